@@ -144,15 +144,37 @@ func main() {
 		case "noids", "noid", "noidentifiers", "noidentifier":
 			bugapp.IdsNone(config)
 		case "env":
-			bugapp.Env(config)
+			bugapp.Env(osArgs[2:], config)
 		case "pwd", "dir", "cwd":
 			bugapp.Pwd(config)
 		case "version", "about", "--version", "-v":
 			bugapp.PrintVersion()
 		case "purge":
 			bugapp.Purge(config)
+		case "reindex":
+			bugapp.Reindex(config)
+		case "search":
+			bugapp.Search(osArgs[2:], config)
+		case "log":
+			bugapp.Log(osArgs[2:], config)
+		case "diff":
+			bugapp.Diff(osArgs[2:], config)
+		case "link":
+			bugapp.Link(osArgs[2:], config)
+		case "graph":
+			bugapp.Graph(osArgs[2:], config)
+		case "changelog", "releasenotes":
+			bugapp.Changelog(osArgs[2:], config)
 		case "twilio":
 			bugapp.Twilio(config)
+		case "notify":
+			bugapp.Notify(osArgs[2:], config)
+		case "watch":
+			bugapp.Watch(osArgs[2:], config)
+		case "unwatch":
+			bugapp.Unwatch(osArgs[2:], config)
+		case "webhooks":
+			bugapp.Webhooks(osArgs[2:], config)
 		case "staging", "staged", "cached", "cache", "index":
 			if b, err := handler.SCMIssuesUpdaters(config); err != nil {
 				fmt.Printf("Files in " + config.FitDirName + "/ need committing, see $ git status --porcelain -u -- :/" + config.FitDirName + "\nor if already in the index see     $ git diff --name-status --cached HEAD -- :/" + config.FitDirName + "\n")
@@ -189,6 +211,8 @@ func main() {
 			bugapp.Relabel(osArgs[2:], config)
 		case "close", "rm":
 			bugapp.Close(osArgs[2:], config)
+		case "comment":
+			bugapp.Comment(osArgs[2:], config)
 		case "tag":
 			bugapp.Tag(osArgs[2:], config) // boolean only
 		case "id", "identifier":
@@ -196,7 +220,7 @@ func main() {
 		case "status":
 			if len(osArgs) == 2 {
 				// overview like a git status
-				bugapp.Env(config)
+				bugapp.Env(osArgs[2:], config)
 			} else {
 				// get or set the status of an issue
 				bugapp.Status(osArgs[2:], config)
@@ -205,12 +229,20 @@ func main() {
 			bugapp.Priority(osArgs[2:], config)
 		case "milestone":
 			bugapp.Milestone(osArgs[2:], config)
+		case "due":
+			bugapp.Due(osArgs[2:], config)
 		case "import":
 			bugapp.Import(osArgs[2:], config)
+		case "sync":
+			bugapp.Sync(osArgs[2:], config)
 		case "commit", "save":
 			bugapp.Commit(osArgs[2:], config)
 		case "roadmap":
 			bugapp.Roadmap(osArgs[2:], config)
+		case "serve", "server":
+			bugapp.Serve(osArgs[2:], config)
+		case "export":
+			bugapp.Export(osArgs[2:], config)
 		case "help", "--help", "-h":
 			//fmt.Printf("C %s %#v\n", "osArgs: ", osArgs)
 			bugapp.Help(osArgs[2])
@@ -27,10 +27,21 @@ type Config struct {
 	NewFieldAsTag             bool   `json:"NewFieldAsTag"`
 	NewFieldLowerCase         bool   `json:"NewFieldLowerCase"`
 	GithubPersonalAccessToken string `json:"GithubPersonalAccessToken"`
+	GithubApiUrl              string `json:"GithubApiUrl"`
+	JiraUser                  string `json:"JiraUser"`
+	JiraApiToken              string `json:"JiraApiToken"`
 	TwilioAccountSid          string `json:"TwilioAccountSid"`
 	TwilioAuthToken           string `json:"TwilioAuthToken"`
 	TwilioPhoneNumberFrom     string `json:"TwilioPhoneNumberFrom"`
 	FitSite                   string `json:"FitSite"`
+	TwilioApiUrl              string `json:"TwilioApiUrl"`
+	SmtpServer                string `json:"SmtpServer"`
+	SmtpUser                  string `json:"SmtpUser"`
+	SmtpPassword              string `json:"SmtpPassword"`
+	NotifyFrom                string `json:"NotifyFrom"`
+	NotifyTo                  string `json:"NotifyTo"`
+	WebhookUrls               string `json:"WebhookUrls"`
+	WebhookSecret             string `json:"WebhookSecret"`
 	MultipleFitDirs           bool   `json:"MultipleFitDirs"`
 	CloseStatusTag            bool   `json:"CloseStatusTag"`
 	IdAbbreviate              bool   `json:"IdAbbreviate"`
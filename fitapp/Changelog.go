@@ -0,0 +1,192 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"os"
+	"sort"
+	"strings"
+)
+
+// changelogCategories group closed issues by tag in the order of the
+// sections of a changelog, with their headings in markdown and text and
+// in Keep a Changelog format. Issues without any of the tags are other
+// changes.
+var changelogCategories = []struct {
+	Tag, Heading, KeepAChangelog string
+}{
+	{"security", "Security", "Security"},
+	{"bug", "Bug fixes", "Fixed"},
+	{"feature", "Features", "Added"},
+	{"", "Other changes", "Changed"},
+}
+
+// changelogColumns are the csv columns of changelog.
+var changelogColumns = []string{"Milestone", "Category", "Identifier", "Title", "Name", "Removed"}
+
+// changelogEntry is a closed issue of a changelog.
+type changelogEntry struct {
+	bugs.IssueChange
+	Milestone string
+	Category  int
+}
+
+// snapshot returns the last snapshot of the issue of a change.
+func (e changelogEntry) snapshot() *bugs.IssueSnapshot {
+	if e.New != nil {
+		return e.New
+	}
+	return e.Old
+}
+
+// title returns the title of the issue with its identifier.
+func (e changelogEntry) title() string {
+	if e.Identifier != "" {
+		return fmt.Sprintf("(%s) %s", e.Identifier, e.Title)
+	}
+	return e.Title
+}
+
+// changelogCategory returns the index in changelogCategories of the
+// first category of which the issue has the tag, as a tag or the value
+// of a key:value tag like type:bug.
+func changelogCategory(tags []string) int {
+	for i, category := range changelogCategories {
+		for _, tag := range tags {
+			if category.Tag != "" && (tag == category.Tag || strings.HasSuffix(tag, ":"+category.Tag)) {
+				return i
+			}
+		}
+	}
+	return len(changelogCategories) - 1
+}
+
+// changelogEntries returns the closed issues with milestone, or of any
+// milestone when it is empty, ordered by milestone, issues without one
+// first and then newest milestone first, by category and by title.
+func changelogEntries(changes []bugs.IssueChange, milestone string) []changelogEntry {
+	var entries []changelogEntry
+	for _, c := range changes {
+		e := changelogEntry{IssueChange: c}
+		s := e.snapshot()
+		e.Milestone = s.Milestone
+		e.Category = changelogCategory(s.Tags)
+		if milestone == "" || e.Milestone == milestone {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch {
+		case a.Milestone != b.Milestone:
+			if a.Milestone == "" || b.Milestone == "" {
+				return a.Milestone == ""
+			}
+			return milestoneLess(b.Milestone, a.Milestone)
+		case a.Category != b.Category:
+			return a.Category < b.Category
+		}
+		return a.Title < b.Title
+	})
+	return entries
+}
+
+// changelogText prints entries as markdown, text or in Keep a Changelog
+// format.
+func changelogText(entries []changelogEntry, format string, config bugs.Config) {
+	project := bugs.RootDirer(&config).ShortNamer().ToTitle()
+	switch format {
+	case "markdown":
+		fmt.Printf("# Changelog for %s\n", project)
+	case "keepachangelog":
+		fmt.Printf("# Changelog\n\nAll notable changes to this project are documented in this file.\n")
+	default:
+		fmt.Printf("Changelog for %s\n", project)
+	}
+	for i, e := range entries {
+		if i == 0 || e.Milestone != entries[i-1].Milestone {
+			switch {
+			case format == "keepachangelog" && e.Milestone == "":
+				fmt.Printf("\n## [Unreleased]\n")
+			case format == "keepachangelog":
+				fmt.Printf("\n## [%s]\n", e.Milestone)
+			case format == "markdown" && e.Milestone == "":
+				fmt.Printf("\n## No milestone set\n")
+			case format == "markdown":
+				fmt.Printf("\n## %s\n", e.Milestone)
+			case e.Milestone == "":
+				fmt.Printf("\nNo milestone set\n")
+			default:
+				fmt.Printf("\n%s\n", e.Milestone)
+			}
+		}
+		if i == 0 || e.Milestone != entries[i-1].Milestone || e.Category != entries[i-1].Category {
+			category := changelogCategories[e.Category]
+			switch format {
+			case "keepachangelog":
+				fmt.Printf("\n### %s\n", category.KeepAChangelog)
+			case "markdown":
+				fmt.Printf("\n### %s\n", category.Heading)
+			default:
+				fmt.Printf("  %s:\n", category.Heading)
+			}
+		}
+		if format == "text" {
+			fmt.Printf("    %s\n", e.title())
+		} else {
+			fmt.Printf("- %s\n", e.title())
+		}
+	}
+}
+
+// Changelog is a subcommand to print release notes of the issues closed
+// between two revisions, by removing them or setting their status to
+// closed, grouped by milestone and by category.
+func Changelog(args argumentList, config bugs.Config) {
+	format := "markdown"
+	if args.HasArgument("--format") {
+		args, format = formatArgument(args)
+	}
+	args, values := args.GetAndRemoveArguments([]string{"--since", "--until", "--milestone"})
+	since, until, milestone := values[0], values[1], values[2]
+	if len(args) > 0 || since == "true" || until == "true" || milestone == "true" {
+		fmt.Printf("Usage: %s changelog [--milestone <milestone>] [--since <revision>] [--until <revision>] [--format <format>]\n", os.Args[0])
+		return
+	}
+	handler, _, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	changes, err := scm.ClosedIssues(handler, since, until, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	entries := changelogEntries(changes, milestone)
+	if format == "markdown" || format == "keepachangelog" {
+		changelogText(entries, format, config)
+		return
+	}
+	writeReport(format, outputReport{
+		Columns: changelogColumns,
+		Records: func() []outputRecord {
+			var records []outputRecord
+			for _, e := range entries {
+				records = append(records, outputRecord{
+					"Milestone":  e.Milestone,
+					"Category":   changelogCategories[e.Category].Tag,
+					"Identifier": e.Identifier,
+					"Title":      e.Title,
+					"Name":       e.Name,
+					"Removed":    e.New == nil,
+				})
+			}
+			return records
+		},
+		Text: func() {
+			changelogText(entries, format, config)
+		},
+	})
+}
@@ -0,0 +1,128 @@
+package fitapp
+
+import (
+	"encoding/json"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestChangelogCategory(t *testing.T) {
+	for expected, tags := range map[int][]string{
+		0: {"bug", "security"},
+		1: {"type:bug"},
+		2: {"feature", "ui"},
+		3: {"ui"},
+	} {
+		if got := changelogCategory(tags); got != expected {
+			t.Errorf("Unexpected category of %v: %d", tags, got)
+		}
+	}
+	if got := changelogCategory(nil); got != 3 {
+		t.Errorf("Unexpected category without tags: %d", got)
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("WARN git executable not found")
+	}
+	dir, err := ioutil.TempDir("", "changelogtest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	commit := func(msg string) {
+		exec.Command("git", "add", "-A").Run()
+		cmd := exec.Command("git", "-c", "user.name=Tester", "-c", "user.email=tester@example.com", "commit", "-q", "-m", msg)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	}
+	set := func(name, file, value string) {
+		ioutil.WriteFile(dir+"/fit/"+name+"/"+file, []byte(value+"\n"), 0644)
+	}
+
+	captureOutput(func() {
+		Create(argumentList{"-n", "Login", "crash", "--tag", "bug", "--milestone", "v1.3"}, config)
+		Create(argumentList{"-n", "Old", "feature", "--tag", "feature", "--milestone", "v1.2"}, config)
+		Create(argumentList{"-n", "Reopened", "--milestone", "v1.3"}, config)
+		Create(argumentList{"-n", "Still", "open", "--milestone", "v1.3"}, config)
+	}, t)
+	commit("Create issues")
+	exec.Command("git", "tag", "v1").Run()
+
+	set("Login-crash", "Status", "closed")
+	set("Reopened", "Status", "closed")
+	os.RemoveAll(dir + "/fit/Old-feature")
+	captureOutput(func() {
+		Create(argumentList{"-n", "Token", "leak", "--tag", "security", "--milestone", "v1.3"}, config)
+	}, t)
+	commit("Close issues")
+	set("Reopened", "Status", "open")
+	os.RemoveAll(dir + "/fit/Token-leak")
+	commit("Reopen and close issues")
+	// closed in the working tree
+	captureOutput(func() {
+		Create(argumentList{"-n", "Typo"}, config)
+	}, t)
+	ioutil.WriteFile(dir+"/fit/Typo/tag_status_closed", []byte{}, 0644)
+
+	stdout, stderr := captureOutput(func() {
+		Changelog(argumentList{"--since", "v1"}, config)
+	}, t)
+	expected := "\n## No milestone set\n\n### Other changes\n- Typo\n" +
+		"\n## v1.3\n\n### Security\n- Token leak\n\n### Bug fixes\n- Login crash\n" +
+		"\n## v1.2\n\n### Features\n- Old feature\n"
+	if !strings.HasPrefix(stdout, "# Changelog for ") || !strings.HasSuffix(stdout, expected) || stderr != "" {
+		t.Errorf("Unexpected markdown changelog: %s %s", stdout, stderr)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Changelog(argumentList{"--milestone", "v1.3", "--until", "HEAD", "--format", "keepachangelog"}, config)
+	}, t)
+	expected = "# Changelog\n\nAll notable changes to this project are documented in this file.\n" +
+		"\n## [v1.3]\n\n### Security\n- Token leak\n\n### Fixed\n- Login crash\n"
+	if stdout != expected {
+		t.Errorf("Unexpected Keep a Changelog changelog: %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Changelog(argumentList{"--since", "v1", "--until", "HEAD", "--format", "text"}, config)
+	}, t)
+	if !strings.HasSuffix(stdout, "\nv1.3\n  Security:\n    Token leak\n  Bug fixes:\n    Login crash\n\nv1.2\n  Features:\n    Old feature\n") {
+		t.Errorf("Unexpected text changelog: %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Changelog(argumentList{"--since", "v1", "--milestone", "v1.2", "--format", "json"}, config)
+	}, t)
+	var records []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil || len(records) != 1 ||
+		records[0]["Title"] != "Old feature" || records[0]["Category"] != "feature" || records[0]["Removed"] != true {
+		t.Errorf("Unexpected json changelog %s: %v", stdout, err)
+	}
+
+	_, stderr = captureOutput(func() {
+		Changelog(argumentList{"--since", "missing"}, config)
+	}, t)
+	if stderr != "Error: Unknown revisions missing..HEAD\n" {
+		t.Errorf("Unexpected error of a missing revision: %s", stderr)
+	}
+}
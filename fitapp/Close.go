@@ -4,6 +4,7 @@ import (
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
 	"os"
+	"time"
 )
 
 // Close is a subcommand to close issues.
@@ -16,30 +17,38 @@ func Close(args argumentList, config bugs.Config) {
 
 	// There were parameters, so show the full description of each
 	// of those issues
-	var bugsToClose []string
+	var bugsToClose []*bugs.Issue
 	for _, bugID := range args {
 		if bug, err := bugs.LoadIssueByHeuristic(bugID, config); err == nil {
-			dir := bug.Direr()
 			if config.CloseStatusTag {
-				fmt.Printf("Tag status closed %s\n", dir)
-				err = bug.SetField("Status", "closed", config)
-				if err != nil {
+				fmt.Printf("Tag status closed %s\n", bug.Direr())
+				if err = closeIssue(bug, config); err != nil {
 					fmt.Fprintf(os.Stderr, "Error setting %s %s : %s\n", "Status", "closed", err.Error())
 				}
 			} else {
-				bugsToClose = append(bugsToClose, string(dir))
+				bugsToClose = append(bugsToClose, bug)
 			}
 		} else {
 			fmt.Fprintf(os.Stderr, "Could not close issue %s: %s\n", bugID, err.Error())
 		}
 	}
-	for _, dir := range bugsToClose {
-		if !config.CloseStatusTag {
-			fmt.Printf("Removing %s\n", dir)
-			err := os.RemoveAll(dir)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error removing %s : %s\n", dir, err.Error())
-			}
+	// removed after all are loaded so indexes don't shift
+	for _, bug := range bugsToClose {
+		fmt.Printf("Removing %s\n", bug.Direr())
+		if err := closeIssue(bug, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing %s : %s\n", bug.Direr(), err.Error())
+		}
+	}
+}
+
+// closeIssue sets the Status to closed and the Closed date when
+// CloseStatusTag is configured, otherwise the issue directory is removed.
+func closeIssue(b *bugs.Issue, config bugs.Config) error {
+	if config.CloseStatusTag {
+		if err := b.SetField("Status", "closed", config); err != nil {
+			return err
 		}
+		return b.SetDate(bugs.DateClosed, bugs.Date{Time: time.Now()}, config)
 	}
+	return b.Remove()
 }
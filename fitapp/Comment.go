@@ -0,0 +1,141 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commentAuthor returns the author of new comments from the git or hg
+// configuration, or the USER environment variable.
+func commentAuthor(config bugs.Config) string {
+	scmconfig := func(args ...string) string {
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	switch config.ScmType {
+	case "hg":
+		if author := scmconfig("hg", "config", "ui.username"); author != "" {
+			return author
+		}
+	default:
+		name := scmconfig("git", "config", "user.name")
+		email := scmconfig("git", "config", "user.email")
+		if name != "" && email != "" {
+			return name + " <" + email + ">"
+		} else if name != "" {
+			return name
+		}
+	}
+	return os.Getenv("USER")
+}
+
+// commentUsage prints how to use the comment subcommand.
+func commentUsage() {
+	fmt.Printf("Usage: %s comment <IssueID> [-m <text>]\n", os.Args[0])
+	fmt.Printf("       %s comment --list <IssueID>\n", os.Args[0])
+	fmt.Printf("       %s comment --edit <IssueID> <CommentNumber>\n", os.Args[0])
+	fmt.Printf("       %s comment --rm <IssueID> <CommentNumber>\n", os.Args[0])
+}
+
+// runEditor opens file in the editor of the user.
+func runEditor(file string) {
+	cmd := exec.Command(getEditor(), file)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Comment is a subcommand to add, list, edit and remove the comments of
+// an issue.
+func Comment(args argumentList, config bugs.Config) {
+	action := ""
+	if len(args) > 0 && (args[0] == "--list" || args[0] == "--edit" || args[0] == "--rm") {
+		action = args[0]
+		args = args[1:]
+	}
+	if len(args) < 1 {
+		commentUsage()
+		return
+	}
+	b, err := bugs.LoadIssueByHeuristic(args[0], config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+		return
+	}
+
+	switch action {
+	case "--list":
+		for _, c := range b.Comments() {
+			fmt.Printf("[%d] ", c.Order)
+			if c.Author != "" {
+				fmt.Printf("%s, ", c.Author)
+			}
+			fmt.Printf("%s\n%s\n\n", c.Time.Format(time.RFC3339), c.Body)
+		}
+		return
+	case "--edit", "--rm":
+		if len(args) != 2 {
+			commentUsage()
+			return
+		}
+		comments := b.Comments()
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > len(comments) {
+			fmt.Fprintf(os.Stderr, "Invalid comment number %s, issue has %d comments\n", args[1], len(comments))
+			return
+		}
+		c := comments[n-1]
+		if action == "--rm" {
+			b.RemoveComment(c)
+			fmt.Printf("Removed comment %d from %s\n", n, b.Title(""))
+			return
+		}
+		fmt.Printf("Editing %s%s%s\n", b.Direr(), sops, c.File)
+		runEditor(string(b.Direr()) + sops + c.File)
+		return
+	}
+
+	comment := bugs.Comment{Author: commentAuthor(config), Time: time.Now()}
+	switch {
+	case len(args) > 2 && args[1] == "-m":
+		comment.Body = strings.Join(args[2:], " ")
+	case len(args) == 1:
+		file, err := ioutil.TempFile("", "fitComment")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not create temporary file: %s\n", err.Error())
+			return
+		}
+		file.Close()
+		defer os.Remove(file.Name())
+		runEditor(file.Name())
+		data, err := ioutil.ReadFile(file.Name())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not read comment: %s\n", err.Error())
+			return
+		}
+		comment.Body = string(data)
+	default:
+		commentUsage()
+		return
+	}
+	comment.Body = strings.TrimRight(comment.Body, "\n")
+	if strings.TrimSpace(comment.Body) == "" {
+		fmt.Fprintf(os.Stderr, "Empty comment, nothing added\n")
+		return
+	}
+	b.CommentIssue(comment, config)
+	fmt.Printf("Added comment %d to %s\n", len(b.Comments()), b.Title(""))
+}
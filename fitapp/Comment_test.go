@@ -0,0 +1,96 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComment(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "commenttest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	os.Setenv("FIT", dir)
+	editor := os.Getenv("EDITOR")
+	defer os.Setenv("EDITOR", editor)
+	script := dir + sops + "editor.sh"
+	ioutil.WriteFile(script, []byte("#!/bin/sh\necho from the editor > \"$1\"\n"), 0755)
+	os.Setenv("EDITOR", script)
+	user := os.Getenv("USER")
+	defer os.Setenv("USER", user)
+	os.Setenv("USER", "Tester")
+
+	captureOutput(func() {
+		Create(argumentList{"-n", "Commented", "bug", "--identifier", "C1"}, config)
+	}, t)
+	// the same first words collided with the old comment file names
+	stdout, stderr := captureOutput(func() {
+		Comment(argumentList{"C1", "-m", "this comment starts the same way A"}, config)
+		Comment(argumentList{"C1", "-m", "this comment starts the same way B"}, config)
+		Comment(argumentList{"C1"}, config)
+	}, t)
+	if stderr != "" || !strings.Contains(stdout, "Added comment 3 to Commented bug") {
+		t.Errorf("Unexpected comment output %s %s", stdout, stderr)
+	}
+	issuedir := dir + sops + "fit" + sops + "Commented-bug"
+	files, _ := filepath.Glob(issuedir + sops + "comment-*")
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 comment files, got %v", files)
+	}
+	data, _ := ioutil.ReadFile(issuedir + sops + "comment-1-this-comment-starts-the-s")
+	if !strings.HasPrefix(string(data), "Author: "+commentAuthor(config)+"\nDate: ") ||
+		!strings.HasSuffix(string(data), "\n\nthis comment starts the same way A\n") {
+		t.Errorf("Unexpected comment file %q", data)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Comment(argumentList{"--list", "C1"}, config)
+	}, t)
+	a := strings.Index(stdout, "[1] ")
+	b := strings.Index(stdout, "[2] ")
+	c := strings.Index(stdout, "[3] ")
+	if a == -1 || b < a || c < b || !strings.Contains(stdout, "same way B\n") ||
+		!strings.HasSuffix(stdout, "from the editor\n\n") {
+		t.Errorf("Unexpected comment list %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Comment(argumentList{"--rm", "C1", "1"}, config)
+		Comment(argumentList{"--edit", "C1", "1"}, config)
+	}, t)
+	if !strings.Contains(stdout, "Removed comment 1 from Commented bug") {
+		t.Errorf("Unexpected remove output %s", stdout)
+	}
+	issue, _ := bugs.LoadIssueByHeuristic("C1", config)
+	comments := issue.Comments()
+	if len(comments) != 2 || comments[0].Body != "from the editor" || comments[1].Body != "from the editor" {
+		t.Errorf("Unexpected comments after remove and edit %+v", comments)
+	}
+
+	stdout, _ = captureOutput(func() {
+		issue.ViewIssue()
+	}, t)
+	if !strings.Contains(stdout, "Comments:\n\n[1] "+commentAuthor(config)+", ") ||
+		!strings.HasSuffix(stdout, "\nfrom the editor\n") {
+		t.Errorf("Unexpected issue view %s", stdout)
+	}
+
+	_, stderr = captureOutput(func() {
+		Comment(argumentList{"--rm", "C1", "3"}, config)
+	}, t)
+	if !strings.Contains(stderr, "Invalid comment number 3") {
+		t.Errorf("Expected an invalid comment number, got %s", stderr)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
 	"github.com/driusan/bug/scm"
+	"os"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
 //var sops = string(os.PathSeparator)
 
 // Commit is a subcommand to save issues to the git or mercurial (hg) SCMs.
+// The changes of the commit are posted to WebhookUrls.
 func Commit(args argumentList, config bugs.Config) {
 	options := make(map[string]bool)
 	if !args.HasArgument("--no-autoclose") {
@@ -19,16 +21,28 @@ func Commit(args argumentList, config bugs.Config) {
 	}
 	options["use_bug_prefix"] = true // SCM will ignore this option if it doesn't know it
 
-	scm, _, err := scm.DetectSCM(options, config)
+	handler, scmdir, err := scm.DetectSCM(options, config)
 	if err != nil {
 		fmt.Printf("Error: %s\n", err.Error())
 		return
 	}
 
-	err = scm.Commit(bugs.FitDirer(config)+dops, "Added or removed issues with the tool \"fit\"", config)
+	// a post-commit hook running webhooks send leaves the events to us
+	os.Setenv("FIT_COMMIT", "1")
+	err = handler.Commit(bugs.FitDirer(config)+dops, "Added or removed issues with the tool \"fit\"", config)
+	os.Unsetenv("FIT_COMMIT")
 
 	if err != nil {
 		fmt.Printf("Could not commit: %s\n", err.Error())
 		return
 	}
+	if config.WebhookUrls != "" {
+		rev := "HEAD"
+		if handler.SCMTyper() == "hg" {
+			rev = "."
+		}
+		if err := sendWebhooks(handler, scmdir, rev, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		}
+	}
 }
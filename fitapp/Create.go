@@ -8,12 +8,13 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
 //var sops = string(os.PathSeparator)
 
-// filecp copies files.
+// filecp copies a file to a file of an issue.
 // see https://opensource.com/article/18/6/copying-files-go
 func filecp(sourceFile string, destinationFile string) {
 	input, err := ioutil.ReadFile(sourceFile)
@@ -21,7 +22,7 @@ func filecp(sourceFile string, destinationFile string) {
 		fmt.Println(err)
 		return
 	}
-	err = ioutil.WriteFile(destinationFile, input, 0644)
+	err = bugs.CurrentStore().WriteFile(destinationFile, input)
 	if err != nil {
 		fmt.Println("Error creating", destinationFile)
 		fmt.Println(err)
@@ -86,9 +87,7 @@ func Create(Args argumentList, config bugs.Config) {
 
 	dir := bug.Direr()
 
-	var mode os.FileMode
-	mode = 0775
-	err := os.Mkdir(string(dir), mode)
+	_, err := bugs.New(strings.Join(Args, " "), config)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "\n%s error: mkdir\n", os.Args[0])
 		log.Fatal(err)
@@ -100,7 +99,7 @@ func Create(Args argumentList, config bugs.Config) {
 			filecp(config.FitYmlDir+sops+config.DefaultDescriptionFile, DescriptionFile)
 		} else {
 			//fmt.Printf("here %s\n", config.DescriptionFileName)
-			ioutil.WriteFile(DescriptionFile, txt, 0644)
+			bugs.CurrentStore().WriteFile(DescriptionFile, txt)
 		}
 	} else {
 		if config.DefaultDescriptionFile != "" {
@@ -135,5 +134,6 @@ func Create(Args argumentList, config bugs.Config) {
 	if identifier != "" {
 		bug.SetIdentifier(identifier, config)
 	}
+	bug.SetDate(bugs.DateReported, bugs.Date{Time: time.Now()}, config)
 	fmt.Printf("Created issue: %s\n", bug.Title(""))
 }
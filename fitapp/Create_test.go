@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
@@ -133,8 +134,9 @@ func TestCreateNoEditor(t *testing.T) {
 		t.Error("Unexpected number of issues in " + config.FitDirName + " dir\n")
 	}
 
+	// Description and Reported
 	bugDir, err := ioutil.ReadDir(fmt.Sprintf("%s%s%s%sTest-bug", dir, sops, config.FitDirName, sops))
-	if len(bugDir) != 1 {
+	if len(bugDir) != 2 {
 		t.Error("Unexpected number of files found in Test-bug dir\n")
 	}
 	if err != nil {
@@ -149,6 +151,10 @@ func TestCreateNoEditor(t *testing.T) {
 	if len(file) != 0 {
 		t.Error("Expected empty file for Test bug")
 	}
+	b := bugs.Issue{Dir: bugs.Directory(dir + sops + config.FitDirName + sops + "Test-bug")}
+	if reported, ok := b.Date(bugs.DateReported); !ok || time.Since(reported.Time) > time.Minute {
+		t.Errorf("Unexpected Reported date %s", reported)
+	}
 
 	///// second issue
 	config.DefaultDescriptionFile = dir + sops + "ddf" // put ABOVE issues so len(issuesDir) check later is unaltered
@@ -174,7 +180,7 @@ func TestCreateNoEditor(t *testing.T) {
 	}
 
 	bugDir, err = ioutil.ReadDir(fmt.Sprintf("%s%s%s%sTest2-bug", dir, sops, config.FitDirName, sops))
-	if len(bugDir) != 2 {
+	if len(bugDir) != 3 {
 		t.Error("Unexpected number of files found in Test2-bug dir\n")
 	}
 	if err != nil {
@@ -0,0 +1,139 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"os"
+	"strings"
+	"time"
+)
+
+// diffColumns are the csv columns of diff.
+var diffColumns = []string{"Kind", "Name", "OldName", "Title", "Identifier", "Fields", "TagsAdded", "TagsRemoved", "Comments", "DescriptionChanged"}
+
+// diffRecord returns an issue change for machine readable output.
+func diffRecord(c bugs.IssueChange) outputRecord {
+	fields := []outputRecord{}
+	for _, f := range c.Fields {
+		fields = append(fields, outputRecord{"Field": f.Field, "Old": f.Old, "New": f.New})
+	}
+	comments := []outputRecord{}
+	for _, comment := range c.Comments {
+		comments = append(comments, outputRecord{
+			"Author": comment.Author,
+			"Time":   comment.Time.Format(time.RFC3339),
+			"Body":   comment.Body,
+		})
+	}
+	strs := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+		return s
+	}
+	return outputRecord{
+		"Kind":               c.Kind,
+		"Name":               c.Name,
+		"OldName":            c.OldName,
+		"Title":              c.Title,
+		"Identifier":         c.Identifier,
+		"Fields":             fields,
+		"TagsAdded":          strs(c.TagsAdded),
+		"TagsRemoved":        strs(c.TagsRemoved),
+		"Comments":           comments,
+		"DescriptionChanged": c.DescriptionChanged,
+	}
+}
+
+// diffLines describes an issue change, the first line names the issue
+// and the others, indented, what changed.
+func diffLines(c bugs.IssueChange) []string {
+	kind := strings.Title(c.Kind)
+	title := c.Title
+	if c.OldName != "" {
+		title = c.Old.Title + " -> " + c.Title
+	}
+	if c.Identifier != "" {
+		title = fmt.Sprintf("(%s) %s", c.Identifier, title)
+	}
+	lines := []string{kind + ": " + title}
+	if c.Kind == bugs.IssueClosed && c.New == nil {
+		lines[0] += " (removed)"
+	}
+	for _, f := range c.Fields {
+		switch {
+		case f.Old == "":
+			lines = append(lines, fmt.Sprintf("    %s set to %s", f.Field, f.New))
+		case f.New == "":
+			lines = append(lines, fmt.Sprintf("    %s removed (was %s)", f.Field, f.Old))
+		default:
+			lines = append(lines, fmt.Sprintf("    %s: %s -> %s", f.Field, f.Old, f.New))
+		}
+	}
+	if len(c.TagsAdded) > 0 {
+		lines = append(lines, "    Tags added: "+strings.Join(c.TagsAdded, ", "))
+	}
+	if len(c.TagsRemoved) > 0 {
+		lines = append(lines, "    Tags removed: "+strings.Join(c.TagsRemoved, ", "))
+	}
+	if c.DescriptionChanged {
+		lines = append(lines, "    Description changed")
+	}
+	for _, comment := range c.Comments {
+		body := logFirstLine(comment.Body)
+		if comment.Author != "" {
+			lines = append(lines, fmt.Sprintf("    Comment by %s: %s", comment.Author, body))
+		} else {
+			lines = append(lines, "    Comment: "+body)
+		}
+	}
+	return lines
+}
+
+// Diff is a subcommand to print the issues created, closed, renamed or
+// changed between two revisions, or a revision and the working tree.
+func Diff(args argumentList, config bugs.Config) {
+	args, format := formatArgument(args)
+	if len(args) > 2 {
+		fmt.Printf("Usage: %s diff [<revision> [<revision>]] [--format <format>]\n", os.Args[0])
+		return
+	}
+	handler, _, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	from, to := "HEAD", ""
+	if handler.SCMTyper() == "hg" {
+		from = "."
+	}
+	if len(args) > 0 {
+		from = args[0]
+	}
+	if len(args) > 1 {
+		to = args[1]
+	}
+	changes, err := scm.DiffIssues(handler, from, to, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	writeReport(format, outputReport{
+		Columns: diffColumns,
+		Records: func() []outputRecord {
+			var records []outputRecord
+			for _, c := range changes {
+				records = append(records, diffRecord(c))
+			}
+			return records
+		},
+		Text: func() {
+			for _, c := range changes {
+				for _, line := range diffLines(c) {
+					fmt.Printf("%s\n", line)
+				}
+			}
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package fitapp
+
+import (
+	"encoding/json"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("WARN git executable not found")
+	}
+	dir, err := ioutil.TempDir("", "difftest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	exec.Command("git", "config", "user.name", "Tester").Run()
+	exec.Command("git", "config", "user.email", "tester@example.com").Run()
+	commit := func(msg string) {
+		exec.Command("git", "add", "-A").Run()
+		cmd := exec.Command("git", "commit", "-q", "-m", msg)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	}
+
+	captureOutput(func() {
+		Create(argumentList{"-n", "Fixed", "bug", "--status", "open", "--tag", "ui", "--identifier", "FIX"}, config)
+		Create(argumentList{"-n", "Old", "title", "--identifier", "OLD"}, config)
+		Create(argumentList{"-n", "Removed", "bug", "--identifier", "RM"}, config)
+		Create(argumentList{"-n", "Draft", "notes"}, config)
+	}, t)
+	// issues without an identifier are renamed with the same Description
+	ioutil.WriteFile(dir+"/fit/Draft-notes/Description", []byte("Renamed later\n"), 0644)
+	commit("Create issues")
+	exec.Command("git", "tag", "v1").Run()
+	// issue numbers of issues created in the same second may change, use
+	// the identifiers
+	captureOutput(func() {
+		Status(argumentList{"FIX", "closed"}, config)
+		Tag(argumentList{"FIX", "security"}, config)
+		Comment(argumentList{"FIX", "-m", "Fixed", "now"}, config)
+		Relabel(argumentList{"OLD", "New", "title"}, config)
+		Close(argumentList{"RM"}, config)
+		Create(argumentList{"-n", "Added", "bug"}, config)
+	}, t)
+	os.Rename(dir+"/fit/Draft-notes", dir+"/fit/Final-notes")
+
+	expected := "Closed: (FIX) Fixed bug\n" +
+		"    Status: open -> closed\n" +
+		"    Tags added: security\n" +
+		"    Comment by Tester <tester@example.com>: Fixed now\n" +
+		"Closed: (RM) Removed bug (removed)\n" +
+		"Created: Added bug\n" +
+		"Renamed: Draft notes -> Final notes\n" +
+		"Renamed: (OLD) Old title -> New title\n"
+	stdout, stderr := captureOutput(func() {
+		Diff(argumentList{}, config)
+	}, t)
+	if stdout != expected || stderr != "" {
+		t.Errorf("Unexpected diff of the working tree: %s %s", stdout, stderr)
+	}
+	commit("Change issues")
+	stdout, _ = captureOutput(func() {
+		Diff(argumentList{"v1", "HEAD"}, config)
+	}, t)
+	if stdout != expected {
+		t.Errorf("Unexpected diff of two revisions: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Diff(argumentList{}, config)
+	}, t)
+	if stdout != "" {
+		t.Errorf("Unexpected diff of a clean working tree: %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Diff(argumentList{"v1", "--format", "json"}, config)
+	}, t)
+	var records []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil || len(records) != 5 {
+		t.Fatalf("Unexpected json diff %s: %v", stdout, err)
+	}
+	if records[4]["Kind"] != "renamed" || records[4]["OldName"] != "Old-title" || records[4]["Name"] != "New-title" {
+		t.Errorf("Unexpected json of a renamed issue %v", records[4])
+	}
+
+	_, stderr = captureOutput(func() {
+		Diff(argumentList{"missing"}, config)
+	}, t)
+	if stderr != "Error: Unknown revision missing\n" {
+		t.Errorf("Unexpected error of a missing revision: %s", stderr)
+	}
+}
@@ -0,0 +1,104 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"os"
+	"strings"
+	"time"
+)
+
+// dueUsage prints how to use the due subcommand.
+func dueUsage() {
+	fmt.Printf("Usage: %s due <IssueID> [--reported|--started|--closed] <date>\n", os.Args[0])
+	fmt.Printf("       %s due <IssueID> [--reported|--started|--closed] --rm\n", os.Args[0])
+	fmt.Printf("       %s due <IssueID>\n", os.Args[0])
+	fmt.Printf("\nDates are like 2026-11-01, \"2026-11-01 17:00 Europe/Paris\" or 7d\n")
+}
+
+// formatDate returns a date as a day or a time in the local time zone.
+func formatDate(d bugs.Date) string {
+	if d.Day {
+		return d.String()
+	}
+	return d.Local().Format("2006-01-02 15:04 MST")
+}
+
+// dueDates returns the dates set of an issue as "Field: date" lines.
+func dueDates(b bugs.Issue) []string {
+	var lines []string
+	for _, field := range bugs.DateFields {
+		if d, ok := b.Date(field); ok {
+			line := field + ": " + formatDate(d)
+			if field == bugs.DateDue && b.Overdue(time.Now()) {
+				line += " (overdue)"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// overdueIssues returns the open issues with a due date in the past as
+// "Issue N: Title, due date" lines in the order of issue numbers.
+func overdueIssues(config bugs.Config) []string {
+	now := time.Now()
+	var lines []string
+	for idx, r := range bugs.CachedIssues(config) {
+		b := r.Issue(config)
+		if b.Overdue(now) {
+			due, _ := b.Date(bugs.DateDue)
+			lines = append(lines, fmt.Sprintf("%s: %s, due %s", issueNamer(b, idx), r.Title, formatDate(due)))
+		}
+	}
+	return lines
+}
+
+// Due is a subcommand to set, remove and show the due date of an issue,
+// or with --reported, --started or --closed another date field.
+func Due(args argumentList, config bugs.Config) {
+	field := bugs.DateDue
+	for _, f := range bugs.DateFields {
+		var found bool
+		if args, found = args.RemoveFlag("--" + strings.ToLower(f)); found {
+			field = f
+		}
+	}
+	args, remove := args.RemoveFlag("--rm")
+	if len(args) < 1 || remove && len(args) != 1 {
+		dueUsage()
+		return
+	}
+	b, err := bugs.LoadIssueByHeuristic(args[0], config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+		return
+	}
+	switch {
+	case remove:
+		if err := b.RemoveDate(field, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+			return
+		}
+		fmt.Printf("Removed %s date of %s\n", strings.ToLower(field), b.Title(""))
+	case len(args) > 1:
+		d, err := bugs.ParseDate(strings.Join(args[1:], " "), time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+			return
+		}
+		if err := b.SetDate(field, d, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+			return
+		}
+		fmt.Printf("%s: %s\n", field, formatDate(d))
+	default:
+		lines := dueDates(*b)
+		if len(lines) == 0 {
+			fmt.Printf("No dates\n")
+		}
+		for _, line := range lines {
+			fmt.Printf("%s\n", line)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDue(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	config.CloseStatusTag = true
+	dir, err := ioutil.TempDir("", "duetest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer func() {
+		os.Chdir(pwd)
+		os.RemoveAll(dir)
+	}()
+	os.MkdirAll(config.FitDirName, 0700)
+	os.Setenv("FIT", dir)
+	captureOutput(func() {
+		Create(argumentList{"-n", "Release", "--identifier", "REL"}, config)
+		Create(argumentList{"-n", "Late", "docs", "--identifier", "DOC"}, config)
+	}, t)
+
+	stdout, stderr := captureOutput(func() {
+		Due(argumentList{"REL", "2026-11-01", "17:00", "UTC"}, config)
+	}, t)
+	expected := "Due: " + time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC).Local().Format("2006-01-02 15:04 MST") + "\n"
+	if stdout != expected || stderr != "" {
+		t.Errorf("Unexpected output setting a due date: %s %s", stdout, stderr)
+	}
+	stdout, _ = captureOutput(func() {
+		Due(argumentList{"REL", "--started", "2026-10-01"}, config)
+		Due(argumentList{"DOC", "-3d"}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "Started: 2026-10-01\nDue: ") {
+		t.Errorf("Unexpected output setting dates: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Due(argumentList{"DOC"}, config)
+	}, t)
+	if lines := strings.Split(stdout, "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], "Reported: ") ||
+		!strings.HasSuffix(lines[1], " (overdue)") {
+		t.Errorf("Unexpected dates of an issue: %s", stdout)
+	}
+	_, stderr = captureOutput(func() {
+		Due(argumentList{"REL", "soon"}, config)
+	}, t)
+	if !strings.HasPrefix(stderr, "Error: Invalid date soon") {
+		t.Errorf("Unexpected error of an invalid date: %s", stderr)
+	}
+
+	if overdue := overdueIssues(config); len(overdue) != 1 || !strings.HasPrefix(overdue[0], "Issue DOC: Late docs, due ") {
+		t.Errorf("Unexpected overdue issues %v", overdue)
+	}
+	stdout, _ = captureOutput(func() {
+		Env(argumentList{}, config)
+	}, t)
+	if !strings.Contains(stdout, "Overdue:\n    Issue DOC: Late docs, due ") {
+		t.Errorf("Unexpected status without overdue issues: %s", stdout)
+	}
+
+	captureOutput(func() {
+		Close(argumentList{"DOC"}, config)
+	}, t)
+	b, _ := bugs.LoadIssueByHeuristic("DOC", config)
+	if closed, ok := b.Date(bugs.DateClosed); !ok || time.Since(closed.Time) > time.Minute {
+		t.Errorf("Unexpected Closed date %s", closed)
+	}
+	if overdue := overdueIssues(config); len(overdue) != 0 {
+		t.Errorf("Unexpected overdue issues after closing %v", overdue)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Due(argumentList{"REL", "--rm"}, config)
+		Due(argumentList{"REL"}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "Removed due date of Release\nReported: ") || strings.Contains(stdout, "Due:") {
+		t.Errorf("Unexpected output removing a due date: %s", stdout)
+	}
+}
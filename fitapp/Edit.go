@@ -60,6 +60,8 @@ func Edit(args argumentList, config bugs.Config) {
 		if err != nil {
 			log.Fatal(err)
 		}
+		// fields are edited in place
+		bugs.InvalidateCache(dir, config)
 	default:
 		fmt.Printf("Usage: %s edit [fieldname] IssueID\n", os.Args[0])
 		fmt.Printf("\nNo IssueID specified\n")
@@ -7,9 +7,41 @@ import (
 	"strings"
 )
 
-// Env is a subcommand to output detected editor, directory and scm type.
-func Env(config bugs.Config) {
+// Env is a subcommand to output detected editor, directory and scm type
+// and the overdue issues.
+func Env(args argumentList, config bugs.Config) {
+	_, format := formatArgument(args)
 	vcs, scmdir, scmerr := scm.DetectSCM(make(map[string]bool), config)
+	writeReport(format, outputReport{
+		Columns: []string{"Editor", "RootDirectory", "FitDirectory", "SettingsFile",
+			"VCSType", "VCSDirectory", "NeedCommitting", "Overdue", "Config"},
+		Records: func() []outputRecord {
+			record := outputRecord{
+				"Editor":        getEditor(),
+				"RootDirectory": config.FitDir,
+				"FitDirectory":  string(bugs.FitDirer(config)),
+				"SettingsFile":  config.FitYml,
+				"Overdue":       overdueIssues(config),
+				"Config":        config,
+			}
+			if scmerr == nil {
+				record["VCSType"] = vcs.SCMTyper()
+				record["VCSDirectory"] = string(scmdir)
+				record["NeedCommitting"] = ""
+				if b, err := vcs.SCMIssuesUpdaters(config); err != nil {
+					record["NeedCommitting"] = string(b)
+				}
+			}
+			return []outputRecord{record}
+		},
+		Text: func() {
+			envText(vcs, scmdir, scmerr, config)
+		},
+	})
+}
+
+// envText prints the settings as text.
+func envText(vcs scm.SCMHandler, scmdir bugs.Directory, scmerr error, config bugs.Config) {
 	fmt.Printf("Settings:\n\nEditor: %s\nRoot Directory: %s\nFit Directory: %s\nSettings file: %s\n\n",
 		getEditor(), config.FitDir, bugs.FitDirer(config), config.FitYml)
 
@@ -27,6 +59,9 @@ func Env(config bugs.Config) {
 			fmt.Printf("%v\n\n", string(b)) // simplest implementation, doesn't clarify
 		}
 	}
+	if overdue := overdueIssues(config); len(overdue) > 0 {
+		fmt.Printf("Overdue:\n    %s\n\n", strings.Join(overdue, "\n    "))
+	}
 	fmt.Printf("Config:\n    " +
 		strings.Replace(
 			strings.TrimLeft(
@@ -32,7 +32,7 @@ func TestEnvGit(t *testing.T) {
 	os.Mkdir("Fit", 0755)
 
 	stdout, stderr := captureOutput(func() {
-		Env(config)
+		Env(argumentList{}, config)
 	}, t)
 	if stderr != "" {
 		t.Error("Unexpected error: " + stderr)
@@ -0,0 +1,119 @@
+package fitapp
+
+import (
+	"encoding/json"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/ghodss/yaml"
+	"io/ioutil"
+	"os"
+)
+
+// Export is a subcommand to write every issue of the tree as json or yaml
+// or as a BugsEverywhere repository.
+func Export(args argumentList, config bugs.Config) {
+	var query bugs.Query
+	if args.HasArgument("--filter") {
+		var filter []string
+		args, filter = args.GetAndRemoveArguments([]string{"--filter"})
+		q, ok := parseQuery(filter[0])
+		if !ok {
+			return
+		}
+		query = q
+	}
+	args, values := args.GetAndRemoveArguments([]string{"--json", "--yaml", "--be"})
+	files := []string(args)
+	// --json, --yaml and --be take an optional file or directory name
+	for _, value := range values {
+		if value != "" && value != "true" {
+			files = append(files, value)
+		}
+	}
+	if len(files) > 1 {
+		fmt.Printf("Usage: %s export [--json|--yaml] [--filter <query>] [<file>]\n", os.Args[0])
+		fmt.Printf("       %s export --be [--filter <query>] [<dir>]\n", os.Args[0])
+		return
+	}
+	if values[2] != "" {
+		dir := string(bugs.RootDirer(&config))
+		if len(files) == 1 {
+			dir = files[0]
+		}
+		n, err := beExport(dir, query, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting BugsEverywhere issues: %s\n", err.Error())
+			return
+		}
+		fmt.Printf("Exported %d issues to %s\n", n, dir+sops+".be")
+		return
+	}
+
+	tree, err := bugs.ExportIssues(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting issues: %s\n", err.Error())
+		return
+	}
+	if query != nil {
+		matching := []bugs.ExportIssue{}
+		for _, issue := range tree.Issues {
+			b := bugs.Issue{}
+			b.LoadIssue(bugs.FitDirer(config)+dops+bugs.Directory(issue.Dir), config)
+			if query.Match(b) {
+				matching = append(matching, issue)
+			}
+		}
+		tree.Issues = matching
+	}
+	var out []byte
+	if values[1] != "" {
+		out, err = yaml.Marshal(tree)
+	} else {
+		out, err = json.MarshalIndent(tree, "", "  ")
+		out = append(out, '\n')
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting issues: %s\n", err.Error())
+		return
+	}
+	if len(files) == 0 || files[0] == "-" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := ioutil.WriteFile(files[0], out, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %s\n", files[0], err.Error())
+		return
+	}
+	fmt.Printf("Exported %d issues to %s\n", len(tree.Issues), files[0])
+}
+
+// importTree reads an export of the format json or yaml from file, or
+// stdin when file is "" or "-", and rebuilds the issues.
+func importTree(format, file string, config bugs.Config) {
+	var data []byte
+	var err error
+	if file == "" || file == "-" || file == "true" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s export: %s\n", format, err.Error())
+		return
+	}
+	var tree bugs.ExportTree
+	if format == "yaml" {
+		err = yaml.Unmarshal(data, &tree)
+	} else {
+		err = json.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s export: %s\n", format, err.Error())
+		return
+	}
+	if err := bugs.ImportTree(tree, config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing issues: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Imported %d issues\n", len(tree.Issues))
+}
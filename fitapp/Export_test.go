@@ -0,0 +1,74 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "exporttest")
+	if err != nil {
+		t.Error("Could not create temporary dir for test")
+		return
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	defer os.RemoveAll(dir)
+	err = os.Setenv("FIT", dir)
+	if err != nil {
+		t.Error("Could not set environment variable: " + err.Error())
+		return
+	}
+	captureOutput(func() {
+		Create(argumentList{"-n", "Exported", "bug", "--status", "open"}, config)
+		Create(argumentList{"-n", "Another", "bug", "--tag", "cli"}, config)
+	}, t)
+	ioutil.WriteFile(dir+sops+"fit"+sops+"Exported-bug"+sops+"comment-1", []byte("a comment\n"), 0644)
+	list, _ := captureOutput(func() {
+		List(argumentList{"--format", "json"}, config, true)
+	}, t)
+
+	for _, format := range []string{"json", "yaml"} {
+		file := dir + sops + "export." + format
+		stdout, stderr := captureOutput(func() {
+			Export(argumentList{"--" + format, file}, config)
+		}, t)
+		if stderr != "" || stdout != "Exported 2 issues to "+file+"\n" {
+			t.Errorf("Unexpected export output %s %s", stdout, stderr)
+		}
+		os.RemoveAll(dir + sops + "fit")
+		os.Mkdir(dir+sops+"fit", 0700)
+		stdout, stderr = captureOutput(func() {
+			Import(argumentList{"--" + format, file}, config)
+		}, t)
+		if stderr != "" || stdout != "Imported 2 issues\n" {
+			t.Errorf("Unexpected import output %s %s", stdout, stderr)
+		}
+		got, _ := captureOutput(func() {
+			List(argumentList{"--format", "json"}, config, true)
+		}, t)
+		if got != list {
+			t.Errorf("Unexpected issues after %s import", format)
+			fmt.Printf("Expected: %s\nGot: %s\n", list, got)
+		}
+		if c, _ := ioutil.ReadFile(dir + sops + "fit" + sops + "Exported-bug" + sops + "comment-1"); string(c) != "a comment\n" {
+			t.Errorf("Unexpected comment after %s import: %q", format, c)
+		}
+	}
+
+	_, stderr := captureOutput(func() {
+		Import(argumentList{"--json", dir + sops + "export.json"}, config)
+	}, t)
+	if !strings.Contains(stderr, "already exists") {
+		t.Errorf("Expected an error importing existing issues, got %s", stderr)
+	}
+	os.Chdir(pwd)
+}
@@ -4,55 +4,122 @@ import (
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
 	"os"
-	"sort"
+	"strings"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
 //var sops = string(os.PathSeparator)
 
+// findColumns are the csv columns of find.
+var findColumns = []string{"ID", "Identifier", "Title", "Status", "Priority", "Milestone", "Tags"}
+
+// parseQuery parses a query, printing syntax errors with a marker under
+// the column of the error.
+func parseQuery(query string) (bugs.Query, bool) {
+	q, err := bugs.ParseQuery(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid query: %s\n", err.Error())
+		if qerr, ok := err.(bugs.QueryError); ok {
+			fmt.Fprintf(os.Stderr, "    %s\n    %s^\n", qerr.Query, strings.Repeat(" ", qerr.Pos))
+		}
+		return nil, false
+	}
+	return q, true
+}
+
 // find does the work of finding bugs.
-func find(findType string, findValues []string, config bugs.Config) {
-	fitdir := bugs.FitDirer(config)
-	//issues, _ := ioutil.ReadDir(string(fitdir))
-	issues := readIssues(string(fitdir))
-	sort.Sort(byDir(issues))
-	for idx, issue := range issues {
-		var dir bugs.Directory = fitdir + dops + bugs.Directory(issue.Name())
-		b := bugs.Issue{Dir: dir}
-		name := issueNamer(b, idx)
+func find(findType string, findValues []string, format string, config bugs.Config) {
+	switch findType {
+	case "tags", "status", "priority", "milestone":
+	default:
+		fmt.Printf("Unknown find type: %s\n", findType)
+		return
+	}
+	findMatching(func(e bugs.IssueRecord) bool {
 		var values []string
 		switch findType {
 		case "tags":
-			values = b.StringTags()
+			values = e.Tags
 		case "status":
-			values = []string{b.Status()}
+			values = []string{e.Status}
 		case "priority":
-			values = []string{b.Priority()}
+			values = []string{e.Priority}
 		case "milestone":
-			values = []string{b.Milestone()}
-		default:
-			fmt.Printf("Unknown find type: %s\n", findType)
-			return
+			values = []string{e.Milestone}
 		}
-		printed := false
 		for _, findValue := range findValues {
 			for _, value := range values {
 				if value == findValue {
-					fmt.Printf("%s: %s\n", name, b.Title(findType))
-					printed = true
+					return true
 				}
 			}
-			if printed {
-				break
-			}
+		}
+		return false
+	}, findType, format, config)
+}
+
+// findMatching prints the issues match returns true for, from the cache
+// of the issues.
+func findMatching(match func(e bugs.IssueRecord) bool, titleOptions string, format string, config bugs.Config) {
+	var found []int
+	var foundIssues []bugs.Issue
+	for idx, e := range bugs.CachedIssues(config) {
+		if match(e) {
+			found = append(found, idx)
+			foundIssues = append(foundIssues, e.Issue(config))
 		}
 	}
+	writeReport(format, outputReport{
+		Columns: findColumns,
+		Records: func() []outputRecord {
+			var records []outputRecord
+			for i, b := range foundIssues {
+				record := issueRecord(b)
+				record["ID"] = found[i] + 1
+				records = append(records, record)
+			}
+			return records
+		},
+		Text: func() {
+			for i, b := range foundIssues {
+				fmt.Printf("%s: %s\n", issueNamer(b, found[i]), b.Title(titleOptions))
+			}
+		},
+	})
+}
+
+// queryMatcher returns a findMatching function matching q.
+func queryMatcher(q bugs.Query, config bugs.Config) func(e bugs.IssueRecord) bool {
+	return func(e bugs.IssueRecord) bool {
+		return q.Match(e.Issue(config))
+	}
+}
+
+// isQuery returns true when the arguments of find are a query rather
+// than a find type and values.
+func isQuery(args argumentList) bool {
+	return len(args) > 0 && (strings.ContainsAny(args[0], ":~<>(") ||
+		strings.ToUpper(args[0]) == "NOT" || strings.ToUpper(args[0]) == "OVERDUE")
 }
 
-// Find is a subcommand to find issues.
+// Find is a subcommand to find issues by field values or by a query.
 func Find(args argumentList, config bugs.Config) {
+	args, restore, ok := revisionArguments(args, config)
+	if !ok {
+		return
+	}
+	defer restore()
+	args, format := formatArgument(args)
+	if isQuery(args) {
+		// the query can be split by the shell
+		if q, ok := parseQuery(strings.Join(args, " ")); ok {
+			findMatching(queryMatcher(q, config), "status priority", format, config)
+		}
+		return
+	}
 	if len(args) < 2 {
 		fmt.Printf("Usage: %s find {tags, status, priority, milestone} value1 [value2 ...]\n", os.Args[0])
+		fmt.Printf("       %s find <query>\n", os.Args[0])
 		return
 	}
 	switch args[0] {
@@ -63,7 +130,7 @@ func Find(args argumentList, config bugs.Config) {
 	case "priority":
 		fallthrough
 	case "milestone":
-		find(args[0], args[1:], config)
+		find(args[0], args[1:], format, config)
 	default:
 		fmt.Printf("Unknown command: %v\n", args)
 		return
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +82,62 @@ func TestFindSubcommands(t *testing.T) {
 	runfind(argumentList{"tags", "matchstring"}, "", t) // still not found
 	os.Chdir(pwd)
 }
+
+func TestFindQuery(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "findquery")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.Mkdir(config.FitDirName, 0755)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	captureOutput(func() {
+		Create(argumentList{"-n", "Crash", "on", "start", "--status", "open", "--priority", "high", "--milestone", "v1"}, config)
+		Create(argumentList{"-n", "Leaks", "password", "--status", "open", "--tag", "security", "--milestone", "v2"}, config)
+		Create(argumentList{"-n", "Slow", "start", "--status", "closed", "--priority", "high"}, config)
+	}, t)
+	query := "status:open AND (priority:high OR tag:security) AND NOT milestone:v2"
+
+	// the shell splits the query of find
+	runfind(strings.Split(query, " "), "^Issue 1: Crash on start \\(Status: open; Priority: high\\)\n$", t)
+	runfind(argumentList{"title~start$", "AND", "NOT", "status:open"}, "^Issue 3: Slow start \\(Status: closed; Priority: high\\)\n$", t)
+
+	stdout, _ := captureOutput(func() {
+		List(argumentList{"--filter", "tag:security", "--tags"}, config, true)
+	}, t)
+	if stdout != "Issue 2: Leaks password (milestone:v2, security, status:open)\n" {
+		t.Errorf("Unexpected list --filter output %q", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Roadmap(argumentList{"--filter", "priority:high", "--simple"}, config)
+	}, t)
+	if !strings.Contains(stdout, "## v1:\n- Crash on start\n\n## No milestone set:\n- Slow start\n") ||
+		strings.Contains(stdout, "Leaks") {
+		t.Errorf("Unexpected roadmap --filter output %q", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Export(argumentList{"--json", "--filter", query}, config)
+	}, t)
+	if !strings.Contains(stdout, `"Dir": "Crash-on-start"`) || strings.Contains(stdout, "Leaks") ||
+		strings.Contains(stdout, "Slow") {
+		t.Errorf("Unexpected export --filter output %s", stdout)
+	}
+
+	_, stderr := captureOutput(func() {
+		Find(argumentList{"status:open", "AND", "(priority:high"}, config)
+	}, t)
+	expected := "Invalid query: expected \")\", got end of query at column 31\n" +
+		"    status:open AND (priority:high\n" +
+		"                                  ^\n"
+	if stderr != expected {
+		t.Errorf("Unexpected query error %q, expected %q", stderr, expected)
+	}
+}
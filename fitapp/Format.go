@@ -0,0 +1,147 @@
+package fitapp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/ghodss/yaml"
+	"os"
+	"strings"
+)
+
+// outputFormats are the values accepted by the --format option.
+var outputFormats = []string{"text", "json", "ndjson", "yaml", "csv"}
+
+// outputRecord is one row of machine readable output.
+type outputRecord map[string]interface{}
+
+// outputReport is what a subcommand prints. Records are only built for
+// machine readable formats, Text prints the human readable form and
+// Columns orders the csv header.
+type outputReport struct {
+	Columns []string
+	Records func() []outputRecord
+	Text    func()
+}
+
+// exit ends the program with an error status, tests replace it.
+var exit = os.Exit
+
+// unknownFormat prints the formats to use instead of format to stderr
+// and exits with an error status.
+func unknownFormat(format string, formats []string) {
+	fmt.Fprintf(os.Stderr, "Error: Unknown format: %s, use one of %s\n", format, strings.Join(formats, ", "))
+	exit(1)
+}
+
+// issueColumns are the csv columns of issueRecord.
+var issueColumns = []string{"Identifier", "Title", "Status", "Priority", "Milestone", "Tags"}
+
+// formatArgument removes --format <format> from args.
+// The default format is text.
+func formatArgument(args argumentList) (argumentList, string) {
+	if !args.HasArgument("--format") {
+		return args, "text"
+	}
+	args, values := args.GetAndRemoveArguments([]string{"--format"})
+	return args, strings.ToLower(values[0])
+}
+
+// issueRecord returns the fields of b for machine readable output.
+func issueRecord(b bugs.Issue) outputRecord {
+	tags := b.StringTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return outputRecord{
+		"Identifier": b.Identifier(),
+		"Title":      b.Title(""),
+		"Status":     b.Status(),
+		"Priority":   b.Priority(),
+		"Milestone":  b.Milestone(),
+		"Tags":       tags,
+	}
+}
+
+// writeReport prints r to stdout in format.
+func writeReport(format string, r outputReport) {
+	if format == "text" {
+		r.Text()
+		return
+	}
+	known := false
+	for _, f := range outputFormats {
+		known = known || f == format
+	}
+	if !known {
+		unknownFormat(format, outputFormats)
+		return
+	}
+	records := r.Records()
+	if records == nil {
+		records = []outputRecord{}
+	}
+	if err := writeRecords(format, r.Columns, records); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %s\n", format, err.Error())
+	}
+}
+
+// writeRecords prints records in one of the machine readable formats.
+func writeRecords(format string, columns []string, records []outputRecord) error {
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", out)
+	case "ndjson":
+		for _, record := range records {
+			out, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s\n", out)
+		}
+	case "yaml":
+		out, err := yaml.Marshal(records)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s", out)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(columns)
+		for _, record := range records {
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = csvValue(record[column])
+			}
+			w.Write(row)
+		}
+		w.Flush()
+		return w.Error()
+	}
+	return nil
+}
+
+// csvValue returns a single csv cell. Lists are joined with commas and
+// anything else that is not a string or number is json encoded.
+func csvValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ",")
+	case int, bool:
+		return fmt.Sprintf("%v", value)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
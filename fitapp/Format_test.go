@@ -0,0 +1,126 @@
+package fitapp
+
+import (
+	"encoding/json"
+	"flag"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// checkGolden compares got with testdata/name, rewriting it with -update.
+func checkGolden(t *testing.T, name, got string) {
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		os.MkdirAll("testdata", 0755)
+		if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Could not read golden file %s: %s", golden, err.Error())
+	}
+	if got != string(expected) {
+		t.Errorf("Unexpected output for %s\nExpected:\n%s\nGot:\n%s", golden, expected, got)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	pwd, _ := os.Getwd()
+	golden := func(name, got string) {
+		os.Chdir(pwd)
+		checkGolden(t, name, got)
+	}
+	dir, err := ioutil.TempDir("", "formattest")
+	if err != nil {
+		t.Error("Could not create temporary dir for test")
+		return
+	}
+	os.Chdir(dir)
+	dir, _ = os.Getwd()
+	os.MkdirAll(config.FitDirName, 0700)
+	defer os.RemoveAll(dir)
+	err = os.Setenv("FIT", dir)
+	if err != nil {
+		t.Error("Could not set environment variable: " + err.Error())
+		return
+	}
+	captureOutput(func() {
+		Create(argumentList{"-n", "Alpha", "bug", "--status", "open", "--milestone", "v1.0", "--tag", "cli"}, config)
+		Create(argumentList{"-n", "Beta", "bug", "--priority", "high", "--milestone", "v2.0", "--tag", "cli"}, config)
+		Create(argumentList{"-n", "Gamma", "bug", "--status", "open", "--identifier", "GH-3"}, config)
+	}, t)
+	// The temporary directory name is part of the text output.
+	normalize := func(s string) string {
+		s = strings.Replace(s, dir, "$FIT", -1)
+		return strings.Replace(s, filepath.Base(dir), "$FIT", -1)
+	}
+
+	for _, format := range outputFormats {
+		os.Chdir(dir)
+		stdout, stderr := captureOutput(func() {
+			List(argumentList{"--format", format}, config, true)
+		}, t)
+		if stderr != "" {
+			t.Error("Unexpected error: " + stderr)
+		}
+		golden("list."+format, normalize(stdout))
+
+		os.Chdir(dir)
+		stdout, _ = captureOutput(func() {
+			List(argumentList{"2", "--format", format}, config, true)
+		}, t)
+		golden("view."+format, normalize(stdout))
+
+		os.Chdir(dir)
+		stdout, _ = captureOutput(func() {
+			Find(argumentList{"--format", format, "status", "open"}, config)
+		}, t)
+		golden("find."+format, normalize(stdout))
+
+		os.Chdir(dir)
+		stdout, _ = captureOutput(func() {
+			TagsAssigned(argumentList{"--format", format, "--count"}, config)
+		}, t)
+		golden("tags."+format, normalize(stdout))
+
+		os.Chdir(dir)
+		stdout, _ = captureOutput(func() {
+			Roadmap(argumentList{"--format", format}, config)
+		}, t)
+		golden("roadmap."+format, normalize(stdout))
+	}
+
+	os.Chdir(dir)
+	stdout, _ := captureOutput(func() {
+		Env(argumentList{"--format", "json"}, config)
+	}, t)
+	var env []struct {
+		FitDirectory string
+		Config       bugs.Config
+	}
+	if err := json.Unmarshal([]byte(stdout), &env); err != nil || len(env) != 1 ||
+		env[0].FitDirectory != dir+sops+"fit" || env[0].Config.FitDirName != "fit" {
+		t.Errorf("Unexpected env json %v %s", err, stdout)
+	}
+
+	status := 0
+	exit = func(code int) { status = code }
+	defer func() { exit = os.Exit }()
+	stdout, stderr := captureOutput(func() {
+		List(argumentList{"--format", "xml"}, config, true)
+	}, t)
+	if stdout != "" || !strings.HasPrefix(stderr, "Error: Unknown format: xml") || status != 1 {
+		t.Errorf("Unexpected output for unknown format: %q %q %d", stdout, stderr, status)
+	}
+	os.Chdir(pwd)
+}
@@ -0,0 +1,152 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"strings"
+)
+
+// graphNode is an issue of the graph, or the target of a missing link.
+type graphNode struct {
+	id    string
+	label string
+	state string // closed, missing or empty
+}
+
+// graphNodes returns the nodes of the issues with links, in the order of
+// issue numbers followed by the targets of missing links, by name.
+func graphNodes(g *bugs.LinkGraph) ([]graphNode, map[string]string) {
+	linked := map[string]bool{}
+	for _, e := range g.Edges {
+		linked[e.From] = true
+		linked[e.To] = true
+	}
+	var nodes []graphNode
+	ids := map[string]string{}
+	add := func(name, label, state string) {
+		if _, ok := ids[name]; ok {
+			return
+		}
+		ids[name] = fmt.Sprintf("n%d", len(nodes)+1)
+		nodes = append(nodes, graphNode{ids[name], label, state})
+	}
+	for _, r := range g.Issues {
+		if linked[r.Name] {
+			state := ""
+			if r.Closed() {
+				state = "closed"
+			}
+			add(r.Name, linkName(g, r)+": "+r.Title, state)
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Missing {
+			add(e.To, e.To, "missing")
+		}
+	}
+	return nodes, ids
+}
+
+// graphDot prints the graph in the DOT language of graphviz.
+func graphDot(g *bugs.LinkGraph) {
+	nodes, ids := graphNodes(g)
+	fmt.Printf("digraph issues {\n")
+	for _, n := range nodes {
+		label := n.label
+		attrs := ""
+		if n.state != "" {
+			label += " (" + n.state + ")"
+			attrs = ", style=dashed"
+		}
+		fmt.Printf("\t%s [label=%q%s];\n", n.id, label, attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := ""
+		switch e.Relation {
+		case bugs.LinkRelatesTo:
+			attrs = ", dir=none"
+		case bugs.LinkDuplicates:
+			attrs = ", style=dotted"
+		}
+		fmt.Printf("\t%s -> %s [label=%q%s];\n", ids[e.From], ids[e.To], e.Relation, attrs)
+	}
+	fmt.Printf("}\n")
+}
+
+// graphMermaid prints the graph as a mermaid flowchart.
+func graphMermaid(g *bugs.LinkGraph) {
+	nodes, ids := graphNodes(g)
+	fmt.Printf("graph TD\n")
+	for _, n := range nodes {
+		label := strings.Replace(n.label, `"`, "#quot;", -1)
+		if n.state != "" {
+			label += " (" + n.state + ")"
+		}
+		fmt.Printf("    %s[\"%s\"]\n", n.id, label)
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		switch e.Relation {
+		case bugs.LinkRelatesTo:
+			arrow = "---"
+		case bugs.LinkDuplicates:
+			arrow = "-.->"
+		}
+		fmt.Printf("    %s %s|%s| %s\n", ids[e.From], arrow, e.Relation, ids[e.To])
+	}
+}
+
+// graphCheck prints the cycles of blocking issues and the links to
+// missing and closed issues and returns how many it found.
+func graphCheck(g *bugs.LinkGraph) int {
+	problems := 0
+	title := func(name string) string {
+		if number := g.Number(name); number > 0 {
+			r := g.Issues[number-1]
+			return linkName(g, r) + " (" + r.Title + ")"
+		}
+		return name
+	}
+	for _, cycle := range g.Cycles() {
+		var names []string
+		for _, name := range cycle {
+			names = append(names, title(name))
+		}
+		fmt.Printf("Cycle: %s block each other\n", strings.Join(names, ", "))
+		problems++
+	}
+	missing, closed := g.Dangling()
+	for _, e := range missing {
+		fmt.Printf("Missing: %s %s %s, closed or renamed\n", title(e.From), e.Relation, e.To)
+		problems++
+	}
+	for _, e := range closed {
+		fmt.Printf("Closed: %s %s %s\n", title(e.From), e.Relation, title(e.To))
+		problems++
+	}
+	return problems
+}
+
+// Graph is a subcommand to print the links between issues as a DOT or
+// mermaid graph, or with --check the cycles of blocking issues and the
+// links to missing or closed issues.
+func Graph(args argumentList, config bugs.Config) {
+	format := "dot"
+	if args.HasArgument("--format") {
+		args, format = formatArgument(args)
+	}
+	check := args.HasArgument("--check")
+	g := bugs.NewLinkGraph(config)
+	switch {
+	case check:
+		if graphCheck(g) == 0 {
+			fmt.Printf("No problems found\n")
+		}
+	case format == "dot":
+		graphDot(g)
+	case format == "mermaid":
+		graphMermaid(g)
+	default:
+		unknownFormat(format, []string{"dot", "mermaid"})
+	}
+}
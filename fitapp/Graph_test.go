@@ -0,0 +1,115 @@
+package fitapp
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// graphLines returns the sorted lines of a graph with the ids of nodes
+// in edges replaced by their labels, since nodes are numbered in the
+// order of issue numbers.
+func graphLines(graph string) []string {
+	node := regexp.MustCompile(`^\s*(n\d+) ?\[(?:label=)?"([^"]*)"`)
+	id := regexp.MustCompile(`\bn\d+\b`)
+	labels := map[string]string{}
+	lines := strings.Split(strings.TrimSpace(graph), "\n")
+	for _, line := range lines {
+		if m := node.FindStringSubmatch(line); m != nil {
+			labels[m[1]] = m[2]
+		}
+	}
+	var out []string
+	for _, line := range lines {
+		if node.MatchString(line) {
+			line = id.ReplaceAllString(line, "node")
+		} else {
+			line = id.ReplaceAllStringFunc(line, func(n string) string { return labels[n] })
+		}
+		out = append(out, strings.TrimSpace(line))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestGraph(t *testing.T) {
+	config, done := setupLinkedIssues(t)
+	defer done()
+
+	stdout, _ := captureOutput(func() {
+		Graph(argumentList{}, config)
+	}, t)
+	expected := []string{
+		"Issue API: Api -> Issue UI: Ui [label=\"blocks\"];",
+		"Issue DB: Database schema -> Issue API: Api [label=\"blocks\"];",
+		"Issue DOC: Docs -> Issue UI: Ui [label=\"relates-to\", dir=none];",
+		"digraph issues {",
+		"node [label=\"Issue API: Api\"];",
+		"node [label=\"Issue DB: Database schema\"];",
+		"node [label=\"Issue DOC: Docs\"];",
+		"node [label=\"Issue UI: Ui\"];",
+		"}",
+	}
+	if lines := graphLines(stdout); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Unexpected dot graph: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Graph(argumentList{"--check"}, config)
+	}, t)
+	if stdout != "No problems found\n" {
+		t.Errorf("Unexpected problems: %s", stdout)
+	}
+
+	os.RemoveAll(os.Getenv("FIT") + "/fit/Database-schema")
+	captureOutput(func() {
+		Status(argumentList{"UI", "closed"}, config)
+		Link(argumentList{"UI", "blocks", "DOC"}, config)
+		Link(argumentList{"DOC", "blocks", "UI"}, config)
+	}, t)
+	stdout, _ = captureOutput(func() {
+		Graph(argumentList{"--format", "mermaid"}, config)
+	}, t)
+	expected = []string{
+		"Issue API: Api -->|blocks| Issue UI: Ui (closed)",
+		"Issue API: Api -->|depends-on| DB (missing)",
+		"Issue DOC: Docs ---|relates-to| Issue UI: Ui (closed)",
+		"Issue DOC: Docs -->|blocks| Issue UI: Ui (closed)",
+		"Issue UI: Ui (closed) -->|blocks| Issue DOC: Docs",
+		"graph TD",
+		"node[\"DB (missing)\"]",
+		"node[\"Issue API: Api\"]",
+		"node[\"Issue DOC: Docs\"]",
+		"node[\"Issue UI: Ui (closed)\"]",
+	}
+	if lines := graphLines(stdout); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Unexpected mermaid graph: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Graph(argumentList{"--check"}, config)
+	}, t)
+	for _, line := range []string{
+		"Missing: Issue API (Api) depends-on DB, closed or renamed\n",
+		"Closed: Issue DOC (Docs) relates-to Issue UI (Ui)\n",
+		"Closed: Issue DOC (Docs) blocks Issue UI (Ui)\n",
+	} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("Expected %q in problems: %s", line, stdout)
+		}
+	}
+	if !strings.HasPrefix(stdout, "Cycle: Issue DOC (Docs), Issue UI (Ui) block each other\n") &&
+		!strings.HasPrefix(stdout, "Cycle: Issue UI (Ui), Issue DOC (Docs) block each other\n") {
+		t.Errorf("Expected a cycle in problems: %s", stdout)
+	}
+	status := 0
+	exit = func(code int) { status = code }
+	defer func() { exit = os.Exit }()
+	_, stderr := captureOutput(func() {
+		Graph(argumentList{"--format", "svg"}, config)
+	}, t)
+	if stderr != "Error: Unknown format: svg, use one of dot, mermaid\n" || status != 1 {
+		t.Errorf("Unexpected output of an unknown format: %s %d", stderr, status)
+	}
+}
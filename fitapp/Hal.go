@@ -0,0 +1,308 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The serve subcommand answers with resources in the HAL draft format
+// https://tools.ietf.org/html/draft-kelly-json-hal-08 so clients can
+// navigate from the root document without hard coded urls.
+
+// halContentType is the media type of HAL documents.
+const halContentType = "application/hal+json"
+
+// halNoMilestone is the milestone path segment for issues without one.
+const halNoMilestone = "-"
+
+// halPerPage is the default page size of collections.
+const halPerPage = 30
+
+// halMaxPerPage is the largest page size of collections.
+const halMaxPerPage = 100
+
+// halLink is a HAL link object.
+type halLink struct {
+	Href      string `json:"href"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+// halLinks holds link relations by name.
+type halLinks map[string]halLink
+
+// halRoot is the entry point document.
+type halRoot struct {
+	Links halLinks `json:"_links"`
+}
+
+// halIssue is an issue with links to its related resources.
+type halIssue struct {
+	Links halLinks `json:"_links"`
+	bugs.IssueJSON
+}
+
+// halMilestone is a milestone with the count of its issues.
+type halMilestone struct {
+	Links halLinks `json:"_links"`
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+}
+
+// halComment is a comment of an issue.
+type halComment struct {
+	Order  int
+	Author string `json:",omitempty"`
+	Time   time.Time
+	Body   string
+}
+
+// halTags is the tags resource of an issue.
+type halTags struct {
+	Links halLinks `json:"_links"`
+	Tags  []string `json:"tags"`
+}
+
+// halFields is the fields resource of an issue.
+type halFields struct {
+	Links  halLinks          `json:"_links"`
+	Fields map[string]string `json:"fields"`
+}
+
+// halField is one field of an issue.
+type halField struct {
+	Links halLinks `json:"_links"`
+	Value string
+}
+
+// halCollection is one page of embedded resources.
+type halCollection struct {
+	Links    halLinks               `json:"_links"`
+	Count    int                    `json:"count"`
+	Total    int                    `json:"total"`
+	Embedded map[string]interface{} `json:"_embedded"`
+}
+
+// issueRef is an issue with the IssueID used in its links.
+type issueRef struct {
+	ref   string
+	issue bugs.Issue
+}
+
+// issueRefs returns all issues in IssueID index order. The ref is the
+// Identifier when set, otherwise the index like "bug list".
+func issueRefs(config bugs.Config) []issueRef {
+	fitdir := bugs.FitDirer(config)
+	issues := readIssues(string(fitdir))
+	sort.Sort(byDir(issues))
+	refs := []issueRef{}
+	for idx, issue := range issues {
+		b := bugs.Issue{}
+		b.LoadIssue(fitdir+dops+bugs.Directory(issue.Name()), config)
+		ref := b.Identifier()
+		if ref == "" {
+			ref = strconv.Itoa(idx + 1)
+		}
+		refs = append(refs, issueRef{ref: ref, issue: b})
+	}
+	return refs
+}
+
+// refForIssue returns the IssueID used in the links of b, one of refs
+// from issueRefs.
+func refForIssue(b bugs.Issue, refs []issueRef) string {
+	if id := b.Identifier(); id != "" {
+		return id
+	}
+	for _, r := range refs {
+		if r.issue.Dir == b.Dir {
+			return r.ref
+		}
+	}
+	return string(b.Dir.ShortNamer())
+}
+
+func issueHref(ref string) string {
+	return "/issues/" + url.PathEscape(ref)
+}
+
+func milestoneHref(name string) string {
+	if name == "" {
+		name = halNoMilestone
+	}
+	return "/milestones/" + url.PathEscape(name)
+}
+
+func newHalRoot() halRoot {
+	return halRoot{Links: halLinks{
+		"self":       {Href: "/"},
+		"issues":     {Href: "/issues{?page,per_page}", Templated: true},
+		"issue":      {Href: "/issues/{IssueID}", Templated: true},
+		"milestones": {Href: "/milestones"},
+	}}
+}
+
+func newHalIssue(r issueRef) halIssue {
+	self := issueHref(r.ref)
+	h := halIssue{
+		Links: halLinks{
+			"self":       {Href: self, Title: r.issue.Title("")},
+			"collection": {Href: "/issues"},
+			"comments":   {Href: self + "/comments"},
+			"tags":       {Href: self + "/tags"},
+			"fields":     {Href: self + "/fields"},
+			"milestones": {Href: "/milestones"},
+		},
+		IssueJSON: r.issue.ToJSON(),
+	}
+	if ms := r.issue.Milestone(); ms != "" {
+		h.Links["milestone"] = halLink{Href: milestoneHref(ms), Title: ms}
+	}
+	return h
+}
+
+func newHalTags(r issueRef) halTags {
+	self := issueHref(r.ref)
+	tags := r.issue.StringTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return halTags{
+		Links: halLinks{"self": {Href: self + "/tags"}, "issue": {Href: self}},
+		Tags:  tags,
+	}
+}
+
+func newHalFields(r issueRef) halFields {
+	self := issueHref(r.ref)
+	h := halFields{
+		Links:  halLinks{"self": {Href: self + "/fields"}, "issue": {Href: self}},
+		Fields: map[string]string{},
+	}
+	for _, f := range []string{"Status", "Priority", "Milestone", "Identifier"} {
+		h.Links[f] = halLink{Href: self + "/fields/" + f}
+		if v := fieldValue(r.issue, f); v != "" {
+			h.Fields[f] = v
+		}
+	}
+	return h
+}
+
+func newHalField(r issueRef, field string) halField {
+	self := issueHref(r.ref)
+	return halField{
+		Links: halLinks{"self": {Href: self + "/fields/" + field}, "issue": {Href: self}},
+		Value: fieldValue(r.issue, field),
+	}
+}
+
+// fieldValue returns the first line of a field of b.
+func fieldValue(b bugs.Issue, field string) string {
+	switch field {
+	case "Status":
+		return b.Status()
+	case "Priority":
+		return b.Priority()
+	case "Milestone":
+		return b.Milestone()
+	case "Identifier", "Id":
+		return b.Identifier()
+	}
+	for _, tag := range b.StringTags() {
+		if kv := strings.SplitN(tag, ":", 2); len(kv) == 2 && kv[0] == strings.ToLower(field) {
+			return kv[1]
+		}
+	}
+	return ""
+}
+
+// milestonesOf returns milestones of refs in roadmap order, highest first.
+func milestonesOf(refs []issueRef) []halMilestone {
+	var all []bugs.Issue
+	for _, r := range refs {
+		all = append(all, r.issue)
+	}
+	sort.Sort(IssueListByMilestone(all))
+	ms := []halMilestone{}
+	for i := len(all) - 1; i >= 0; i-- {
+		name := all[i].Milestone()
+		if len(ms) > 0 && ms[len(ms)-1].Name == name {
+			ms[len(ms)-1].Count++
+			continue
+		}
+		title := name
+		if name == "" {
+			title = "No milestone set"
+		}
+		ms = append(ms, halMilestone{
+			Links: halLinks{"self": {Href: milestoneHref(name), Title: title}},
+			Name:  name,
+			Count: 1,
+		})
+	}
+	return ms
+}
+
+// halPage returns the page and per_page query parameters.
+func halPage(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = halPerPage
+	} else if perPage > halMaxPerPage {
+		perPage = halMaxPerPage
+	}
+	return page, perPage
+}
+
+// newHalCollection embeds one page of items with self, first, last,
+// next and prev links.
+func newHalCollection(r *http.Request, base, name string, items []interface{}) halCollection {
+	page, perPage := halPage(r)
+	total := len(items)
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+	pageHref := func(p int) string {
+		return fmt.Sprintf("%s?page=%d&per_page=%d", base, p, perPage)
+	}
+	links := halLinks{
+		"self":  {Href: pageHref(page)},
+		"first": {Href: pageHref(1)},
+		"last":  {Href: pageHref(last)},
+	}
+	if page < last {
+		links["next"] = halLink{Href: pageHref(page + 1)}
+	}
+	if page > 1 {
+		links["prev"] = halLink{Href: pageHref(page - 1)}
+	}
+	// pages after the last are empty, without multiplying a large page
+	start, end := total, total
+	if page-1 <= total/perPage {
+		start = (page - 1) * perPage
+		if start > total {
+			start = total
+		}
+		end = start + perPage
+		if end > total {
+			end = total
+		}
+	}
+	return halCollection{
+		Links:    links,
+		Count:    end - start,
+		Total:    total,
+		Embedded: map[string]interface{}{name: items[start:end]},
+	}
+}
@@ -0,0 +1,130 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type halTestLink struct {
+	Href string `json:"href"`
+}
+
+type halTestIssue struct {
+	Links map[string]halTestLink `json:"_links"`
+	Title string
+}
+
+type halTestCollection struct {
+	Links    map[string]halTestLink `json:"_links"`
+	Count    int                    `json:"count"`
+	Total    int                    `json:"total"`
+	Embedded struct {
+		Issues     []halTestIssue `json:"issues"`
+		Milestones []struct {
+			Links map[string]halTestLink `json:"_links"`
+			Name  string                 `json:"name"`
+			Count int                    `json:"count"`
+		} `json:"milestones"`
+		Comments []halComment `json:"comments"`
+	} `json:"_embedded"`
+}
+
+func TestHalNavigation(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "haltest")
+	if err != nil {
+		t.Error("Could not create temporary dir for test")
+		return
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	defer os.RemoveAll(dir)
+	err = os.Setenv("FIT", dir)
+	if err != nil {
+		t.Error("Could not set environment variable: " + err.Error())
+		return
+	}
+	srv := httptest.NewServer(serveHandler(config))
+	defer srv.Close()
+
+	servetest(t, srv, "POST", "/issues", `{"Title": "One", "Milestone": "v1.0"}`, http.StatusCreated, nil)
+	servetest(t, srv, "POST", "/issues", `{"Title": "Two", "Milestone": "v1.0"}`, http.StatusCreated, nil)
+	servetest(t, srv, "POST", "/issues", `{"Title": "Three", "Milestone": "v2.0", "Identifier": "GitHub:u/r#3"}`, http.StatusCreated, nil)
+	servetest(t, srv, "POST", "/issues", `{"Title": "Four"}`, http.StatusCreated, nil)
+
+	var root halRoot
+	servetest(t, srv, "GET", "/", "", http.StatusOK, &root)
+	if root.Links["milestones"].Href != "/milestones" || !root.Links["issues"].Templated {
+		t.Errorf("Unexpected root links %+v", root.Links)
+	}
+
+	var milestones halTestCollection
+	servetest(t, srv, "GET", root.Links["milestones"].Href, "", http.StatusOK, &milestones)
+	ms := milestones.Embedded.Milestones
+	if len(ms) != 3 || ms[0].Name != "v2.0" || ms[1].Name != "v1.0" || ms[1].Count != 2 || ms[2].Name != "" {
+		t.Fatalf("Unexpected milestones %+v", ms)
+	}
+
+	var issues halTestCollection
+	servetest(t, srv, "GET", ms[1].Links["self"].Href, "", http.StatusOK, &issues)
+	if issues.Total != 2 || issues.Embedded.Issues[0].Title != "One" {
+		t.Fatalf("Unexpected milestone issues %+v", issues)
+	}
+	var one halTestIssue
+	servetest(t, srv, "GET", issues.Embedded.Issues[0].Links["self"].Href, "", http.StatusOK, &one)
+	for _, rel := range []string{"self", "comments", "tags", "fields", "milestone", "collection"} {
+		if one.Links[rel].Href == "" {
+			t.Errorf("Missing %s link in %+v", rel, one.Links)
+		}
+	}
+	if one.Links["milestone"].Href != ms[1].Links["self"].Href {
+		t.Errorf("Unexpected milestone link %+v", one.Links["milestone"])
+	}
+
+	var three halTestIssue
+	servetest(t, srv, "GET", ms[0].Links["self"].Href, "", http.StatusOK, &issues)
+	servetest(t, srv, "GET", issues.Embedded.Issues[0].Links["self"].Href, "", http.StatusOK, &three)
+	if three.Title != "Three" || three.Links["self"].Href != "/issues/GitHub:u%2Fr%233" {
+		t.Errorf("Unexpected identifier link %+v", three)
+	}
+
+	var page halTestCollection
+	servetest(t, srv, "GET", "/issues?per_page=3", "", http.StatusOK, &page)
+	if page.Count != 3 || page.Total != 4 || page.Links["prev"].Href != "" {
+		t.Errorf("Unexpected first page %+v", page)
+	}
+	var next halTestCollection
+	servetest(t, srv, "GET", page.Links["next"].Href, "", http.StatusOK, &next)
+	if next.Count != 1 || next.Links["next"].Href != "" || next.Links["prev"].Href == "" {
+		t.Errorf("Unexpected second page %+v", next)
+	}
+	// large pages and page sizes do not overflow
+	servetest(t, srv, "GET", "/issues?page=2&per_page=9223372036854775807", "", http.StatusOK, &next)
+	if next.Count != 0 || next.Total != 4 || next.Links["self"].Href != "/issues?page=2&per_page=100" {
+		t.Errorf("Unexpected page of a large page size %+v", next)
+	}
+	servetest(t, srv, "GET", "/issues?page=9223372036854775807&per_page=3", "", http.StatusOK, &next)
+	if next.Count != 0 || next.Total != 4 {
+		t.Errorf("Unexpected large page %+v", next)
+	}
+
+	servetest(t, srv, "POST", one.Links["comments"].Href, `{"Author": "me", "Body": "first"}`, http.StatusCreated, nil)
+	var comments halTestCollection
+	servetest(t, srv, "GET", one.Links["comments"].Href, "", http.StatusOK, &comments)
+	if comments.Total != 1 || comments.Embedded.Comments[0].Body != "first" {
+		t.Errorf("Unexpected comments %+v", comments)
+	}
+	var fields halFields
+	servetest(t, srv, "GET", one.Links["fields"].Href, "", http.StatusOK, &fields)
+	if fields.Fields["Milestone"] != "v1.0" {
+		t.Errorf("Unexpected fields %+v", fields)
+	}
+	os.Chdir(pwd)
+}
@@ -49,6 +49,9 @@ aliases for create: add new
 		fmt.Printf("       " + os.Args[0] + " list <-t|--tags> <IssueID>...\n")
 		fmt.Printf("       " + os.Args[0] + " list <tag>...\n\n")
 		fmt.Printf("       " + os.Args[0] + " list <-r|--recursive>...\n")
+		fmt.Printf("       " + os.Args[0] + " list --filter <query> [-t|--tags]\n")
+		fmt.Printf("       " + os.Args[0] + " list --format <json|ndjson|yaml|csv|text>...\n")
+		fmt.Printf("       " + os.Args[0] + " list <--rev <revision>|--at <date>>...\n")
 		fmt.Printf(
 			`This will list the issues found in the current environment
 
@@ -75,6 +78,18 @@ issues. Details are provided by "fit help ids."
 
 The [-r|--recursive] option lists matching issues in subdirectories.
 
+The --filter option lists the issues matching a query, see
+"fit help find" for the query language.
+
+The --format option prints the selected issues as json, ndjson, yaml
+or csv for scripts instead of text. IssueIDs include the Description.
+Subdirectories are not searched for these formats.
+
+The --rev option lists the issues of a git or hg revision, like a tag
+or branch, instead of the working tree. The --at option lists the
+issues of the last commit before a date, YYYY-MM-DD[ HH:MM[:SS]].
+The working tree is not changed. find and roadmap take them too.
+
 aliases for list: view show display ls
 `)
 
@@ -106,6 +121,9 @@ command "%s edit status <IssueID>". If you provide a longer than 1 line
 status with "fit edit status", "fit status" will preserve everything
 after the first line when editing a status. You can use this to provide
 further context on a status (for instance, why that status is setup.)
+
+Without an IssueID status prints the settings like "fit env" followed by
+the open issues with a due date in the past.
 `, os.Args[0])
 	case "priority":
 		fmt.Printf("usage: " + os.Args[0] + " priority <IssueID> <New Priority>\n\n")
@@ -148,6 +166,28 @@ milestone is set) with the command "fit edit milestone <IssueID>"
 
 This command will preserve the explanation when updating a priority.
 `, os.Args[0], os.Args[0])
+	case "due":
+		fmt.Printf("usage: " + os.Args[0] + " due <IssueID> [--reported|--started|--closed] <date>\n")
+		fmt.Printf("       " + os.Args[0] + " due <IssueID> [--reported|--started|--closed] --rm\n")
+		fmt.Printf("       " + os.Args[0] + " due <IssueID>\n\n")
+		fmt.Printf(
+			`This will set the due date of the issue identified by IssueID, or
+with --reported, --started or --closed that date instead. With --rm the
+date is removed and with only an IssueID the dates of the issue are
+printed.
+
+Dates are a day like 2026-11-01, a time like "2026-11-01 17:00" in the
+local time zone unless followed by an offset or the name of a zone like
+"2026-11-01 17:00 Europe/Paris", or a number of hours, days or weeks
+from now like 12h, 7d or -2w. Days are written as YYYY-MM-DD and times
+in UTC like 2026-11-01T160000Z to the Due, Reported, Started or Closed
+file, or to a tag file with NewFieldAsTag.
+
+Create sets the Reported date and close with CloseStatusTag the Closed
+date. Queries compare dates with < and >, like "fit find due<7d" for the
+issues due within a week, and overdue finds the open issues with a due
+date in the past, which "fit status" also prints.
+`)
 	case "retitle", "mv", "rename", "relabel":
 		fmt.Printf("usage: " + os.Args[0] + " retitle <IssueID> <New Title>\n\n")
 		fmt.Printf(
@@ -176,26 +216,184 @@ alias for close: rm
 		fmt.Printf("usage: " + os.Args[0] + " find tag <value1> [value2 ...]\n")
 		fmt.Printf("usage: " + os.Args[0] + " find status <value1> [value2 ...]\n")
 		fmt.Printf("usage: " + os.Args[0] + " find priority <value1> [value2 ...]\n")
-		fmt.Printf("usage: " + os.Args[0] + " find milestone <value1> [value2 ...]\n\n")
+		fmt.Printf("usage: " + os.Args[0] + " find milestone <value1> [value2 ...]\n")
+		fmt.Printf("usage: " + os.Args[0] + " find <query>\n")
+		fmt.Printf("usage: " + os.Args[0] + " find --format <json|ndjson|yaml|csv|text> ...\n")
+		fmt.Printf("usage: " + os.Args[0] + " find <--rev <revision>|--at <date>> ...\n\n")
 		fmt.Printf(
 			`This will search all issues for multiple tags, statuses, priorities, or milestone.
-The matching issues will be printed.
+The matching issues will be printed, as text unless --format is given.
+
+A query combines predicates with AND, OR, NOT and parentheses:
+
+    fit find 'status:open AND (priority:high OR tag:security) AND NOT milestone:v2'
+
+field:value is true when the field equals the value, ignoring case.
+For title and description the value can be any part of the text.
+field~regex is true when the field matches the regular expression,
+ignoring case. The fields are status, priority, milestone, identifier
+(or id), tag (or tags), title, description and the dates reported,
+due, started and closed. Quote values with spaces or parentheses:
+description~"nil pointer". An empty value, milestone:"", matches
+issues without the field. NOT binds tightest, then AND, then OR, and
+predicates without an operator between them are joined by AND.
+
+Dates are compared with field<date and field>date, see "fit help due":
+due<7d is true for issues due within a week, reported>2026-01-01 for
+issues reported after that day. overdue is true for open issues with a
+due date in the past.
+
+list, roadmap and export take the same query with --filter.
+
+--rev <revision> and --at <date> search the issues of a git or hg
+revision, see "fit help list".
+`)
+	case "search":
+		fmt.Printf("usage: " + os.Args[0] + " search [-r|--recursive] <term>...\n")
+		fmt.Printf("       " + os.Args[0] + " search --format <json|ndjson|yaml|csv|text> <term>...\n\n")
+		fmt.Printf(
+			`This will search the titles, Descriptions, comments, field values
+and tags of every issue for the terms. Issues containing any of the
+terms are listed best match first, ranked by BM25, with a snippet of
+the matching text and the terms **highlighted**.
+
+Terms are words of letters and digits, case is ignored. Matches in the
+title count the most, then field values and tags, then Description
+and comments.
+
+The [-r|--recursive] option, or MultipleFitDirs in the config, also
+searches the fit directories of subdirectories.
+`)
+	case "reindex":
+		fmt.Printf("usage: " + os.Args[0] + " reindex\n\n")
+		fmt.Printf(
+			`This will rebuild the cache of the issues. The cache holds the
+titles, identifiers, fields and tags of every issue so that looking up
+an IssueID, find, tagslist and roadmap do not read every issue.
+
+The cache is kept in the .git or .hg directory, or next to the fit
+directory without one. It is updated automatically: an issue is read
+again when files are added to or removed from its directory, which
+includes git and hg checkouts, and when fit changes its fields or tags.
+Run reindex after changing field files in place with other programs.
 `)
 	case "purge":
 		fmt.Printf("usage: " + os.Args[0] + " purge\n\n")
 		fmt.Printf(
 			`This will delete any issues that are not currently tracked by
-git or hg.
+git or hg. With hg the purge extension is not needed.
+`)
+	case "log":
+		fmt.Printf("usage: " + os.Args[0] + " log <IssueID>\n\n")
+		fmt.Printf(
+			`This will print the history of the issue identified by IssueID
+from the git or hg log, newest first. Each commit that changed the
+issue is listed with its author and date, followed by the changes:
+fields like Status or Priority with their old and new values, added
+and removed tags, added, edited and removed comments and the diff of
+the Description. Renames of the issue directory are followed.
+`)
+	case "diff":
+		fmt.Printf("usage: " + os.Args[0] + " diff [<revision> [<revision>]] [--format <format>]\n\n")
+		fmt.Printf(
+			`This will print the issues created, closed, renamed or changed
+from the first revision to the second, or to the working tree without
+a second revision. Without revisions the last commit is compared to the
+working tree. Issues are closed when their directory was removed or
+their Status became closed. A removed and an added issue directory
+are one renamed issue with the same Identifier, or without identifiers
+with the same Description, other than the DefaultDescriptionFile
+template, and a title sharing a word. Changes list field transitions like
+Status: open -> closed, added and removed tags, new comments and
+whether the Description changed.
+
+The format is one of text, json, ndjson, yaml or csv, text by default.
+`)
+	case "notify":
+		fmt.Printf("usage: " + os.Args[0] + " notify [<revision> [<revision>]] [--channel email|sms|webhook] [--dry-run]\n\n")
+		fmt.Printf(
+			`This will notify the recipients of the issues changed between two
+revisions, like "fit diff", by email, sms with twilio or a json webhook.
+Without revisions the changes since the last commit are sent.
+
+The recipients of an issue are its watchers, see "fit help watch",
+and the watchers of queries matching it. The NotifyTo setting lists
+recipients of every change, like email:ops@example.com,
+sms:+14155551212 or webhook:https://chat.example.com/hook. Older notify
+tags, like tag_notify_email_alice@example.com, and tag_twilio_<phone>
+for sms are recipients too.
+
+Email is sent with SmtpServer, SmtpUser, SmtpPassword and NotifyFrom,
+sms with TwilioAccountSid, TwilioAuthToken, TwilioPhoneNumberFrom and
+TwilioApiUrl. Webhooks receive a json object with the changes.
+
+Messages describe each change: created, closed or renamed, field
+transitions like Status: open -> closed, added and removed tags and new
+comments. Email also has the changed lines of the Description and sms
+are shortened to 160 characters, leaving out details and then issues.
+
+--channel only sends to recipients of one channel and --dry-run prints
+the notifications instead of sending them.
+`)
+	case "watch", "unwatch":
+		fmt.Printf("usage: " + os.Args[0] + " watch <IssueID> [--to <channel:address>]\n")
+		fmt.Printf("       " + os.Args[0] + " watch --query <query> [--to <channel:address>]\n")
+		fmt.Printf("       " + os.Args[0] + " watch [--list <IssueID>]\n")
+		fmt.Printf("       " + os.Args[0] + " unwatch <IssueID> [--to <channel:address>]\n")
+		fmt.Printf("       " + os.Args[0] + " unwatch --query <query> [--to <channel:address>]\n\n")
+		fmt.Printf(
+			`This will add a recipient to the watchers of an issue, who "fit
+notify" tells about the changes of the issue. The recipient is like
+email:alice@example.com, sms:+14155551212 or
+webhook:https://example.com/hook, by default the email address of the
+user from git or hg. Watchers are listed in the Watchers file of the
+issue, so they are not shown as tags.
+
+With --query the recipient watches every issue matching a query, like
+"fit find" takes, for example --query "tag:security". Watches of
+queries are listed in the .fit_watchers file of the fit directory.
+
+Without arguments the watchers of every issue and query are listed,
+with --list only the watchers of an issue. unwatch removes a watcher.
+`)
+	case "webhooks":
+		fmt.Printf("usage: " + os.Args[0] + " webhooks [list]\n")
+		fmt.Printf("       " + os.Args[0] + " webhooks send [<revision>]\n")
+		fmt.Printf("       " + os.Args[0] + " webhooks retry [--all]\n\n")
+		fmt.Printf(
+			`After "fit commit" a json event of every issue changed by the commit
+is posted to each url of the WebhookUrls setting. An event has an Id,
+an Event like issue.created, issue.closed, issue.renamed or
+issue.changed, the Revision of the commit, the FitSite setting, the Time
+and the Change like "fit diff --format json" prints it. The headers
+X-Fit-Event and X-Fit-Delivery repeat the Event and Id and with the
+WebhookSecret setting X-Fit-Signature-256 is sha256= and the hex
+HMAC-SHA256 of the body with the secret.
+
+send posts the events of a commit, HEAD by default, for commits made
+without "fit commit", for example from .git/hooks/post-commit:
+
+    #!/bin/sh
+    fit webhooks send
+
+Events that could not be delivered are kept in fit-webhooks.json in the
+.git or .hg directory and retried after a minute, doubling the wait
+after every attempt up to a day. Retries happen with the next events
+and with retry, which with --all ignores the wait. list prints the
+events waiting. A fit-webhooks.json that can not be read is moved to
+fit-webhooks.json.corrupt.
 `)
 	case "twilio":
 		fmt.Printf("usage: " + os.Args[0] + " twilio\n\n")
 		fmt.Printf(
-			`This will send via twilio notifications of modified issues.
+			`This will send via twilio notifications of modified issues, like
+"fit notify --channel sms".
 `)
 	case "commit", "save":
 		fmt.Printf("usage: " + os.Args[0] + " commit [--no-autoclose]\n\n")
 		fmt.Printf(`This will commit any new, modified, or removed issues to
-git or hg.
+git or hg. The commit message lists the closed, created and updated
+issues, like Close issue "Old-bug"; Create issue "New-bug".
 
 Your working tree and staging area should be otherwise
 unaffected by using this command.
@@ -209,8 +407,9 @@ will autoclose them when the changes are pushed upstream.
 alias for commit: save
 `)
 	case "env":
-		fmt.Printf("usage: " + os.Args[0] + " env\n\n")
+		fmt.Printf("usage: " + os.Args[0] + " env [--format <json|ndjson|yaml|csv|text>]\n\n")
 		fmt.Printf(`This will print the environment variables used by the command to stdout.
+The --format option prints them as one machine readable record.
 
 Use this command if you want to see settings what directory "fit create" is
 using to store issues, or what editor will be invoked for a create/edit.
@@ -234,12 +433,23 @@ Tags can be any string which would make a valid file name.
 
 If the --rm option is provided before the IssueID, all tags provided will
 be removed instead of added.
+`)
+	case "tagslist", "taglist", "tagsassigned", "tags":
+		fmt.Printf("usage: " + os.Args[0] + " tagslist [-c|--count] [--format <json|ndjson|yaml|csv|text>]\n\n")
+		fmt.Printf(
+			`This will print the tags assigned to issues in the current tree.
+
+The [-c|--count] option adds the number of issues with each tag.
+Other formats always include the count.
+
+aliases for tagslist: taglist tagsassigned tags
 `)
 	case "roadmap":
 		fmt.Printf("usage: " + os.Args[0] + " roadmap [options]\n\n")
 		fmt.Printf(
 			`This will print a markdown formatted list of all open
-issues, grouped by milestone.
+issues, grouped by milestone. Issues of a milestone are listed after
+the issues blocking them, see "fit help link".
 
 Valid options are:
     --simple      Don't show anything other than the title in the output
@@ -251,7 +461,100 @@ Valid options are:
     --filter tag           Only show issues matching tag
     --filter tag1,tag2,etc Only show issues matching at least one of
                            the supplied tags
+    --filter <query>       Only show issues matching a query, see
+                           "fit help find"
+
+    --format json|ndjson|yaml|csv|text
+                  Print the issues in roadmap order as records
+                  instead of markdown
+
+    --rev <revision> Show the roadmap of a git or hg revision,
+                     like a release tag
+    --at <date>      Show the roadmap of the last commit before
+                     a date, YYYY-MM-DD[ HH:MM[:SS]]
+
+`)
+	case "link":
+		fmt.Printf("usage: " + os.Args[0] + " link <IssueID> <relation> <IssueID>\n")
+		fmt.Printf("       " + os.Args[0] + " link --rm <IssueID> <relation> <IssueID>\n")
+		fmt.Printf("       " + os.Args[0] + " link <IssueID>\n\n")
+		fmt.Printf(
+			`This will link the first issue to the second with one of the
+relations blocks, depends-on, relates-to or duplicates. A link is an
+empty file named link_<relation>_<target> in the directory of the
+first issue, the target is the Identifier of the second issue or its
+directory name. "A depends-on B" is the same as "B blocks A".
+
+With --rm the link is removed. With only an IssueID the links of the
+issue are listed along with the open issues blocking it.
+
+list shows the open issues blocking an issue, roadmap lists issues
+after the issues blocking them and graph prints all links.
+`)
+	case "graph":
+		fmt.Printf("usage: " + os.Args[0] + " graph [--format dot|mermaid] [--check]\n\n")
+		fmt.Printf(
+			`This will print the links between issues as a graph in the DOT
+language of graphviz, by default, or as a mermaid flowchart. Blocking
+edges go from the blocking issue to the blocked issue. Closed issues
+and links to missing issues are dashed.
+
+With --check the problems of the links are printed instead: issues
+blocking each other in a cycle, links to issues that are missing
+because they were closed by removing them or renamed, and links to
+closed issues.
+`)
+	case "changelog", "releasenotes":
+		fmt.Printf("usage: " + os.Args[0] + " changelog [options]\n\n")
+		fmt.Printf(
+			`This will print release notes of the issues closed in the git or
+hg history, by removing them or by setting their status to closed, for
+example with CloseStatusTag. Issues are grouped by milestone, newest
+first like roadmap, and by their security, bug or feature tags. Tags
+like type:bug are used too. Issues closed and reopened are left out.
 
+Valid options are:
+    --milestone <milestone> Only show issues of a milestone
+    --since <revision>      Only show issues closed after a revision,
+                            like the tag of the last release
+    --until <revision>      Only show issues closed up to a revision
+                            instead of the working tree
+
+    --format markdown|keepachangelog|text|json|ndjson|yaml|csv
+                  Print markdown, the Keep a Changelog format, plain
+                  text or records, markdown by default
+`)
+	case "serve", "server":
+		fmt.Printf("usage: " + os.Args[0] + " serve [--addr host:port]\n\n")
+		fmt.Printf(
+			`This will answer http requests with the issues as json.
+The default address is localhost:8080. Responses are HAL documents
+(application/hal+json) with _links, so clients can navigate from the
+root document to milestones to issues.
+
+    GET    /                              links to the resources below
+    GET    /issues[?page=N&per_page=N]    list issues
+    POST   /issues                        create from {"Title", "Description",
+                                          "Status", "Priority", "Milestone",
+                                          "Identifier", "Tags"}
+    GET    /issues/<IssueID>              show an issue
+    DELETE /issues/<IssueID>              close an issue
+    POST   /issues/<IssueID>/close        close an issue
+    GET    /issues/<IssueID>/fields       show the fields
+    GET    /issues/<IssueID>/fields/<key> show a field
+    PUT    /issues/<IssueID>/fields/<key> set a field from {"Value"}
+    GET    /issues/<IssueID>/tags         show the tags
+    POST   /issues/<IssueID>/tags         add a tag from {"Value"}
+    DELETE /issues/<IssueID>/tags/<tag>   remove a tag
+    GET    /issues/<IssueID>/comments     list comments
+    POST   /issues/<IssueID>/comments     comment from {"Author", "Body"}
+    GET    /milestones                    list milestones
+    GET    /milestones/<milestone>        list issues of a milestone, use
+                                          - for issues without a milestone
+
+IssueIDs containing / or # must be escaped as %%2F and %%23.
+
+alias for serve: server
 `)
 	case "id", "identifier":
 		fmt.Printf("usage: " + os.Args[0] + " id <IssueID> [--generate-id] <value>\n\n")
@@ -312,7 +615,9 @@ invoked.
 aliases for version: about --version -v
 `, os.Args[0])
 	case "import":
-		fmt.Printf("usage: " + os.Args[0] + " import <--github|--be> <repo>\n\n")
+		fmt.Printf("usage: " + os.Args[0] + " import <--github|--be> <repo>\n")
+		fmt.Printf("       " + os.Args[0] + " import --jira <url|file>\n")
+		fmt.Printf("       " + os.Args[0] + " import <--json|--yaml> [<file>]\n\n")
 		fmt.Printf(
 			`This will read from github <user>/<repository> issues 
 or a local BugsEverywhere bug database to the issues/ directory.
@@ -321,6 +626,92 @@ Either "--github <user>/repo>" is required to import GitHub issues
 or  "--github <user>/<repo>/projects/<num>" to import a GitHub project
 or "--be <path>" is required to import a local BugsEverywhere database.
 GitHub projects require a configured GithubPersonalAccessToken value.
+
+Importing GitHub issues again updates the issues found by their
+GitHub:<user>/<repo>#<num> identifier instead of creating duplicates.
+Changed titles rename the issue directory, changed descriptions and
+milestones are replaced and new labels and comments are added. A
+summary of created, updated and unchanged issues is printed. Set
+GithubApiUrl to import from a GitHub Enterprise api.
+
+"--jira <url|file>" imports Jira issues from a REST api url like
+https://<site>/rest/api/2/search?jql=project=KEY or from a saved json
+or xml export file. The summary and key name the issue, description,
+status, priority, fixVersion, labels and comments are imported and the
+identifier is set to Jira:<key>. Configure JiraUser and JiraApiToken
+for projects that need authentication.
+
+The --json and --yaml options rebuild issues written by "fit export"
+from <file> or stdin. Existing issues are never overwritten. Into an
+existing fit directory the highest next identifier and the query
+watches of both are kept, other files already present are not replaced.
+`)
+	case "comment":
+		fmt.Printf("usage: " + os.Args[0] + " comment <IssueID> [-m <text>]\n")
+		fmt.Printf("       " + os.Args[0] + " comment --list <IssueID>\n")
+		fmt.Printf("       " + os.Args[0] + " comment --edit <IssueID> <CommentNumber>\n")
+		fmt.Printf("       " + os.Args[0] + " comment --rm <IssueID> <CommentNumber>\n\n")
+		fmt.Printf(
+			`This will add a comment to the issue identified by IssueID. The
+text after -m is the comment, otherwise your editor is opened.
+
+Each comment is a file comment-<order>-<first words> in the issue
+directory. It starts with an Author line, from the git user.name and
+user.email or the hg ui.username, a Date line and an empty line
+before the text of the comment.
+
+The --list option prints the comments in chronological order with
+their numbers. --edit opens comment <CommentNumber> in your editor
+and --rm removes it. Numbers can change when comments are removed.
+
+"fit list <IssueID>" shows the comments after the Description.
+`)
+	case "sync":
+		fmt.Printf("usage: " + os.Args[0] + " sync --github [<user>/<repo>] [--force]\n\n")
+		fmt.Printf(
+			`This will push local changes of issues imported with
+"fit import --github" back to GitHub, for every issue with a
+GitHub:<user>/<repo>#<num> identifier or only those of <user>/<repo>.
+
+Title changes made with retitle, Description edits, added and removed
+tags, milestone changes and new comment files are sent. The comments
+file of ImportCommentsTogether is not sent. Issues with the status
+closed, see CloseStatusTag, are closed on GitHub, and so are issues
+whose directory was removed by "fit close". Those are found in the git
+or hg history since the commit of the last sync of every repository,
+kept in GithubSync in the .git or .hg directory.
+
+Import and sync store the GitHub updated_at time of an issue in its
+GithubSync file. An issue changed on GitHub after that time is a
+conflict and is skipped. Import it again to merge the remote changes
+or use --force to overwrite them.
+
+Pushing requires a configured GithubPersonalAccessToken value.
+`)
+	case "export":
+		fmt.Printf("usage: " + os.Args[0] + " export [--json|--yaml] [--filter <query>] [<file>]\n")
+		fmt.Printf("       " + os.Args[0] + " export --be [--filter <query>] [<dir>]\n\n")
+		fmt.Printf(
+			`This will write every issue of the issues/ directory as json,
+or yaml with --yaml, to <file> or stdout.
+
+Every file of an issue is included: the Description, fields, tags,
+comments and any other files, with their modes and modification
+times. Files that are not utf-8 text are base64 encoded.
+
+Use "fit import --json <file>" to rebuild the issues, for example
+in another repository or from a backup.
+
+The --filter option only writes the issues matching a query, see
+"fit help find" for the query language.
+
+The --be option writes a BugsEverywhere repository to <dir>/.be,
+the directory containing the issues/ directory by default. Status
+and Priority become the BE status and severity, statuses and
+priorities BE does not know are mapped to open and minor. The
+Description and comments become BE comments with their author and
+date. Exporting again replaces the issues written before and keeps
+their BE uuids.
 `)
 		//ids aliases: idlist idsassigned identifiers
 		//noids alias: noidentifiers
@@ -339,7 +730,9 @@ Use "fit help <command>" or "fit <command> help" for
 		fmt.Printf(`
 Commands for status/reading:
     list       List issues
-    find       Search for tag of fields: id, status, priority, or milestone
+    find       Search by tag, status, priority, milestone or a query
+    search     Search the text of issues, best match first
+    reindex    Rebuild the cache used to look up issues
     tagslist   List assigned tags
     notags     List issues without tags
     ids        List stable identifiers
@@ -355,18 +748,32 @@ Commands for editing:
     retitle    Rename an issue
     close      Delete an issue
     tag        Tag an issue
+    comment    Add, list, edit or remove comments
+    link       Link issues that block, depend on or relate to others
     id         View or set a stable identifier
     status     View or set status
     priority   View or set priority
     milestone  View or set milestone
-    import     Download from github or bugseverywhere repository
+    due        View or set due, reported, started or closed dates
+    import     Download from github, jira or bugseverywhere or read an export
+    sync       Push changes of imported issues back to github
 
 Commands for version control:
     commit     Commit any new, changed or deleted issues
+    log        Show the history of an issue
+    diff       Show issues changed between revisions
+    notify     Notify recipients of changed issues by email, sms or webhook
+    watch      Notify a recipient of changes to an issue or a query
+    unwatch    Stop notifying a recipient of changes
+    webhooks   Post signed json events of committed changes, retry failed ones
     purge      Remove all issues not tracked
 
 Commands for processing:
     roadmap    Print list of open issues sorted by milestone
+    changelog  Print release notes of closed issues
+    graph      Print links between issues as a DOT or mermaid graph
+    serve      Answer http requests with issues as json
+    export     Write every issue as json, yaml or bugseverywhere
 
 aliases for help: --help -h
 
@@ -10,10 +10,12 @@ import (
 //var dops = bugs.Directory(os.PathSeparator)
 //var sops = string(os.PathSeparator)
 
-// Import is a subcommand to read from a bugsEverywhere.org or github.com systems and create identical issues.
+// Import is a subcommand to read from a bugsEverywhere.org, github.com or Jira
+// systems or an export and create identical issues.
 func Import(args argumentList, config bugs.Config) {
 	if len(args) < 1 {
-		fmt.Printf("Usage: %s import {--github,--be} <repo>\n", os.Args[0])
+		fmt.Printf("Usage: %s import {--github,--be,--jira} <repo>\n", os.Args[0])
+		fmt.Printf("       %s import {--json,--yaml} [<file>]\n", os.Args[0])
 		//fmt.Printf("Usage: %s import {<github.com/user/repo>,--be}\n", os.Args[0])
 		return
 	}
@@ -42,17 +44,31 @@ func Import(args argumentList, config bugs.Config) {
 			fmt.Fprintf(os.Stderr, "BugsEverywhere repo ignored: %s\n", args[1:])
 		}
 		beImport(config)
+	case "--jira":
+		if source := args.GetArgument("--jira", ""); source != "" && source != "true" {
+			jiraImportIssues(source, config)
+		} else {
+			fmt.Fprintf(os.Stderr, "Jira url or export file missing\n")
+		}
+	case "--json":
+		importTree("json", args.GetArgument("--json", ""), config)
+	case "--yaml":
+		importTree("yaml", args.GetArgument("--yaml", ""), config)
 	default:
 		fmt.Fprintf(os.Stderr, "usage: %s import --github user/repo\n", os.Args[0])
 		//fmt.Fprintf(os.Stderr, "usage: %s import github.com/user/repo\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "       %s import --be\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s import --jira <url|file>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s import {--json,--yaml} [<file>]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, `
 Use this command to import an external bug database into the local
 issues/ directory.
 
 Either "--github <user>/repo>" is required to import issues
 or  "--github <user>/<repo>/projects" to import projects
-or "--be" found relative to the current path to import a local BugsEverywhere database.
+or "--be" found relative to the current path to import a local BugsEverywhere database
+or "--jira <url|file>" to import a Jira REST api search or a saved json or xml export
+or "--json" and "--yaml" to rebuild issues from "fit export", read from stdin without <file>.
 GitHub projects require a configured GithubPersonalAccessToken value.
 `)
 	}
@@ -0,0 +1,99 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"os"
+	"strings"
+)
+
+// linkUsage prints how to use the link subcommand.
+func linkUsage() {
+	fmt.Printf("Usage: %s link <IssueID> <relation> <IssueID>\n", os.Args[0])
+	fmt.Printf("       %s link --rm <IssueID> <relation> <IssueID>\n", os.Args[0])
+	fmt.Printf("       %s link <IssueID>\n", os.Args[0])
+	fmt.Printf("\nRelations: %s\n", strings.Join(bugs.LinkRelations, ", "))
+}
+
+// linkName names an issue of a link graph like list does.
+func linkName(g *bugs.LinkGraph, r bugs.IssueRecord) string {
+	if r.Identifier != "" {
+		return "Issue " + r.Identifier
+	}
+	return fmt.Sprintf("Issue %d", g.Number(r.Name))
+}
+
+// blockedBy returns the names of the open issues blocking the issue of
+// the directory name, or "" when there are none.
+func blockedBy(g *bugs.LinkGraph, name string) string {
+	var names []string
+	for _, r := range g.OpenBlockers(name) {
+		names = append(names, linkName(g, r))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Link is a subcommand to add, remove and list links between issues.
+func Link(args argumentList, config bugs.Config) {
+	remove := len(args) > 0 && args[0] == "--rm"
+	if remove {
+		args = args[1:]
+	}
+	if len(args) != 1 && len(args) != 3 || remove && len(args) != 3 {
+		linkUsage()
+		return
+	}
+	b, err := bugs.LoadIssueByHeuristic(args[0], config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+		return
+	}
+	name := string(b.Dir.ShortNamer())
+
+	if len(args) == 1 {
+		g := bugs.NewLinkGraph(config)
+		for _, l := range b.Links() {
+			if target, ok := g.Resolve(l.Target); ok {
+				fmt.Printf("%s %s: %s\n", l.Relation, linkName(g, target), target.Title)
+			} else {
+				fmt.Printf("%s %s (missing)\n", l.Relation, l.Target)
+			}
+		}
+		if blockers := blockedBy(g, name); blockers != "" {
+			fmt.Printf("Blocked by: %s\n", blockers)
+		}
+		return
+	}
+
+	relation := strings.ToLower(args[1])
+	target := args[2]
+	other, err := bugs.LoadIssueByHeuristic(target, config)
+	if err == nil {
+		target = bugs.LinkTarget(*other)
+	} else if !remove {
+		fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+		return
+	}
+	l := bugs.Link{Relation: relation, Target: target}
+	if remove {
+		if err := b.UnlinkIssue(l, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not remove link: %s\n", err.Error())
+			return
+		}
+		fmt.Printf("Removed link %s %s from %s\n", relation, target, b.Title(""))
+		return
+	}
+	if err := b.LinkIssue(l, config); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not link issue: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("%s %s %s\n", b.Title(""), relation, other.Title(""))
+	for _, cycle := range bugs.NewLinkGraph(config).Cycles() {
+		for _, issue := range cycle {
+			if issue == name {
+				fmt.Fprintf(os.Stderr, "Warning: issues block each other: %s\n", strings.Join(cycle, ", "))
+				break
+			}
+		}
+	}
+}
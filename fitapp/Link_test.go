@@ -0,0 +1,110 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setupLinkedIssues creates issues with identifiers linked to each other
+// and returns the config and a function removing them.
+func setupLinkedIssues(t *testing.T) (bugs.Config, func()) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "linktest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	os.Setenv("FIT", dir)
+	captureOutput(func() {
+		Create(argumentList{"-n", "Database", "schema", "--identifier", "DB", "--milestone", "v1"}, config)
+		Create(argumentList{"-n", "Api", "--identifier", "API", "--milestone", "v1"}, config)
+		Create(argumentList{"-n", "Ui", "--identifier", "UI", "--milestone", "v1"}, config)
+		Create(argumentList{"-n", "Docs", "--identifier", "DOC", "--milestone", "v2"}, config)
+		Link(argumentList{"API", "depends-on", "DB"}, config)
+		Link(argumentList{"UI", "depends-on", "API"}, config)
+		Link(argumentList{"DOC", "relates-to", "UI"}, config)
+	}, t)
+	return config, func() {
+		os.Chdir(pwd)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLink(t *testing.T) {
+	config, done := setupLinkedIssues(t)
+	defer done()
+
+	stdout, stderr := captureOutput(func() {
+		Link(argumentList{"UI", "blocks", "DOC"}, config)
+	}, t)
+	if stdout != "Ui blocks Docs\n" || stderr != "" {
+		t.Errorf("Unexpected output linking issues: %s %s", stdout, stderr)
+	}
+	if _, err := os.Stat(os.Getenv("FIT") + "/fit/Ui/link_blocks_DOC"); err != nil {
+		t.Errorf("Expected a link file: %s", err.Error())
+	}
+	stdout, _ = captureOutput(func() {
+		Link(argumentList{"UI"}, config)
+	}, t)
+	if stdout != "blocks Issue DOC: Docs\ndepends-on Issue API: Api\nBlocked by: Issue API\n" {
+		t.Errorf("Unexpected links of an issue: %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		List(argumentList{}, config, true)
+	}, t)
+	for _, line := range []string{"Issue API: Api (blocked by Issue DB)\n", "Issue UI: Ui (blocked by Issue API)\n", "Issue DB: Database schema\n"} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("Expected %q in list: %s", line, stdout)
+		}
+	}
+	stdout, _ = captureOutput(func() {
+		List(argumentList{"UI"}, config, true)
+	}, t)
+	if !strings.HasSuffix(stdout, "Blocked by: Issue API\n") {
+		t.Errorf("Unexpected issue: %s", stdout)
+	}
+
+	// closed blockers do not block
+	captureOutput(func() {
+		Status(argumentList{"DB", "closed"}, config)
+	}, t)
+	stdout, _ = captureOutput(func() {
+		List(argumentList{}, config, true)
+	}, t)
+	if !strings.Contains(stdout, "Issue API: Api\n") {
+		t.Errorf("Unexpected list with a closed blocker: %s", stdout)
+	}
+
+	_, stderr = captureOutput(func() {
+		Link(argumentList{"DOC", "blocks", "UI"}, config)
+	}, t)
+	if stderr != "Warning: issues block each other: Ui, Docs\n" && stderr != "Warning: issues block each other: Docs, Ui\n" {
+		t.Errorf("Expected a warning about a cycle: %s", stderr)
+	}
+	stdout, stderr = captureOutput(func() {
+		Link(argumentList{"--rm", "DOC", "blocks", "UI"}, config)
+	}, t)
+	if stdout != "Removed link blocks UI from Docs\n" || stderr != "" {
+		t.Errorf("Unexpected output removing a link: %s %s", stdout, stderr)
+	}
+	_, stderr = captureOutput(func() {
+		Link(argumentList{"--rm", "DOC", "blocks", "UI"}, config)
+	}, t)
+	if stderr != "Could not remove link: No link blocks UI\n" {
+		t.Errorf("Unexpected error removing a missing link: %s", stderr)
+	}
+	_, stderr = captureOutput(func() {
+		Link(argumentList{"DOC", "fixes", "UI"}, config)
+	}, t)
+	if !strings.HasPrefix(stderr, "Could not link issue: Invalid relation fixes") {
+		t.Errorf("Unexpected error of an invalid relation: %s", stderr)
+	}
+}
@@ -36,8 +36,114 @@ func listTags(files []os.FileInfo, args argumentList, config bugs.Config) {
 	}
 }
 
+// listColumns are the csv columns of listRecords.
+var listColumns = []string{"ID", "Identifier", "Title", "Status", "Priority", "Milestone", "Tags", "Description"}
+
 // List is a subcommand to print lists and individual issues.
 func List(args argumentList, config bugs.Config, topRecurse bool) {
+	args, restore, ok := revisionArguments(args, config)
+	if !ok {
+		return
+	}
+	defer restore()
+	args, format := formatArgument(args)
+	if args.HasArgument("--filter") {
+		args, values := args.GetAndRemoveArguments([]string{"--filter"})
+		q, ok := parseQuery(values[0])
+		if !ok {
+			return
+		}
+		options := ""
+		if args.HasArgument("--tags") || args.HasArgument("-t") {
+			options = "tags"
+		}
+		findMatching(queryMatcher(q, config), options, format, config)
+		return
+	}
+	writeReport(format, outputReport{
+		Columns: listColumns,
+		Records: func() []outputRecord { return listRecords(args, config) },
+		Text:    func() { listText(args, config, topRecurse) },
+	})
+}
+
+// listRecords returns the issues list selects. Issues selected
+// by IssueID include their Description.
+func listRecords(args argumentList, config bugs.Config) []outputRecord {
+	fitdir := bugs.FitDirer(config)
+	issues := readIssues(string(fitdir))
+	sort.Sort(byDir(issues))
+	records := []outputRecord{}
+	add := func(idx int, b bugs.Issue) outputRecord {
+		record := issueRecord(b)
+		record["ID"] = idx + 1
+		records = append(records, record)
+		return record
+	}
+	load := func(idx int) bugs.Issue {
+		b := bugs.Issue{}
+		b.LoadIssue(fitdir+dops+bugs.Directory(issues[idx].Name()), config)
+		return b
+	}
+
+	var params []string
+	for _, arg := range args {
+		switch arg {
+		case "--match", "-m", "--tags", "-t", "--recursive", "-r":
+		default:
+			params = append(params, arg)
+		}
+	}
+	switch {
+	case len(params) == 0:
+		for idx := range issues {
+			add(idx, load(idx))
+		}
+	case args.HasArgument("--match") || args.HasArgument("-m"):
+		for _, param := range params {
+			re, err := regexp.Compile("(?i)" + param)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid regex %s: %s\n", param, err.Error())
+				continue
+			}
+			for idx, issue := range issues {
+				if re.MatchString(issue.Name()) {
+					add(idx, load(idx))
+				}
+			}
+		}
+	default:
+		tags := uniqueTagList(config)
+		for _, param := range params {
+			b, err := bugs.LoadIssueByHeuristic(param, config)
+			if err == nil {
+				for idx, issue := range issues {
+					if issue.Name() == string(b.Dir.ShortNamer()) {
+						add(idx, *b)["Description"] = b.Description()
+					}
+				}
+				continue
+			}
+			isTag := false
+			for _, tagname := range tags {
+				isTag = isTag || tagname == param
+			}
+			if !isTag {
+				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+				continue
+			}
+			for idx := range issues {
+				if b := load(idx); b.HasTag(bugs.TagBoolTrue(param)) {
+					add(idx, b)
+				}
+			}
+		}
+	}
+	return records
+}
+
+// listText prints lists and individual issues as text.
+func listText(args argumentList, config bugs.Config, topRecurse bool) {
 	fitdir := bugs.FitDirer(config)
 	issues := readIssues(string(fitdir))
 	sort.Sort(byDir(issues))
@@ -70,6 +176,7 @@ func List(args argumentList, config bugs.Config, topRecurse bool) {
 		}
 	}
 
+	links := bugs.NewLinkGraph(config)
 	fmt.Printf("\n===== list %s\n", config.FitDir+sops+config.FitDirName)
 	if matchRegex && (len(args) > 1) {
 		for i, length := 0, len(args); i < length; i += 1 {
@@ -88,7 +195,7 @@ func List(args argumentList, config bugs.Config, topRecurse bool) {
 				if err == nil {
 					s := re.Find([]byte(issue.Name()))
 					if s != nil {
-						printIssueByDir(idx, issue, fitdir, config, wantTags)
+						printIssueByDir(idx, issue, fitdir, config, wantTags, links)
 					} // else { continue }
 				} // else { continue }
 			}
@@ -103,7 +210,7 @@ func List(args argumentList, config bugs.Config, topRecurse bool) {
 			if issue.IsDir() != true {
 				continue
 			}
-			printIssueByDir(idx, issue, fitdir, config, wantTags)
+			printIssueByDir(idx, issue, fitdir, config, wantTags, links)
 		}
 		if topRecurse == true && (wantRecursive || config.MultipleFitDirs == true) {
 			fi, _ := os.Stat(config.FitDir)
@@ -144,6 +251,9 @@ func List(args argumentList, config bugs.Config, topRecurse bool) {
 
 			// err == nil so issue loaded
 			b.ViewIssue()
+			if blockers := blockedBy(links, string(b.Dir.ShortNamer())); blockers != "" {
+				fmt.Printf("Blocked by: %s\n", blockers)
+			}
 			if i < length-1 {
 				fmt.Printf("\n--\n\n")
 			}
@@ -197,14 +307,20 @@ func checkDirTreeDown(args argumentList, config bugs.Config, node os.FileInfo, a
 	}
 }
 
-func printIssueByDir(idx int, issue os.FileInfo, fitdir bugs.Directory, config bugs.Config, wantTags bool) {
+// printIssueByDir prints an issue of a list with the open issues
+// blocking it.
+func printIssueByDir(idx int, issue os.FileInfo, fitdir bugs.Directory, config bugs.Config, wantTags bool, links *bugs.LinkGraph) {
 	// TODO: same next eight lines func (idx, issue)
 	var dir bugs.Directory = fitdir + dops + bugs.Directory(issue.Name())
 	b := bugs.Issue{Dir: dir, DescriptionFileName: config.DescriptionFileName} // usually Description
 	name := issueNamer(b, idx)                                                 // Issue idx: b.Title
+	blocked := ""
+	if blockers := blockedBy(links, issue.Name()); blockers != "" {
+		blocked = " (blocked by " + blockers + ")"
+	}
 	if wantTags == false {
-		fmt.Printf("%s: %s\n", name, b.Title(""))
+		fmt.Printf("%s: %s%s\n", name, b.Title(""), blocked)
 	} else {
-		fmt.Printf("%s: %s\n", name, b.Title("tags"))
+		fmt.Printf("%s: %s%s\n", name, b.Title("tags"), blocked)
 	}
 }
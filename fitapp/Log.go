@@ -0,0 +1,202 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"os"
+	"strings"
+	"time"
+)
+
+// logFieldNames are the files holding fields, see bugs.Issue.Tags.
+var logFieldNames = map[string]string{
+	"status":     "Status",
+	"priority":   "Priority",
+	"milestone":  "Milestone",
+	"identifier": "Identifier",
+	"id":         "Identifier",
+}
+
+// logFirstLine returns the first line of the contents of a field file.
+func logFirstLine(s string) string {
+	return strings.TrimSpace(strings.SplitN(s, "\n", 2)[0])
+}
+
+// logTag returns the tag of a tags/<tag> or tag_<key>[_<value>] file.
+func logTag(name string) (string, bool) {
+	if strings.HasPrefix(name, "tags/") {
+		return strings.TrimPrefix(name, "tags/"), true
+	}
+	if strings.HasPrefix(name, "tag_") {
+		parts := strings.SplitN(strings.TrimPrefix(name, "tag_"), "_", 2)
+		if len(parts) == 2 {
+			return strings.ToLower(parts[0]) + ":" + strings.ToLower(parts[1]), true
+		}
+		return strings.ToLower(parts[0]), true
+	}
+	return "", false
+}
+
+// logField returns the field of a field file, or of a tag_<field> file
+// holding the value with NewFieldAsTag.
+func logField(name string) (string, bool) {
+	field, ok := logFieldNames[strings.TrimPrefix(strings.ToLower(name), "tag_")]
+	return field, ok
+}
+
+// logFieldTags returns the values removed and added by the
+// tag_<field>_<value> files of the fields changed by a commit, with
+// NewFieldAsTag a transition removes one and adds another.
+func logFieldTags(files []scm.LogFile) map[string]*[2]string {
+	values := map[string]*[2]string{}
+	for _, f := range files {
+		tag, ok := logTag(f.Name)
+		kv := strings.SplitN(tag, ":", 2)
+		if !ok || len(kv) != 2 || !strings.HasPrefix(f.Name, "tag_") {
+			continue
+		}
+		field, ok := logFieldNames[kv[0]]
+		if !ok || f.Status != "A" && f.Status != "D" {
+			continue
+		}
+		if values[field] == nil {
+			values[field] = &[2]string{}
+		}
+		if f.Status == "D" {
+			values[field][0] = kv[1]
+		} else {
+			values[field][1] = kv[1]
+		}
+	}
+	return values
+}
+
+// logTransition describes the change of a field from before to after.
+func logTransition(field, before, after string) (string, bool) {
+	switch {
+	case before == after:
+		return "", false
+	case before == "":
+		return fmt.Sprintf("%s set to %s", field, after), true
+	case after == "":
+		return fmt.Sprintf("%s removed (was %s)", field, before), true
+	}
+	return fmt.Sprintf("%s: %s -> %s", field, before, after), true
+}
+
+// logChanges describes the files changed by a commit: field transitions,
+// added and removed tags and comments and the diff of the Description.
+func logChanges(entry scm.LogEntry, config bugs.Config) []string {
+	var lines []string
+	if entry.RenamedFrom != "" {
+		lines = append(lines, "Renamed from "+entry.RenamedFrom)
+	}
+	fieldTags := logFieldTags(entry.Files)
+	for _, f := range entry.Files {
+		if f.Status == "R" && f.Before == f.After {
+			continue
+		}
+		before, after := logFirstLine(f.Before), logFirstLine(f.After)
+		if f.Status == "A" {
+			before = ""
+		} else if f.Status == "D" {
+			after = ""
+		}
+		if field, ok := logField(f.Name); ok {
+			if line, ok := logTransition(field, before, after); ok {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		if tag, ok := logTag(f.Name); ok {
+			kv := strings.SplitN(tag, ":", 2)
+			if field, ok := logFieldNames[kv[0]]; ok && len(kv) == 2 && strings.HasPrefix(f.Name, "tag_") {
+				// one line for the removed and the added value
+				if values := fieldTags[field]; values != nil {
+					if line, ok := logTransition(field, values[0], values[1]); ok {
+						lines = append(lines, line)
+					}
+					delete(fieldTags, field)
+				}
+				continue
+			}
+			if f.Status == "D" {
+				lines = append(lines, "Tag removed: "+tag)
+			} else if f.Status == "A" {
+				lines = append(lines, "Tag added: "+tag)
+			}
+			continue
+		}
+		if (f.Name == "comments" || strings.HasPrefix(f.Name, "comment-")) && !strings.HasSuffix(f.Name, ".xml") {
+			switch f.Status {
+			case "A":
+				lines = append(lines, "Comment added: "+f.Name)
+			case "D":
+				lines = append(lines, "Comment removed: "+f.Name)
+			default:
+				lines = append(lines, "Comment edited: "+f.Name)
+			}
+			continue
+		}
+		if f.Name == config.DescriptionFileName {
+			lines = append(lines, "Description:")
+			// the hunks without the file headers of the diff
+			hunks := false
+			for _, line := range strings.Split(strings.TrimRight(f.Diff, "\n"), "\n") {
+				hunks = hunks || strings.HasPrefix(line, "@@")
+				if hunks {
+					lines = append(lines, line)
+				}
+			}
+			continue
+		}
+		switch f.Status {
+		case "A":
+			lines = append(lines, "Added "+f.Name)
+		case "D":
+			lines = append(lines, "Removed "+f.Name)
+		default:
+			lines = append(lines, "Changed "+f.Name)
+		}
+	}
+	return lines
+}
+
+// Log is a subcommand to print the history of an issue from the git or
+// hg log: every commit that changed the issue with the changes to its
+// fields, tags, comments and Description.
+func Log(args argumentList, config bugs.Config) {
+	if len(args) != 1 {
+		fmt.Printf("Usage: %s log <IssueID>\n", os.Args[0])
+		return
+	}
+	b, err := bugs.LoadIssueByHeuristic(args[0], config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+		return
+	}
+	handler, _, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	entries, err := handler.Log(b.Direr(), config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading the %s log: %s\n", handler.SCMTyper(), err.Error())
+		return
+	}
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Printf("\n")
+		}
+		fmt.Printf("commit %s\nAuthor: %s\nDate:   %s\n\n    %s\n", entry.Commit, entry.Author,
+			entry.Date.Format(time.RFC3339), entry.Message)
+		if changes := logChanges(entry, config); len(changes) > 0 {
+			fmt.Printf("\n")
+			for _, line := range changes {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("WARN git executable not found")
+	}
+	dir, err := ioutil.TempDir("", "logtest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	commit := func(msg string) {
+		exec.Command("git", "add", "-A").Run()
+		cmd := exec.Command("git", "-c", "user.name=Tester", "-c", "user.email=tester@example.com", "commit", "-q", "-m", msg)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	}
+
+	captureOutput(func() {
+		Create(argumentList{"-n", "Test", "bug"}, config)
+	}, t)
+	commit("Create Test bug")
+	b := bugs.Issue{Dir: bugs.Directory(dir + sops + "fit" + sops + "Test-bug"), DescriptionFileName: "Description"}
+	b.SetDescription("hello", config)
+	b.SetStatus("open", config)
+	b.TagIssue(bugs.TagBoolTrue("cli"), config)
+	commit("Update Test bug")
+	os.Rename(dir+sops+"fit"+sops+"Test-bug", dir+sops+"fit"+sops+"Renamed-bug")
+	b = bugs.Issue{Dir: bugs.Directory(dir + sops + "fit" + sops + "Renamed-bug")}
+	b.SetStatus("closed", config)
+	commit("Rename Test bug")
+
+	stdout, stderr := captureOutput(func() {
+		Log(argumentList{"1"}, config)
+	}, t)
+	if stderr != "" {
+		t.Errorf("Unexpected error: %s", stderr)
+	}
+	for _, expected := range []string{
+		"Author: Tester <tester@example.com>\n",
+		"    Rename Test bug\n\n    Renamed from Test-bug\n    Status: open -> closed\n",
+		"    Update Test bug\n\n    Description:\n    @@ ",
+		"    +hello\n",
+		"    Status set to open\n",
+		"    Tag added: cli\n",
+		"    Create Test bug\n",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected %q in log output, got %s", expected, stdout)
+		}
+	}
+	if strings.Index(stdout, "Rename Test bug") > strings.Index(stdout, "Create Test bug") {
+		t.Errorf("Expected newest commit first, got %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Log(argumentList{}, config)
+	}, t)
+	if !strings.Contains(stdout, "Usage: ") {
+		t.Errorf("Expected usage without an IssueID, got %s", stdout)
+	}
+}
+
+func TestLogChangesFieldTags(t *testing.T) {
+	entry := scm.LogEntry{Files: []scm.LogFile{
+		{Name: "tag_Status_open", Status: "D"},
+		{Name: "tag_Status_closed", Status: "A"},
+		{Name: "tag_priority_high", Status: "A"},
+		{Name: "tag_milestone_v1", Status: "D"},
+		{Name: "tags/status", Status: "A"},
+		{Name: "tag_cli", Status: "A"},
+	}}
+	lines := logChanges(entry, bugs.Config{})
+	expected := []string{
+		"Status: open -> closed",
+		"Priority set to high",
+		"Milestone removed (was v1)",
+		"Tag added: status",
+		"Tag added: cli",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected changes %q, expected %q", lines, expected)
+	}
+}
@@ -0,0 +1,197 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// smsLimit is the most characters of the body of an sms.
+const smsLimit = 160
+
+// Message is the subject and body of a notification.
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// MessageChange is a change of an issue as messages describe it.
+type MessageChange struct {
+	// Summary is like Closed: (ID) Title.
+	Summary string
+	// Details are the field transitions, tags and comments.
+	Details []string
+	// DescriptionDiff are the changed lines of the Description, with
+	// - before removed and + before added lines.
+	DescriptionDiff []string
+}
+
+// messageData is what the templates of messages format.
+type messageData struct {
+	Site    string
+	Changes []MessageChange
+	// More is the number of changes left out of an sms.
+	More int
+}
+
+// messageTemplates format the body of messages of each channel.
+var messageTemplates = map[string]*template.Template{
+	ChannelEmail: template.Must(template.New(ChannelEmail).Parse(
+		`{{if .Site}}Site: {{.Site}}
+
+{{end}}{{range .Changes}}{{.Summary}}
+{{range .Details}}    {{.}}
+{{end}}{{range .DescriptionDiff}}        {{.}}
+{{end}}
+{{end}}`)),
+	ChannelSMS: template.Must(template.New(ChannelSMS).Parse(
+		`{{range .Changes}}{{.Summary}}{{range .Details}}; {{.}}{{end}}
+{{end}}{{if .More}}and {{.More}} more
+{{end}}`)),
+	ChannelWebhook: template.Must(template.New(ChannelWebhook).Parse(
+		`{{range .Changes}}{{.Summary}}
+{{range .Details}}    {{.}}
+{{end}}{{end}}`)),
+}
+
+// descriptionDiff returns the lines removed from old and added in new,
+// with - and + before them and context lines before them with two
+// spaces, and ... between parts that are apart.
+func descriptionDiff(old, new string) []string {
+	split := func(s string) []string {
+		s = strings.TrimRight(s, "\n")
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, "\n")
+	}
+	a, b := split(old), split(new)
+	// longest common subsequence of lines from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var lines []string
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	// keep one line of context around changed lines
+	const context = 1
+	var excerpt []string
+	last := -1
+	for i, line := range lines {
+		near := false
+		for k := i - context; k <= i+context; k++ {
+			near = near || k >= 0 && k < len(lines) && !strings.HasPrefix(lines[k], "  ")
+		}
+		if !near {
+			continue
+		}
+		if last >= 0 && i > last+1 {
+			excerpt = append(excerpt, "...")
+		}
+		excerpt = append(excerpt, line)
+		last = i
+	}
+	return excerpt
+}
+
+// messageChange describes a change, leaving out the tags of recipients.
+func messageChange(c bugs.IssueChange, withDescription bool) MessageChange {
+	c.TagsAdded = withoutRecipientTags(c.TagsAdded)
+	c.TagsRemoved = withoutRecipientTags(c.TagsRemoved)
+	lines := diffLines(c)
+	m := MessageChange{Summary: lines[0]}
+	for _, line := range lines[1:] {
+		m.Details = append(m.Details, strings.TrimSpace(line))
+	}
+	if withDescription && c.New != nil && (c.Old == nil || c.DescriptionChanged) {
+		old := ""
+		if c.Old != nil {
+			old = c.Old.Description
+		}
+		m.DescriptionDiff = descriptionDiff(old, c.New.Description)
+	}
+	return m
+}
+
+// withoutRecipientTags returns the tags that do not name recipients.
+func withoutRecipientTags(tags []string) []string {
+	var plain []string
+	for _, tag := range tags {
+		if _, ok := tagRecipient(tag); !ok {
+			plain = append(plain, tag)
+		}
+	}
+	return plain
+}
+
+// messageSubject returns the subject of a message of changes.
+func messageSubject(changes []bugs.IssueChange) string {
+	if len(changes) == 1 {
+		return strings.Title(changes[0].Kind) + ": " + changes[0].Title
+	}
+	return fmt.Sprintf("%d issues changed", len(changes))
+}
+
+// BuildMessage returns the message of a channel about changes. Email
+// has the Description diff of every change, sms leaves out details and
+// then changes to fit in smsLimit characters.
+func BuildMessage(channel string, changes []bugs.IssueChange, config bugs.Config) Message {
+	t, ok := messageTemplates[channel]
+	if !ok {
+		t = messageTemplates[ChannelWebhook]
+	}
+	data := messageData{}
+	for _, c := range changes {
+		data.Changes = append(data.Changes, messageChange(c, channel == ChannelEmail))
+	}
+	if channel == ChannelEmail {
+		data.Site = config.FitSite
+	}
+	render := func(d messageData) string {
+		var body strings.Builder
+		t.Execute(&body, d)
+		return body.String()
+	}
+	body := render(data)
+	if channel == ChannelSMS && utf8.RuneCountInString(body) > smsLimit {
+		short := messageData{}
+		for _, m := range data.Changes {
+			short.Changes = append(short.Changes, MessageChange{Summary: m.Summary})
+		}
+		for body = render(short); utf8.RuneCountInString(body) > smsLimit && len(short.Changes) > 1; body = render(short) {
+			short.Changes = short.Changes[:len(short.Changes)-1]
+			short.More++
+		}
+		if runes := []rune(body); len(runes) > smsLimit {
+			body = string(runes[:smsLimit-3]) + "..."
+		}
+	}
+	return Message{Subject: messageSubject(changes), Body: body}
+}
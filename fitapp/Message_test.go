@@ -0,0 +1,86 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDescriptionDiff(t *testing.T) {
+	old := "Steps:\n1. open\n2. login\n3. crash\n\nSeen on\nlinux\nmac\n"
+	new := "Steps:\n1. open\n2. login with sso\n3. crash\n\nSeen on\nlinux\nmac\nwindows\n"
+	expected := []string{"  1. open", "- 2. login", "+ 2. login with sso", "  3. crash", "...", "  mac", "+ windows"}
+	if lines := descriptionDiff(old, new); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Unexpected diff %q", lines)
+	}
+	if lines := descriptionDiff("", "new\n"); !reflect.DeepEqual(lines, []string{"+ new"}) {
+		t.Errorf("Unexpected diff of a new Description %q", lines)
+	}
+	if lines := descriptionDiff("same\n", "same\n"); lines != nil {
+		t.Errorf("Unexpected diff without changes %q", lines)
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	config := bugs.Config{FitSite: "https://example.com/fit/"}
+	login := bugs.IssueChange{
+		Kind:       bugs.IssueChanged,
+		Name:       "Login-fails",
+		Title:      "Login fails",
+		Identifier: "LOGIN",
+		Fields:     []bugs.FieldChange{{Field: "Status", Old: "open", New: "in progress"}},
+		TagsAdded:  []string{"security", "notify_email_alice@example.com"},
+		Old: &bugs.IssueSnapshot{
+			IssueRecord: bugs.IssueRecord{Name: "Login-fails", Title: "Login fails"},
+			Description: "Fails with sso\n",
+		},
+		New: &bugs.IssueSnapshot{
+			IssueRecord: bugs.IssueRecord{Name: "Login-fails", Title: "Login fails"},
+			Description: "Fails with sso\nand with passwords\n",
+		},
+		DescriptionChanged: true,
+	}
+	search := bugs.IssueChange{Kind: bugs.IssueClosed, Name: "Slow-search", Title: "Slow search"}
+
+	m := BuildMessage(ChannelEmail, []bugs.IssueChange{login, search}, config)
+	expected := "Site: https://example.com/fit/\n\n" +
+		"Changed: (LOGIN) Login fails\n" +
+		"    Status: open -> in progress\n" +
+		"    Tags added: security\n" +
+		"    Description changed\n" +
+		"          Fails with sso\n" +
+		"        + and with passwords\n\n" +
+		"Closed: Slow search (removed)\n\n"
+	if m.Subject != "2 issues changed" || m.Body != expected {
+		t.Errorf("Unexpected email %q %q", m.Subject, m.Body)
+	}
+
+	m = BuildMessage(ChannelSMS, []bugs.IssueChange{login}, config)
+	expected = "Changed: (LOGIN) Login fails; Status: open -> in progress; Tags added: security; Description changed\n"
+	if m.Subject != "Changed: Login fails" || m.Body != expected {
+		t.Errorf("Unexpected sms %q %q", m.Subject, m.Body)
+	}
+
+	m = BuildMessage(ChannelWebhook, []bugs.IssueChange{search}, config)
+	if m.Body != "Closed: Slow search (removed)\n" {
+		t.Errorf("Unexpected webhook message %q", m.Body)
+	}
+
+	var many []bugs.IssueChange
+	for i := 1; i <= 10; i++ {
+		many = append(many, bugs.IssueChange{Kind: bugs.IssueCreated, Title: fmt.Sprintf("Issue number %d", i)})
+	}
+	m = BuildMessage(ChannelSMS, many, config)
+	if utf8.RuneCountInString(m.Body) > smsLimit || !strings.HasPrefix(m.Body, "Created: Issue number 1\n") ||
+		!strings.HasSuffix(m.Body, " more\n") {
+		t.Errorf("Unexpected sms of many changes %q", m.Body)
+	}
+	long := bugs.IssueChange{Kind: bugs.IssueCreated, Title: strings.Repeat("long ", 50)}
+	if m = BuildMessage(ChannelSMS, []bugs.IssueChange{long}, config); utf8.RuneCountInString(m.Body) != smsLimit ||
+		!strings.HasSuffix(m.Body, "...") {
+		t.Errorf("Unexpected sms of a long title %q", m.Body)
+	}
+}
@@ -0,0 +1,294 @@
+package fitapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"net/http"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Channels of notifications.
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+)
+
+// Recipient is an address of a notification channel: an email address,
+// a phone number for sms or the url of a webhook.
+type Recipient struct {
+	Channel string
+	Address string
+}
+
+// String returns the recipient as channel:address, like in NotifyTo.
+func (r Recipient) String() string {
+	return r.Channel + ":" + r.Address
+}
+
+// Notification is a message to a recipient about changed issues.
+type Notification struct {
+	To      Recipient
+	Subject string
+	Body    string
+	Changes []bugs.IssueChange
+}
+
+// Notifier sends notifications of a channel.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// EmailNotifier sends notifications as email with the SMTP server
+// Server, a host:port, authenticated when User is set.
+type EmailNotifier struct {
+	Server   string
+	User     string
+	Password string
+	From     string
+}
+
+// Notify sends an email.
+func (e EmailNotifier) Notify(n Notification) error {
+	if e.Server == "" || e.From == "" {
+		return fmt.Errorf("SmtpServer and NotifyFrom are needed to send email")
+	}
+	var auth smtp.Auth
+	if e.User != "" {
+		auth = smtp.PlainAuth("", e.User, e.Password, strings.Split(e.Server, ":")[0])
+	}
+	msg := "From: " + e.From + "\r\n" +
+		"To: " + n.To.Address + "\r\n" +
+		"Subject: " + n.Subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + strings.Replace(n.Body, "\n", "\r\n", -1)
+	return smtp.SendMail(e.Server, auth, e.From, []string{n.To.Address}, []byte(msg))
+}
+
+// WebhookNotifier posts notifications as json to the url of the
+// recipient.
+type WebhookNotifier struct {
+	Site   string
+	Client *http.Client
+}
+
+// Notify posts a notification.
+func (w WebhookNotifier) Notify(n Notification) error {
+	changes := []outputRecord{}
+	for _, c := range n.Changes {
+		changes = append(changes, diffRecord(c))
+	}
+	body, err := json.Marshal(outputRecord{
+		"Site":    w.Site,
+		"Subject": n.Subject,
+		"Body":    n.Body,
+		"Changes": changes,
+	})
+	if err != nil {
+		return err
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(n.To.Address, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s answered %s", n.To.Address, resp.Status)
+	}
+	return nil
+}
+
+// notifiers returns the notifier of every channel from the config.
+func notifiers(config bugs.Config) map[string]Notifier {
+	return map[string]Notifier{
+		ChannelEmail: EmailNotifier{
+			Server:   config.SmtpServer,
+			User:     config.SmtpUser,
+			Password: config.SmtpPassword,
+			From:     config.NotifyFrom,
+		},
+		ChannelSMS: TwilioNotifier{
+			ApiUrl:     config.TwilioApiUrl,
+			AccountSid: config.TwilioAccountSid,
+			AuthToken:  config.TwilioAuthToken,
+			From:       config.TwilioPhoneNumberFrom,
+		},
+		ChannelWebhook: WebhookNotifier{Site: config.FitSite},
+	}
+}
+
+// parseRecipient returns the recipient of channel:address from NotifyTo,
+// or channel_address from a notify tag.
+func parseRecipient(s string) (Recipient, bool) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, ":_")
+	if i <= 0 || i == len(s)-1 {
+		return Recipient{}, false
+	}
+	r := Recipient{strings.ToLower(s[:i]), s[i+1:]}
+	switch r.Channel {
+	case ChannelEmail, ChannelSMS, ChannelWebhook:
+		return r, true
+	}
+	return Recipient{}, false
+}
+
+// tagRecipient returns the recipient of a tag of an issue, a
+// notify:<channel>_<address> tag from a tag_notify_<channel>_<address>
+// file, or a twilio:<phone> tag for sms.
+func tagRecipient(tag string) (Recipient, bool) {
+	for _, prefix := range []string{"notify:", "notify_"} {
+		if strings.HasPrefix(tag, prefix) {
+			return parseRecipient(strings.TrimPrefix(tag, prefix))
+		}
+	}
+	for _, prefix := range []string{"twilio:", "twilio_"} {
+		if strings.HasPrefix(tag, prefix) && len(tag) > len(prefix) {
+			return Recipient{ChannelSMS, strings.TrimPrefix(tag, prefix)}, true
+		}
+	}
+	return Recipient{}, false
+}
+
+// changeSnapshot returns the snapshot of a changed issue, from before
+// the change when it was removed.
+func changeSnapshot(c bugs.IssueChange) *bugs.IssueSnapshot {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+// changeRecipients returns the recipients of a changed issue: its
+// watchers, the watchers of queries matching it and the recipients of
+// its notify and twilio tags.
+func changeRecipients(c bugs.IssueChange) []Recipient {
+	s := changeSnapshot(c)
+	if s == nil {
+		return nil
+	}
+	var recipients []Recipient
+	for _, w := range s.Watchers {
+		if r, ok := parseRecipient(w); ok {
+			recipients = append(recipients, r)
+		}
+	}
+	for _, tag := range s.Tags {
+		if r, ok := tagRecipient(tag); ok {
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients
+}
+
+// notifyRecipients returns the changes for each recipient, the changes
+// of issues they watch and every change for the recipients of NotifyTo,
+// in the order of recipients.
+func notifyRecipients(changes []bugs.IssueChange, config bugs.Config) ([]Recipient, map[Recipient][]bugs.IssueChange) {
+	byRecipient := map[Recipient][]bugs.IssueChange{}
+	everything := map[Recipient]bool{}
+	for _, to := range strings.Split(config.NotifyTo, ",") {
+		if r, ok := parseRecipient(to); ok {
+			byRecipient[r] = changes
+			everything[r] = true
+		}
+	}
+	for _, c := range changes {
+		seen := map[Recipient]bool{}
+		for _, r := range changeRecipients(c) {
+			if !everything[r] && !seen[r] {
+				byRecipient[r] = append(byRecipient[r], c)
+				seen[r] = true
+			}
+		}
+	}
+	var recipients []Recipient
+	for r := range byRecipient {
+		recipients = append(recipients, r)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		return recipients[i].String() < recipients[j].String()
+	})
+	return recipients, byRecipient
+}
+
+// notifyUsage prints how to use the notify subcommand.
+func notifyUsage() {
+	fmt.Printf("Usage: %s notify [<revision> [<revision>]] [--channel email|sms|webhook] [--dry-run]\n", os.Args[0])
+}
+
+// Notify is a subcommand to notify the recipients of changed issues by
+// email, sms or webhook. Issues are compared like diff does.
+func Notify(args argumentList, config bugs.Config) {
+	args, dryRun := args.RemoveFlag("--dry-run")
+	args, values := args.GetAndRemoveArguments([]string{"--channel"})
+	channel := values[0]
+	if len(args) > 2 || channel == "true" {
+		notifyUsage()
+		return
+	}
+	handler, _, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	from, to := "HEAD", ""
+	if handler.SCMTyper() == "hg" {
+		from = "."
+	}
+	if len(args) > 0 {
+		from = args[0]
+	}
+	if len(args) > 1 {
+		to = args[1]
+	}
+	changes, err := scm.DiffIssues(handler, from, to, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	notifyChanges(changes, channel, dryRun, config)
+}
+
+// notifyChanges sends the notifications of changes, of one channel
+// unless channel is empty, or prints them with dryRun.
+func notifyChanges(changes []bugs.IssueChange, channel string, dryRun bool, config bugs.Config) {
+	if len(changes) == 0 {
+		fmt.Printf("No changed issues\n")
+		return
+	}
+	recipients, byRecipient := notifyRecipients(changes, config)
+	senders := notifiers(config)
+	sent := 0
+	for _, r := range recipients {
+		if channel != "" && r.Channel != channel {
+			continue
+		}
+		n := Notification{To: r, Changes: byRecipient[r]}
+		m := BuildMessage(r.Channel, n.Changes, config)
+		n.Subject, n.Body = m.Subject, m.Body
+		sent++
+		if dryRun {
+			fmt.Printf("To: %s\nSubject: %s\n\n%s\n", r, n.Subject, n.Body)
+			continue
+		}
+		if err := senders[r.Channel].Notify(n); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: notifying %s: %s\n", r, err.Error())
+			continue
+		}
+		fmt.Printf("Notified %s of %d issues\n", r, len(n.Changes))
+	}
+	if sent == 0 {
+		fmt.Printf("No one to notify\n")
+	}
+}
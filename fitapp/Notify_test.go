@@ -0,0 +1,224 @@
+package fitapp
+
+import (
+	"bufio"
+	"encoding/json"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// setupNotifyRepo creates a git repository with committed issues and
+// returns the config, a function committing every change and a function
+// removing the repository.
+func setupNotifyRepo(t *testing.T) (bugs.Config, func(msg string), func()) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("WARN git executable not found")
+	}
+	dir, err := ioutil.TempDir("", "notifytest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	exec.Command("git", "config", "user.name", "Tester").Run()
+	exec.Command("git", "config", "user.email", "tester@example.com").Run()
+	commit := func(msg string) {
+		exec.Command("git", "add", "-A").Run()
+		if out, err := exec.Command("git", "commit", "-q", "-m", msg).CombinedOutput(); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	}
+	captureOutput(func() {
+		Create(argumentList{"-n", "Login", "fails", "--status", "open", "--identifier", "LOGIN"}, config)
+		Create(argumentList{"-n", "Slow", "search", "--identifier", "SEARCH"}, config)
+		Tag(argumentList{"LOGIN", "notify_email_alice@example.com"}, config)
+		Tag(argumentList{"LOGIN", "twilio_+14155551212"}, config)
+		Tag(argumentList{"SEARCH", "notify_email_bob@example.com"}, config)
+	}, t)
+	commit("Create issues")
+	return config, commit, func() {
+		os.Chdir(pwd)
+		os.RemoveAll(dir)
+	}
+}
+
+// smtpStandIn listens for SMTP clients on a local port and returns its
+// address and a function returning the messages received so far.
+func smtpStandIn(t *testing.T) (string, func() []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Could not listen: " + err.Error())
+	}
+	var mu sync.Mutex
+	var messages []string
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			conn.Write([]byte("220 localhost\r\n"))
+			var data strings.Builder
+			inData := false
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					break
+				}
+				switch {
+				case inData && line == ".\r\n":
+					inData = false
+					mu.Lock()
+					messages = append(messages, data.String())
+					mu.Unlock()
+					data.Reset()
+					conn.Write([]byte("250 ok\r\n"))
+				case inData:
+					data.WriteString(line)
+				case strings.HasPrefix(line, "DATA"):
+					inData = true
+					conn.Write([]byte("354 go ahead\r\n"))
+				case strings.HasPrefix(line, "QUIT"):
+					conn.Write([]byte("221 bye\r\n"))
+				default:
+					conn.Write([]byte("250 ok\r\n"))
+				}
+			}
+			conn.Close()
+		}
+	}()
+	return l.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, messages...)
+	}
+}
+
+func TestParseRecipient(t *testing.T) {
+	for tag, expected := range map[string]Recipient{
+		"notify:email_alice@example.com": {ChannelEmail, "alice@example.com"},
+		"notify_sms_+14155551212":        {ChannelSMS, "+14155551212"},
+		"twilio:4155551212":              {ChannelSMS, "4155551212"},
+	} {
+		if r, ok := tagRecipient(tag); !ok || r != expected {
+			t.Errorf("Unexpected recipient of %s: %v", tag, r)
+		}
+	}
+	for _, tag := range []string{"notify", "notify:fax_123", "twilio:", "security", "status:open"} {
+		if r, ok := tagRecipient(tag); ok {
+			t.Errorf("Unexpected recipient of %s: %v", tag, r)
+		}
+	}
+	if r, ok := parseRecipient(" webhook:https://example.com/hook "); !ok || r != (Recipient{ChannelWebhook, "https://example.com/hook"}) {
+		t.Errorf("Unexpected recipient %v", r)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	config, _, done := setupNotifyRepo(t)
+	defer done()
+
+	smtpAddr, mails := smtpStandIn(t)
+	var mu sync.Mutex
+	var sms []url.Values
+	var hooks []map[string]interface{}
+	api := http.NewServeMux()
+	api.HandleFunc("/AC1/Messages.json", func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "AC1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		mu.Lock()
+		sms = append(sms, r.PostForm)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	api.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		hooks = append(hooks, event)
+		mu.Unlock()
+	})
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	config.SmtpServer = smtpAddr
+	config.NotifyFrom = "fit@example.com"
+	config.TwilioApiUrl = srv.URL
+	config.TwilioAccountSid = "AC1"
+	config.TwilioPhoneNumberFrom = "+15005550006"
+	config.NotifyTo = "webhook:" + srv.URL + "/hook"
+
+	stdout, _ := captureOutput(func() {
+		Notify(argumentList{}, config)
+	}, t)
+	if stdout != "No changed issues\n" {
+		t.Errorf("Unexpected output without changes: %s", stdout)
+	}
+
+	captureOutput(func() {
+		Status(argumentList{"LOGIN", "closed"}, config)
+	}, t)
+	stdout, stderr := captureOutput(func() {
+		Notify(argumentList{"--dry-run"}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "To: email:alice@example.com\nSubject: Closed: Login fails\n\nClosed: (LOGIN) Login fails\n") ||
+		strings.Contains(stdout, "bob@example.com") || stderr != "" {
+		t.Errorf("Unexpected dry run: %s %s", stdout, stderr)
+	}
+	if len(mails()) != 0 || len(sms) != 0 || len(hooks) != 0 {
+		t.Errorf("Unexpected notifications of a dry run")
+	}
+
+	stdout, stderr = captureOutput(func() {
+		Notify(argumentList{}, config)
+	}, t)
+	expected := "Notified email:alice@example.com of 1 issues\n" +
+		"Notified sms:+14155551212 of 1 issues\n" +
+		"Notified webhook:" + srv.URL + "/hook of 1 issues\n"
+	if stdout != expected || stderr != "" {
+		t.Errorf("Unexpected output notifying: %s %s", stdout, stderr)
+	}
+	if m := mails(); len(m) != 1 || !strings.Contains(m[0], "To: alice@example.com\r\n") ||
+		!strings.Contains(m[0], "Subject: Closed: Login fails\r\n") {
+		t.Errorf("Unexpected mails %v", m)
+	}
+	if len(sms) != 1 || sms[0].Get("To") != "+14155551212" || sms[0].Get("From") != "+15005550006" ||
+		sms[0].Get("Body") != "Closed: (LOGIN) Login fails; Status: open -> closed\n" {
+		t.Errorf("Unexpected sms %v", sms)
+	}
+	if len(hooks) != 1 || hooks[0]["Subject"] != "Closed: Login fails" ||
+		!reflect.DeepEqual(hooks[0]["Changes"].([]interface{})[0].(map[string]interface{})["Identifier"], "LOGIN") {
+		t.Errorf("Unexpected webhook events %v", hooks)
+	}
+
+	config.TwilioAccountSid = "AC2"
+	stdout, stderr = captureOutput(func() {
+		Twilio(config)
+	}, t)
+	if stdout != "" || !strings.HasPrefix(stderr, "Error: notifying sms:+14155551212: twilio answered 404") {
+		t.Errorf("Unexpected output of a failed sms: %s %s", stdout, stderr)
+	}
+}
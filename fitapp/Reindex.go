@@ -0,0 +1,21 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"os"
+)
+
+// Reindex is a subcommand to rebuild the cache of the issues.
+func Reindex(config bugs.Config) {
+	if bugs.FitDirer(config) == "" {
+		fmt.Fprintf(os.Stderr, "No fit directory found\n")
+		return
+	}
+	n, err := bugs.Reindex(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %s\n", bugs.CacheFile(config), err.Error())
+		return
+	}
+	fmt.Printf("Indexed %d issues in %s\n", n, bugs.CacheFile(config))
+}
@@ -0,0 +1,41 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReindex(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "reindextest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.Mkdir(".git", 0755)
+	os.Mkdir(config.FitDirName, 0755)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	captureOutput(func() {
+		Create(argumentList{"-n", "Test", "bug", "--status", "open"}, config)
+	}, t)
+	runfind(argumentList{"status", "open"}, "^Issue 1: Test bug \\(Status: open\\)\n$", t)
+
+	// changed in place by another program
+	ioutil.WriteFile(dir+sops+"fit"+sops+"Test-bug"+sops+"Status", []byte("closed\n"), 0644)
+	stdout, stderr := captureOutput(func() {
+		Reindex(config)
+	}, t)
+	if stderr != "" || stdout != "Indexed 1 issues in "+bugs.CacheFile(config)+"\n" {
+		t.Errorf("Unexpected reindex output %s %s", stdout, stderr)
+	}
+	runfind(argumentList{"status", "closed"}, "^Issue 1: Test bug \\(Status: closed\\)\n$", t)
+}
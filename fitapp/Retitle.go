@@ -27,7 +27,7 @@ func Relabel(Args argumentList, config bugs.Config) {
 	currentDir := b.Direr()
 	newDir := bugs.FitDirer(config) + dops + bugs.TitleToDir(strings.Join(Args[1:], " "))
 	fmt.Printf("Moving %s to %s\n", currentDir, newDir)
-	err = os.Rename(string(currentDir), string(newDir))
+	err = bugs.CurrentStore().Rename(string(currentDir), string(newDir))
 	if err != nil {
 		fmt.Printf("Error moving directory\n")
 	}
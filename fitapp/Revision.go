@@ -0,0 +1,70 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"io"
+	"os"
+	"time"
+)
+
+// revisionDateFormats are the formats of dates of --at, in the local time
+// zone unless the date has one.
+var revisionDateFormats = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// parseRevisionDate returns the time of a date of --at.
+func parseRevisionDate(date string) (time.Time, error) {
+	for _, format := range revisionDateFormats {
+		if t, err := time.ParseInLocation(format, date, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid date %s, use YYYY-MM-DD[ HH:MM[:SS]]", date)
+}
+
+// revisionArguments removes --rev <revision> and --at <date> from args.
+// With either the issues are read from that revision of the repository,
+// without changing the working tree, until the returned function is
+// called. It returns false after printing an error.
+func revisionArguments(args argumentList, config bugs.Config) (argumentList, func(), bool) {
+	if !args.HasArgument("--rev") && !args.HasArgument("--at") {
+		return args, func() {}, true
+	}
+	args, values := args.GetAndRemoveArguments([]string{"--rev", "--at"})
+	rev, at := values[0], values[1]
+	fail := func(err error) (argumentList, func(), bool) {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return args, func() {}, false
+	}
+	switch {
+	case rev != "" && at != "":
+		return fail(fmt.Errorf("Use --rev or --at, not both"))
+	case rev == "true" || at == "true":
+		return fail(fmt.Errorf("Missing value of --rev <revision> or --at <date>"))
+	}
+	handler, _, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		return fail(err)
+	}
+	if at != "" {
+		date, err := parseRevisionDate(at)
+		if err != nil {
+			return fail(err)
+		}
+		if rev, err = handler.RevisionAt(date, config); err != nil {
+			return fail(err)
+		}
+	}
+	store, err := handler.RevisionStore(rev, config)
+	if err != nil {
+		return fail(err)
+	}
+	old := bugs.SetStore(store)
+	return args, func() {
+		bugs.SetStore(old)
+		if c, ok := store.(io.Closer); ok {
+			c.Close()
+		}
+	}, true
+}
@@ -0,0 +1,110 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRevisionDate(t *testing.T) {
+	for date, expected := range map[string]time.Time{
+		"2020-06-01":                time.Date(2020, 6, 1, 0, 0, 0, 0, time.Local),
+		"2020-06-01 12:30":          time.Date(2020, 6, 1, 12, 30, 0, 0, time.Local),
+		"2020-06-01 12:30:15":       time.Date(2020, 6, 1, 12, 30, 15, 0, time.Local),
+		"2020-06-01T12:30:15Z":      time.Date(2020, 6, 1, 12, 30, 15, 0, time.UTC),
+		"2020-06-01T12:30:15-07:00": time.Date(2020, 6, 1, 19, 30, 15, 0, time.UTC),
+	} {
+		if got, err := parseRevisionDate(date); err != nil || !got.Equal(expected) {
+			t.Errorf("Unexpected time of %s: %s %v", date, got, err)
+		}
+	}
+	if _, err := parseRevisionDate("June"); err == nil {
+		t.Errorf("Expected an error for an invalid date")
+	}
+}
+
+func TestRevisionArguments(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("WARN git executable not found")
+	}
+	dir, err := ioutil.TempDir("", "revisiontest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	commit := func(msg string) {
+		exec.Command("git", "add", "-A").Run()
+		cmd := exec.Command("git", "-c", "user.name=Tester", "-c", "user.email=tester@example.com", "commit", "-q", "-m", msg)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	}
+
+	captureOutput(func() {
+		Create(argumentList{"-n", "Release", "bug", "--milestone", "v1.2", "--status", "open"}, config)
+	}, t)
+	commit("Create Release bug")
+	exec.Command("git", "tag", "v1.2").Run()
+	captureOutput(func() {
+		Status(argumentList{"1", "closed"}, config)
+		Create(argumentList{"-n", "Later", "bug"}, config)
+	}, t)
+
+	stdout, _ := captureOutput(func() {
+		Find(argumentList{"milestone:v1.2", "AND", "status:open", "--rev", "v1.2"}, config)
+	}, t)
+	if stdout != "Issue 1: Release bug (Status: open)\n" {
+		t.Errorf("Unexpected issues at v1.2: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Find(argumentList{"milestone:v1.2", "AND", "status:open"}, config)
+	}, t)
+	if stdout != "" {
+		t.Errorf("Unexpected open issues of the working tree: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		List(argumentList{"--at", time.Now().Add(time.Minute).Format("2006-01-02 15:04:05")}, config, true)
+	}, t)
+	if !strings.Contains(stdout, "Issue 1: Release bug\n") || strings.Contains(stdout, "Later") {
+		t.Errorf("Unexpected issues of the last commit: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Roadmap(argumentList{"--rev", "v1.2"}, config)
+	}, t)
+	if !strings.Contains(stdout, "## v1.2:\n- Release bug (Status: open)") {
+		t.Errorf("Unexpected roadmap at v1.2: %s", stdout)
+	}
+	if bugs.CurrentStore() != (bugs.OSStore{}) {
+		t.Errorf("Store not restored after --rev")
+	}
+
+	_, stderr := captureOutput(func() {
+		List(argumentList{"--rev", "missing"}, config, true)
+	}, t)
+	if stderr != "Error: Unknown revision missing\n" {
+		t.Errorf("Unexpected error for a missing revision: %s", stderr)
+	}
+	_, stderr = captureOutput(func() {
+		List(argumentList{"--rev", "v1.2", "--at", "2020-01-01"}, config, true)
+	}, t)
+	if stderr != "Error: Use --rev or --at, not both\n" {
+		t.Errorf("Unexpected error for --rev and --at: %s", stderr)
+	}
+}
@@ -16,8 +16,12 @@ type IssueListByMilestone [](bugs.Issue)
 func (a IssueListByMilestone) Len() int      { return len(a) }
 func (a IssueListByMilestone) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a IssueListByMilestone) Less(i, j int) bool {
-	iMS := a[i].Milestone()
-	jMS := a[j].Milestone()
+	return milestoneLess(a[i].Milestone(), a[j].Milestone())
+}
+
+// milestoneLess compares milestones as semantic versions, then as
+// numbers and then as strings.
+func milestoneLess(iMS, jMS string) bool {
 	// If there's a "v" at the start, strip it out
 	// before doing any comparisons of semantic
 	// versions
@@ -48,17 +52,72 @@ func (a IssueListByMilestone) Less(i, j int) bool {
 
 // Roadmap is a subcommand to output issues by milestone.
 func Roadmap(args argumentList, config bugs.Config) {
+	args, restore, ok := revisionArguments(args, config)
+	if !ok {
+		return
+	}
+	defer restore()
+	args, format := formatArgument(args)
 	var bgs []bugs.Issue
 
-	if args.HasArgument("--filter") {
-		tags := strings.Split(args.GetArgument("--filter", ""), ",")
-		fmt.Printf("%s", tags)
+	var tags []string
+	if filter := args.GetArgument("--filter", ""); isQuery(strings.Fields(filter)) { // from Find.go
+		q, ok := parseQuery(filter)
+		if !ok {
+			return
+		}
+		bgs = bugs.FindIssuesByQuery(q, config)
+	} else if filter != "" {
+		tags = strings.Split(filter, ",")
 		bgs = bugs.FindIssuesByTag(tags, config)
 	} else {
 		bgs = bugs.GetAllIssues(config)
 	}
 	sort.Sort(IssueListByMilestone(bgs))
+	roadmapOrder(bgs, bugs.NewLinkGraph(config))
+
+	writeReport(format, outputReport{
+		Columns: issueColumns,
+		Records: func() []outputRecord {
+			var records []outputRecord
+			for i := len(bgs) - 1; i >= 0; i -= 1 {
+				records = append(records, issueRecord(bgs[i]))
+			}
+			return records
+		},
+		Text: func() {
+			if tags != nil {
+				fmt.Printf("%s", tags)
+			}
+			roadmapText(bgs, args, config)
+		},
+	})
+}
+
+// roadmapOrder orders the issues of each milestone after the issues
+// blocking them. Issues are printed from the end of bgs.
+func roadmapOrder(bgs []bugs.Issue, g *bugs.LinkGraph) {
+	for start := 0; start < len(bgs); {
+		end := start
+		for end < len(bgs) && bgs[end].Milestone() == bgs[start].Milestone() {
+			end++
+		}
+		var names []string
+		issues := map[string]bugs.Issue{}
+		for i := end - 1; i >= start; i-- {
+			name := string(bgs[i].Dir.ShortNamer())
+			names = append(names, name)
+			issues[name] = bgs[i]
+		}
+		for i, name := range g.TopologicalOrder(names) {
+			bgs[end-1-i] = issues[name]
+		}
+		start = end
+	}
+}
 
+// roadmapText prints issues sorted by milestone as markdown.
+func roadmapText(bgs []bugs.Issue, args argumentList, config bugs.Config) {
 	fmt.Printf("# Roadmap for %s\n", bugs.RootDirer(&config).ShortNamer().ToTitle())
 	milestone := ""
 	for i := len(bgs) - 1; i >= 0; i -= 1 {
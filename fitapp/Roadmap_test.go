@@ -6,12 +6,49 @@ import (
 	"io/ioutil"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
 //var sops = string(os.PathSeparator)
 
+func TestRoadmapBlocking(t *testing.T) {
+	config, done := setupLinkedIssues(t) // from Link_test.go
+	defer done()
+	// the issues of a milestone are listed after the issues blocking them
+	stdout, _ := captureOutput(func() {
+		Roadmap(argumentList{"--simple"}, config)
+	}, t)
+	expected := "\n## v2:\n- Docs\n\n## v1:\n- Database schema\n- Api\n- Ui\n"
+	if !strings.HasSuffix(stdout, expected) {
+		t.Errorf("Unexpected roadmap: %s", stdout)
+	}
+}
+
+func TestRoadmapFilterQuery(t *testing.T) {
+	config, done := setupLinkedIssues(t) // from Link_test.go
+	defer done()
+	captureOutput(func() {
+		Due(argumentList{"API", "3d"}, config)
+		Due(argumentList{"DOC", "30d"}, config)
+	}, t)
+	stdout, stderr := captureOutput(func() {
+		Roadmap(argumentList{"--simple", "--filter", "due<7d"}, config)
+	}, t)
+	if !strings.HasSuffix(stdout, "\n## v1:\n- Api\n") || stderr != "" {
+		t.Errorf("Unexpected roadmap of a date query: %s %s", stdout, stderr)
+	}
+	stdout, _ = captureOutput(func() {
+		Roadmap(argumentList{"--simple", "--filter", "NOT due<7d"}, config)
+	}, t)
+	// Ui and Database schema do not block each other without Api
+	if !strings.Contains(stdout, "\n## v2:\n- Docs\n\n## v1:\n") || strings.Contains(stdout, "- Api\n") ||
+		!strings.Contains(stdout, "- Database schema\n") || !strings.Contains(stdout, "- Ui\n") {
+		t.Errorf("Unexpected roadmap of a negated query: %s", stdout)
+	}
+}
+
 func TestRoadmapLess(t *testing.T) {
 	// func (a BugListByMilestone) Less(i, j int) bool {
 	config := bugs.Config{}
@@ -0,0 +1,127 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// searchColumns are the csv columns of search.
+var searchColumns = []string{"ID", "Identifier", "Title", "Score", "Snippet", "Dir"}
+
+// searchFitDirs returns the fit directories below root other than the
+// one of root itself, for MultipleFitDirs.
+func searchFitDirs(root string, config bugs.Config) []string {
+	var dirs []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() || path == root {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if info.Name() == config.FitDirName {
+			if filepath.Dir(path) != root {
+				dirs = append(dirs, path)
+			}
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return dirs
+}
+
+// Search is a subcommand to search the text of issues. Results are
+// ranked by BM25 with snippets of the matching text.
+func Search(args argumentList, config bugs.Config) {
+	args, format := formatArgument(args)
+	recursive := config.MultipleFitDirs
+	var terms []string
+	for _, arg := range args {
+		switch arg {
+		case "--recursive", "-r":
+			recursive = true
+		default:
+			terms = append(terms, arg)
+		}
+	}
+	if len(terms) == 0 {
+		fmt.Printf("Usage: %s search [-r|--recursive] <term>...\n", os.Args[0])
+		return
+	}
+
+	fitdir := bugs.FitDirer(config)
+	// issues of the fit directory are numbered like list does
+	ids := map[string]int{}
+	issues := readIssues(string(fitdir))
+	sort.Sort(byDir(issues))
+	index := bugs.NewSearchIndex()
+	for idx, issue := range issues {
+		b := bugs.Issue{}
+		b.LoadIssue(fitdir+dops+bugs.Directory(issue.Name()), config)
+		ids[string(b.Dir)] = idx + 1
+		index.Add(b)
+	}
+	if recursive {
+		root := string(bugs.RootDirer(&config))
+		for _, dir := range searchFitDirs(root, config) {
+			for _, issue := range readIssues(dir) {
+				b := bugs.Issue{}
+				b.LoadIssue(bugs.Directory(dir+sops+issue.Name()), config)
+				index.Add(b)
+			}
+		}
+	}
+	results := index.Search(strings.Join(terms, " "))
+
+	// issues of nested fit directories are named by their path
+	name := func(r bugs.SearchResult) string {
+		b := bugs.Issue{Dir: bugs.Directory(r.Doc.Dir)}
+		if id, ok := ids[r.Doc.Dir]; ok {
+			return issueNamer(b, id-1)
+		}
+		rel, err := filepath.Rel(string(bugs.RootDirer(&config)), r.Doc.Dir)
+		if err != nil {
+			rel = r.Doc.Dir
+		}
+		if id := b.Identifier(); id != "" {
+			return fmt.Sprintf("Issue %s (%s)", id, rel)
+		}
+		return "Issue " + rel
+	}
+	writeReport(format, outputReport{
+		Columns: searchColumns,
+		Records: func() []outputRecord {
+			var records []outputRecord
+			for _, r := range results {
+				b := bugs.Issue{Dir: bugs.Directory(r.Doc.Dir)}
+				record := outputRecord{
+					"Identifier": b.Identifier(),
+					"Title":      r.Doc.Title,
+					"Score":      r.Score,
+					"Snippet":    r.Snippet,
+					"Dir":        r.Doc.Dir,
+				}
+				if id, ok := ids[r.Doc.Dir]; ok {
+					record["ID"] = id
+				}
+				records = append(records, record)
+			}
+			return records
+		},
+		Text: func() {
+			if len(results) == 0 {
+				fmt.Printf("No issues found for %s\n", strings.Join(terms, " "))
+			}
+			for _, r := range results {
+				fmt.Printf("%s: %s (%.2f)\n", name(r), r.Doc.Title, r.Score)
+				if r.Snippet != "" {
+					fmt.Printf("    %s\n", r.Snippet)
+				}
+			}
+		},
+	})
+}
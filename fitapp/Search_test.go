@@ -0,0 +1,73 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "searchtest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0755)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	captureOutput(func() {
+		Create(argumentList{"-n", "Crash", "on", "start"}, config)
+		Create(argumentList{"-n", "Slow", "start"}, config)
+	}, t)
+	b := bugs.Issue{Dir: bugs.Directory(dir + sops + "fit" + sops + "Slow-start")}
+	b.CommentIssue(bugs.Comment{Body: "it may crash too"}, config)
+	// a nested fit directory
+	os.MkdirAll(dir+sops+"lib"+sops+"fit"+sops+"Lib-crash", 0755)
+	ioutil.WriteFile(dir+sops+"lib"+sops+"fit"+sops+"Lib-crash"+sops+"Description", []byte("crash\n"), 0644)
+
+	stdout, _ := captureOutput(func() {
+		Search(argumentList{"CRASH"}, config)
+	}, t)
+	lines := strings.Split(stdout, "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "Issue 1: Crash on start (") ||
+		lines[1] != "    **Crash** on start" || !strings.HasPrefix(lines[2], "Issue 2: Slow start (") ||
+		lines[3] != "    it may **crash** too" {
+		t.Errorf("Unexpected search output %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Search(argumentList{"-r", "crash"}, config)
+	}, t)
+	if !strings.Contains(stdout, "Issue lib/fit/Lib-crash: Lib crash (") {
+		t.Errorf("Expected nested issue in search output %s", stdout)
+	}
+	config.MultipleFitDirs = true
+	stdout, _ = captureOutput(func() {
+		Search(argumentList{"--format", "csv", "crash"}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "ID,Identifier,Title,Score,Snippet,Dir\n") || strings.Count(stdout, "\n") != 4 {
+		t.Errorf("Unexpected csv search output %s", stdout)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Search(argumentList{"nothing"}, config)
+	}, t)
+	if stdout != "No issues found for nothing\n" {
+		t.Errorf("Unexpected output without results %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Search(argumentList{}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "Usage: ") {
+		t.Errorf("Expected usage, got %s", stdout)
+	}
+}
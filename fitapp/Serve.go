@@ -0,0 +1,342 @@
+package fitapp
+
+import (
+	"encoding/json"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//var dops = bugs.Directory(os.PathSeparator)
+//var sops = string(os.PathSeparator)
+
+// issueRequest is the json body accepted when creating an issue.
+type issueRequest struct {
+	Title       string
+	Description string
+	Status      string
+	Priority    string
+	Milestone   string
+	Identifier  string
+	Tags        []string
+}
+
+// fieldRequest is the json body accepted when setting a field or tag.
+type fieldRequest struct {
+	Value string
+}
+
+// commentRequest is the json body accepted when commenting on an issue.
+type commentRequest struct {
+	Author string
+	Body   string
+}
+
+// serveError is the json body returned with an error status code.
+type serveError struct {
+	Error string
+}
+
+// issueServer answers http requests using the issues of one fit directory.
+type issueServer struct {
+	config bugs.Config
+	// RootDirer changes the working directory so requests are serialized.
+	mu sync.Mutex
+}
+
+// serveHandler returns an http.Handler for the issues of config.
+// Responses are HAL documents, see Hal.go.
+//
+//	GET    /                             links to everything below
+//	GET    /issues                       list issues
+//	POST   /issues                       create an issue
+//	GET    /issues/<IssueID>             get an issue
+//	DELETE /issues/<IssueID>             close an issue
+//	POST   /issues/<IssueID>/close       close an issue
+//	GET    /issues/<IssueID>/fields      get the fields
+//	GET    /issues/<IssueID>/fields/<F>  get field F
+//	PUT    /issues/<IssueID>/fields/<F>  set field F
+//	GET    /issues/<IssueID>/tags        get the tags
+//	POST   /issues/<IssueID>/tags        add a tag
+//	DELETE /issues/<IssueID>/tags/<tag>  remove a tag
+//	GET    /issues/<IssueID>/comments    list comments
+//	POST   /issues/<IssueID>/comments    add a comment
+//	GET    /milestones                   list milestones
+//	GET    /milestones/<milestone>       list issues of a milestone
+func serveHandler(config bugs.Config) http.Handler {
+	return &issueServer{config: config}
+}
+
+// Serve is a subcommand to answer http requests for issues as json.
+func Serve(args argumentList, config bugs.Config) {
+	addr := args.GetArgument("--addr", "localhost:8080")
+	fmt.Printf("Serving %s at http://%s/\n", bugs.FitDirer(config), addr)
+	if err := http.ListenAndServe(addr, serveHandler(config)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving: %s\n", err.Error())
+	}
+}
+
+// pathSegments splits an escaped url path, unescaping each segment so
+// identifiers like GitHub:user/repo#1 can be passed as %2F and %23.
+func pathSegments(r *http.Request) []string {
+	var segments []string
+	for _, s := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		if s == "" {
+			continue
+		}
+		if u, err := url.PathUnescape(s); err == nil {
+			segments = append(segments, u)
+		} else {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// serveFields are the fields read and set below /fields, other names
+// could be files outside the issue directory.
+var serveFields = append([]string{"Status", "Priority", "Milestone", "Identifier"}, bugs.DateFields...)
+
+// serveField returns the field named by a path segment, false when it is
+// not one of serveFields.
+func serveField(name string) (string, bool) {
+	for _, f := range serveFields {
+		if strings.EqualFold(f, name) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// validTag returns false for tags that are empty or could be files
+// outside the tags directory.
+func validTag(tag string) bool {
+	return tag != "" && !strings.ContainsAny(tag, `/\`) && !strings.Contains(tag, "..")
+}
+
+func (s *issueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := pathSegments(r)
+	switch {
+	case len(p) == 0 && r.Method == "GET":
+		writeJSON(w, http.StatusOK, newHalRoot())
+	case len(p) == 1 && p[0] == "issues" && r.Method == "GET":
+		s.list(w, r, "/issues", issueRefs(s.config))
+	case len(p) == 1 && p[0] == "issues" && r.Method == "POST":
+		s.create(w, r)
+	case len(p) == 1 && p[0] == "milestones" && r.Method == "GET":
+		var items []interface{}
+		for _, m := range milestonesOf(issueRefs(s.config)) {
+			items = append(items, m)
+		}
+		writeJSON(w, http.StatusOK, newHalCollection(r, "/milestones", "milestones", items))
+	case len(p) == 2 && p[0] == "milestones" && r.Method == "GET":
+		name := p[1]
+		if name == halNoMilestone {
+			name = ""
+		}
+		var refs []issueRef
+		for _, ref := range issueRefs(s.config) {
+			if ref.issue.Milestone() == name {
+				refs = append(refs, ref)
+			}
+		}
+		s.list(w, r, milestoneHref(name), refs)
+	case len(p) >= 2 && p[0] == "issues":
+		b, err := bugs.LoadIssueByHeuristic(p[1], s.config)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.issue(w, r, b, issueRefs(s.config), p[2:])
+	case len(p) == 0, len(p) == 1 && (p[0] == "issues" || p[0] == "milestones"):
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed "+r.Method)
+	default:
+		writeError(w, http.StatusNotFound, "Not found "+r.URL.Path)
+	}
+}
+
+// issue answers requests below /issues/<IssueID>, refs are the issues
+// of issueRefs resolved once for the request.
+func (s *issueServer) issue(w http.ResponseWriter, r *http.Request, b *bugs.Issue, refs []issueRef, p []string) {
+	ref := issueRef{ref: refForIssue(*b, refs), issue: *b}
+	switch {
+	case len(p) == 0 && r.Method == "GET":
+		writeJSON(w, http.StatusOK, newHalIssue(ref))
+	case len(p) == 0 && r.Method == "DELETE",
+		len(p) == 1 && p[0] == "close" && r.Method == "POST":
+		if err := closeIssue(b, s.config); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(p) == 1 && p[0] == "fields" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, newHalFields(ref))
+	case len(p) == 2 && p[0] == "fields" && r.Method == "GET":
+		field, ok := serveField(p[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, "Unknown field "+p[1])
+			return
+		}
+		writeJSON(w, http.StatusOK, newHalField(ref, field))
+	case len(p) == 2 && p[0] == "fields" && (r.Method == "PUT" || r.Method == "POST"):
+		field, ok := serveField(p[1])
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Unknown field "+p[1])
+			return
+		}
+		var f fieldRequest
+		if !readRequest(w, r, &f) {
+			return
+		}
+		if err := b.SetField(field, f.Value, s.config); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, newHalIssue(ref))
+	case len(p) == 1 && p[0] == "tags" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, newHalTags(ref))
+	case len(p) == 1 && p[0] == "tags" && r.Method == "POST":
+		var f fieldRequest
+		if !readRequest(w, r, &f) {
+			return
+		}
+		if f.Value == "" {
+			writeError(w, http.StatusBadRequest, "Tag Value is required")
+			return
+		}
+		if !validTag(f.Value) {
+			writeError(w, http.StatusBadRequest, "Invalid tag "+f.Value)
+			return
+		}
+		if err := b.TagIssue(bugs.TagBoolTrue(f.Value), s.config); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, newHalIssue(ref))
+	case len(p) == 2 && p[0] == "tags" && r.Method == "DELETE":
+		if !validTag(p[1]) {
+			writeError(w, http.StatusBadRequest, "Invalid tag "+p[1])
+			return
+		}
+		if err := b.RemoveTag(bugs.TagBoolTrue(p[1]), s.config); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, newHalIssue(ref))
+	case len(p) == 1 && p[0] == "comments" && r.Method == "GET":
+		var items []interface{}
+		for _, c := range b.Comments() {
+			items = append(items, halComment{Order: c.Order, Author: c.Author, Time: c.Time, Body: c.Body})
+		}
+		coll := newHalCollection(r, issueHref(ref.ref)+"/comments", "comments", items)
+		coll.Links["issue"] = halLink{Href: issueHref(ref.ref)}
+		writeJSON(w, http.StatusOK, coll)
+	case len(p) == 1 && p[0] == "comments" && r.Method == "POST":
+		var c commentRequest
+		if !readRequest(w, r, &c) {
+			return
+		}
+		if c.Body == "" {
+			writeError(w, http.StatusBadRequest, "Comment Body is required")
+			return
+		}
+		b.CommentIssue(bugs.Comment{Author: c.Author, Body: c.Body}, s.config)
+		writeJSON(w, http.StatusCreated, newHalIssue(ref))
+	default:
+		writeError(w, http.StatusNotFound, "Not found "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// list writes one page of issues as a collection.
+func (s *issueServer) list(w http.ResponseWriter, r *http.Request, base string, refs []issueRef) {
+	var items []interface{}
+	for _, ref := range refs {
+		items = append(items, newHalIssue(ref))
+	}
+	writeJSON(w, http.StatusOK, newHalCollection(r, base, "issues", items))
+}
+
+// create makes a new issue from an issueRequest.
+func (s *issueServer) create(w http.ResponseWriter, r *http.Request) {
+	var req issueRequest
+	if !readRequest(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+	for _, tag := range req.Tags {
+		if !validTag(tag) {
+			writeError(w, http.StatusBadRequest, "Invalid tag "+tag)
+			return
+		}
+	}
+	b, err := bugs.New(req.Title, s.config)
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	b.LoadIssue(b.Direr(), s.config)
+	if err := b.SetDescription(req.Description, s.config); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, tag := range req.Tags {
+		if err := b.TagIssue(bugs.TagBoolTrue(tag), s.config); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	for _, f := range []struct {
+		value string
+		set   func(string, bugs.Config) error
+	}{
+		{req.Status, b.SetStatus},
+		{req.Priority, b.SetPriority},
+		{req.Milestone, b.SetMilestone},
+		{req.Identifier, b.SetIdentifier},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if err := f.set(f.value, s.config); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if err := b.SetDate(bugs.DateReported, bugs.Date{Time: time.Now()}, s.config); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ref := issueRef{ref: refForIssue(*b, issueRefs(s.config)), issue: *b}
+	w.Header().Set("Location", issueHref(ref.ref))
+	writeJSON(w, http.StatusCreated, newHalIssue(ref))
+}
+
+// readRequest decodes a json request body, writing an error when it fails.
+func readRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid json: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, serveError{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", halContentType)
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
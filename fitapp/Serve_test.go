@@ -0,0 +1,174 @@
+package fitapp
+
+import (
+	"encoding/json"
+	"errors"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func servetest(t *testing.T, srv *httptest.Server, method, path, body string, code int, v interface{}) {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != code {
+		out, _ := ioutil.ReadAll(resp.Body)
+		t.Errorf("%s %s: expected status %v, got %v %s", method, path, code, resp.StatusCode, out)
+		return
+	}
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Errorf("%s %s: could not decode json: %s", method, path, err.Error())
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "servetest")
+	if err != nil {
+		t.Error("Could not create temporary dir for test")
+		return
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	defer os.RemoveAll(dir)
+	err = os.Setenv("FIT", dir)
+	if err != nil {
+		t.Error("Could not set environment variable: " + err.Error())
+		return
+	}
+	srv := httptest.NewServer(serveHandler(config))
+	defer srv.Close()
+
+	type issue struct {
+		Identifier  string
+		Title       string
+		Description string
+		Status      string
+		Priority    string
+		Milestone   string
+		Tags        []string
+	}
+	var list struct {
+		Total    int `json:"total"`
+		Embedded struct {
+			Issues []issue `json:"issues"`
+		} `json:"_embedded"`
+	}
+	servetest(t, srv, "GET", "/issues", "", http.StatusOK, &list)
+	if list.Total != 0 {
+		t.Errorf("Expected no issues, got %+v", list)
+	}
+
+	var got issue
+	servetest(t, srv, "POST", "/issues", `{"Title": "Served bug", "Description": "over http", "Status": "open", "Identifier": "GitHub:u/r#1"}`, http.StatusCreated, &got)
+	if got.Title != "Served bug" || got.Description != "over http\n" || got.Status != "open" {
+		t.Errorf("Unexpected created issue %+v", got)
+	}
+	servetest(t, srv, "POST", "/issues", `{"Description": "no title"}`, http.StatusBadRequest, nil)
+	servetest(t, srv, "POST", "/issues", `{"Title": `, http.StatusBadRequest, nil)
+
+	servetest(t, srv, "GET", "/issues/GitHub:u%2Fr%231", "", http.StatusOK, &got)
+	if got.Identifier != "GitHub:u/r#1" {
+		t.Errorf("Unexpected identifier %+v", got)
+	}
+	servetest(t, srv, "PUT", "/issues/1/fields/priority", `{"Value": "high"}`, http.StatusOK, &got)
+	if got.Priority != "high" {
+		t.Errorf("Expected priority high, got %+v", got)
+	}
+	servetest(t, srv, "POST", "/issues/1/tags", `{"Value": "server"}`, http.StatusOK, &got)
+	if !strings.Contains(strings.Join(got.Tags, ","), "server") {
+		t.Errorf("Expected tag server, got %+v", got)
+	}
+	servetest(t, srv, "DELETE", "/issues/1/tags/server", "", http.StatusOK, &got)
+	if strings.Contains(strings.Join(got.Tags, ","), "server") {
+		t.Errorf("Expected tag server removed, got %+v", got)
+	}
+	// names that could be files outside the issue directory
+	servetest(t, srv, "PUT", "/issues/1/fields/..%2F..%2Fescaped", `{"Value": "x"}`, http.StatusBadRequest, nil)
+	servetest(t, srv, "GET", "/issues/1/fields/..%2F..%2Fescaped", "", http.StatusNotFound, nil)
+	servetest(t, srv, "PUT", "/issues/1/fields/due", `{"Value": "2030-01-02"}`, http.StatusOK, nil)
+	servetest(t, srv, "POST", "/issues/1/tags", `{"Value": "../../escaped"}`, http.StatusBadRequest, nil)
+	servetest(t, srv, "DELETE", "/issues/1/tags/..%2F..%2FDescription", "", http.StatusBadRequest, nil)
+	servetest(t, srv, "POST", "/issues", `{"Title": "Bad tag", "Tags": ["a/b"]}`, http.StatusBadRequest, nil)
+	for _, name := range []string{"Escaped", "escaped", "fit" + sops + "Escaped", "fit" + sops + "escaped", "fit" + sops + "Bad-tag"} {
+		if _, err := os.Stat(dir + sops + name); err == nil {
+			t.Errorf("Unexpected file %s written", name)
+		}
+	}
+	if b, err := bugs.LoadIssueByHeuristic("1", config); err != nil || b.Description() != "over http\n" {
+		t.Errorf("Unexpected issue after invalid requests %v", err)
+	}
+	servetest(t, srv, "POST", "/issues/1/comments", `{"Author": "me", "Body": "a comment"}`, http.StatusCreated, nil)
+	servetest(t, srv, "GET", "/issues", "", http.StatusOK, &list)
+	if list.Total != 1 || list.Embedded.Issues[0].Title != "Served bug" {
+		t.Errorf("Expected 1 issue, got %+v", list)
+	}
+	servetest(t, srv, "GET", "/issues/99", "", http.StatusNotFound, nil)
+	servetest(t, srv, "GET", "/nothing", "", http.StatusNotFound, nil)
+
+	servetest(t, srv, "POST", "/issues/1/close", "", http.StatusNoContent, nil)
+	servetest(t, srv, "GET", "/issues", "", http.StatusOK, &list)
+	if list.Total != 0 {
+		t.Errorf("Expected closed issue removed, got %+v", list)
+	}
+	os.Chdir(pwd)
+}
+
+// failingStore fails writing files whose path contains fail.
+type failingStore struct {
+	bugs.OSStore
+	fail string
+}
+
+func (s failingStore) WriteFile(path string, data []byte) error {
+	if strings.Contains(path, s.fail) {
+		return errors.New("Could not write " + path)
+	}
+	return s.OSStore.WriteFile(path, data)
+}
+
+func TestServeWriteErrors(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "servetest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer func() {
+		os.Chdir(pwd)
+		os.RemoveAll(dir)
+	}()
+	os.MkdirAll(config.FitDirName, 0700)
+	os.Setenv("FIT", dir)
+	srv := httptest.NewServer(serveHandler(config))
+	defer srv.Close()
+
+	old := bugs.SetStore(failingStore{fail: sops + "Status"})
+	defer bugs.SetStore(old)
+	servetest(t, srv, "POST", "/issues", `{"Title": "Half written", "Status": "open"}`, http.StatusInternalServerError, nil)
+	bugs.SetStore(failingStore{fail: sops + "tags" + sops})
+	servetest(t, srv, "POST", "/issues", `{"Title": "Tagged", "Tags": ["cli"]}`, http.StatusInternalServerError, nil)
+	bugs.SetStore(failingStore{fail: sops + "Reported"})
+	servetest(t, srv, "POST", "/issues", `{"Title": "Dated"}`, http.StatusInternalServerError, nil)
+	bugs.SetStore(failingStore{fail: sops + "tags" + sops})
+	servetest(t, srv, "POST", "/issues/1/tags", `{"Value": "server"}`, http.StatusInternalServerError, nil)
+}
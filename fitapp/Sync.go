@@ -0,0 +1,44 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"os"
+	"strings"
+)
+
+// Sync is a subcommand to push local changes of imported issues back to
+// the system they were imported from.
+func Sync(args argumentList, config bugs.Config) {
+	force := false
+	var rest argumentList
+	for _, arg := range args {
+		if arg == "--force" {
+			force = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	args, values := rest.GetAndRemoveArguments([]string{"--github"})
+	if values[0] == "" {
+		fmt.Fprintf(os.Stderr, "Usage: %s sync --github [<user>/<repo>] [--force]\n", os.Args[0])
+		return
+	}
+	githubRepo := values[0]
+	if githubRepo == "true" {
+		githubRepo = ""
+		if len(args) > 0 {
+			githubRepo = args[0]
+		}
+	}
+	if githubRepo == "" {
+		githubSyncIssues("", "", force, config)
+		return
+	}
+	pieces := strings.Split(githubRepo, "/")
+	if len(pieces) != 2 || pieces[0] == "" || pieces[1] == "" {
+		fmt.Fprintf(os.Stderr, "GitHub invalid: %s\n", githubRepo)
+		return
+	}
+	githubSyncIssues(pieces[0], pieces[1], force, config)
+}
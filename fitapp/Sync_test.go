@@ -0,0 +1,114 @@
+package fitapp
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSyncGithub(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	config.CloseStatusTag = true
+	dir, err := ioutil.TempDir("", "synctest")
+	if err != nil {
+		t.Error("Could not create temporary dir for test")
+		return
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	defer os.RemoveAll(dir)
+	err = os.Setenv("FIT", dir)
+	if err != nil {
+		t.Error("Could not set environment variable: " + err.Error())
+		return
+	}
+
+	api := &githubTestAPI{
+		issues: []map[string]interface{}{
+			{"number": 1, "title": "First bug", "body": "first", "comments": 1, "state": "open",
+				"labels": []map[string]interface{}{{"name": "cli"}}},
+			{"number": 2, "title": "Second bug", "body": "second", "comments": 0, "state": "open"},
+		},
+		comments: map[string][]map[string]interface{}{
+			"1": {githubTestComment("looks bad")},
+		},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	config.GithubApiUrl = srv.URL
+
+	captureOutput(func() {
+		githubImportIssues("u", "r", config)
+	}, t)
+	var counts githubSyncCounts
+	captureOutput(func() {
+		counts = githubSyncIssues("u", "r", false, config)
+	}, t)
+	if counts != (githubSyncCounts{Unchanged: 2}) {
+		t.Errorf("Unexpected sync counts without changes %+v", counts)
+	}
+
+	captureOutput(func() {
+		Relabel(argumentList{"GitHub:u/r#1", "First", "bug", "retitled", "1"}, config)
+	}, t)
+	b, err := bugs.LoadIssueByHeuristic("GitHub:u/r#1", config)
+	if err != nil {
+		t.Fatalf("Could not load issue: %s", err.Error())
+	}
+	b.SetDescription("first, edited", config)
+	b.RemoveTag(bugs.TagBoolTrue("cli"), config)
+	b.TagIssue(bugs.TagBoolTrue("docs"), config)
+	b.SetMilestone("v2.0", config)
+	b.CommentIssue(bugs.Comment{Body: "fixed locally"}, config)
+	b.SetStatus("closed", config)
+	// issue 2 changes on both sides
+	b2, _ := bugs.LoadIssueByHeuristic("GitHub:u/r#2", config)
+	b2.SetDescription("second, local", config)
+	api.issues[1]["body"] = "second, remote"
+	api.touch(api.issues[1])
+
+	_, stderr := captureOutput(func() {
+		counts = githubSyncIssues("u", "r", false, config)
+	}, t)
+	if counts != (githubSyncCounts{Pushed: 1, Conflicts: 1}) {
+		t.Errorf("Unexpected sync counts %+v", counts)
+	}
+	if !strings.Contains(stderr, "Conflict: GitHub:u/r#2") {
+		t.Errorf("Expected a conflict for issue 2, got %s", stderr)
+	}
+	issue := api.issues[0]
+	labels := issue["labels"].([]map[string]interface{})
+	milestone, _ := issue["milestone"].(map[string]interface{})
+	if issue["title"] != "First bug retitled" || issue["body"] != "first, edited" ||
+		len(labels) != 1 || labels[0]["name"] != "docs" || milestone["title"] != "v2.0" ||
+		issue["state"] != "closed" || len(api.comments["1"]) != 2 ||
+		api.comments["1"][1]["body"] != "fixed locally" {
+		t.Errorf("Unexpected issue on GitHub %v %v", issue, api.comments["1"])
+	}
+	if api.issues[1]["body"] != "second, remote" {
+		t.Errorf("Conflicting issue was pushed: %v", api.issues[1])
+	}
+
+	stdout, _ := captureOutput(func() {
+		Sync(argumentList{"--github", "u/r", "--force"}, config)
+	}, t)
+	if !strings.Contains(stdout, "GitHub sync: 1 pushed, 1 unchanged, 0 conflicts") {
+		t.Errorf("Unexpected forced sync output %s", stdout)
+	}
+	if api.issues[1]["body"] != "second, local" {
+		t.Errorf("Forced sync did not push issue 2: %v", api.issues[1])
+	}
+	stdout, _ = captureOutput(func() {
+		Sync(argumentList{"--github"}, config)
+	}, t)
+	if !strings.Contains(stdout, "GitHub sync: 0 pushed, 2 unchanged, 0 conflicts") {
+		t.Errorf("Unexpected sync output after sync %s", stdout)
+	}
+	os.Chdir(pwd)
+}
@@ -0,0 +1,152 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"os"
+	"strings"
+)
+
+// watchUsage prints how to use the watch and unwatch subcommands.
+func watchUsage(command string) {
+	fmt.Printf("Usage: %s %s <IssueID> [--to <channel:address>]\n", os.Args[0], command)
+	fmt.Printf("       %s %s --query <query> [--to <channel:address>]\n", os.Args[0], command)
+	if command == "watch" {
+		fmt.Printf("       %s watch [--list <IssueID>]\n", os.Args[0])
+	}
+	fmt.Printf("\nRecipients are like email:alice@example.com, sms:+14155551212 or\n")
+	fmt.Printf("webhook:https://example.com/hook, the email of the user by default\n")
+}
+
+// watchRecipient returns the recipient of --to, or the email address of
+// the user from the git or hg configuration.
+func watchRecipient(to string, config bugs.Config) (string, error) {
+	if to != "" {
+		r, ok := parseRecipient(to)
+		if !ok || to != r.String() {
+			return "", fmt.Errorf("Invalid recipient %s, use email:, sms: or webhook: before the address", to)
+		}
+		return r.String(), nil
+	}
+	author := commentAuthor(config)
+	if i, j := strings.Index(author, "<"), strings.LastIndex(author, ">"); i >= 0 && j > i {
+		author = author[i+1 : j]
+	}
+	if !strings.Contains(author, "@") {
+		return "", fmt.Errorf("No email address of the user, use --to <channel:address>")
+	}
+	return ChannelEmail + ":" + strings.TrimSpace(author), nil
+}
+
+// listWatches prints the watchers of the issue of args, or without one
+// the watchers of every issue and the watches of queries.
+func listWatches(args argumentList, config bugs.Config) {
+	if len(args) == 1 {
+		b, err := bugs.LoadIssueByHeuristic(args[0], config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+			return
+		}
+		watchers := b.Watchers()
+		if len(watchers) == 0 {
+			fmt.Printf("No watchers\n")
+		}
+		for _, w := range watchers {
+			fmt.Printf("%s\n", w)
+		}
+		return
+	}
+	found := false
+	for idx, r := range bugs.CachedIssues(config) {
+		b := r.Issue(config)
+		if watchers := b.Watchers(); len(watchers) > 0 {
+			fmt.Printf("%s: %s: %s\n", issueNamer(b, idx), r.Title, strings.Join(watchers, ", "))
+			found = true
+		}
+	}
+	for _, w := range bugs.QueryWatches(config) {
+		fmt.Printf("Query %s: %s\n", w.Query, w.Recipient)
+		found = true
+	}
+	if !found {
+		fmt.Printf("No watchers\n")
+	}
+}
+
+// Watch is a subcommand to notify a recipient of the changes of an issue
+// or of the issues matching a query.
+func Watch(args argumentList, config bugs.Config) {
+	watch(args, false, config)
+}
+
+// Unwatch is a subcommand to stop notifying a recipient of the changes of
+// an issue or of the issues matching a query.
+func Unwatch(args argumentList, config bugs.Config) {
+	watch(args, true, config)
+}
+
+// watch adds or with remove removes a watcher of an issue or a query.
+func watch(args argumentList, remove bool, config bugs.Config) {
+	command := "watch"
+	if remove {
+		command = "unwatch"
+	}
+	args, list := args.RemoveFlag("--list")
+	args, values := args.GetAndRemoveArguments([]string{"--query", "--to"})
+	query, to := values[0], values[1]
+	if query == "true" || to == "true" || len(args) > 1 || query != "" && len(args) != 0 ||
+		list && (remove || query != "" || to != "") {
+		watchUsage(command)
+		return
+	}
+	if list || !remove && query == "" && len(args) == 0 {
+		listWatches(args, config)
+		return
+	}
+	if query == "" && len(args) == 0 {
+		watchUsage(command)
+		return
+	}
+	recipient, err := watchRecipient(to, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	if query != "" {
+		w := bugs.QueryWatch{Recipient: recipient, Query: query}
+		if remove {
+			err = bugs.RemoveQueryWatch(w, config)
+		} else {
+			err = bugs.AddQueryWatch(w, config)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+			return
+		}
+		if remove {
+			fmt.Printf("%s stopped watching %s\n", recipient, query)
+		} else {
+			fmt.Printf("%s watches %s\n", recipient, query)
+		}
+		return
+	}
+	b, err := bugs.LoadIssueByHeuristic(args[0], config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load issue: %s\n", err.Error())
+		return
+	}
+	if remove {
+		err = b.Unwatch(recipient, config)
+	} else {
+		err = b.Watch(recipient, config)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	if remove {
+		fmt.Printf("%s stopped watching %s\n", recipient, b.Title(""))
+	} else {
+		fmt.Printf("%s watches %s\n", recipient, b.Title(""))
+	}
+}
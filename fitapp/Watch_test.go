@@ -0,0 +1,68 @@
+package fitapp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWatch(t *testing.T) {
+	config, commit, done := setupNotifyRepo(t) // from Notify_test.go
+	defer done()
+
+	stdout, stderr := captureOutput(func() {
+		Watch(argumentList{"LOGIN"}, config)
+	}, t)
+	if stdout != "email:tester@example.com watches Login fails\n" || stderr != "" {
+		t.Errorf("Unexpected output watching: %s %s", stdout, stderr)
+	}
+	stdout, stderr = captureOutput(func() {
+		Watch(argumentList{"SEARCH", "--to", "sms:+15550001"}, config)
+		Watch(argumentList{"--query", "status:closed", "--to", "webhook:https://example.com/hook"}, config)
+		Watch(argumentList{"SEARCH", "--to", "fax:123"}, config)
+	}, t)
+	if !strings.HasSuffix(stdout, "webhook:https://example.com/hook watches status:closed\n") ||
+		!strings.HasPrefix(stderr, "Error: Invalid recipient fax:123") {
+		t.Errorf("Unexpected output watching: %s %s", stdout, stderr)
+	}
+	stdout, _ = captureOutput(func() {
+		Watch(argumentList{"--list", "SEARCH"}, config)
+	}, t)
+	if stdout != "sms:+15550001\n" {
+		t.Errorf("Unexpected watchers of an issue: %s", stdout)
+	}
+	stdout, _ = captureOutput(func() {
+		Watch(argumentList{}, config)
+	}, t)
+	if !strings.Contains(stdout, ": Login fails: email:tester@example.com\n") ||
+		!strings.Contains(stdout, ": Slow search: sms:+15550001\n") ||
+		!strings.HasSuffix(stdout, "Query status:closed: webhook:https://example.com/hook\n") {
+		t.Errorf("Unexpected watchers: %s", stdout)
+	}
+	commit("Watch issues")
+
+	captureOutput(func() {
+		Status(argumentList{"LOGIN", "closed"}, config)
+	}, t)
+	stdout, _ = captureOutput(func() {
+		Notify(argumentList{"--dry-run"}, config)
+	}, t)
+	for _, to := range []string{"email:tester@example.com", "webhook:https://example.com/hook"} {
+		if !strings.Contains(stdout, "To: "+to+"\nSubject: Closed: Login fails\n") {
+			t.Errorf("Expected a notification to %s: %s", to, stdout)
+		}
+	}
+	if strings.Contains(stdout, "sms:+15550001") || strings.Contains(stdout, "tester@example.com watches") {
+		t.Errorf("Unexpected notification of an issue not watched: %s", stdout)
+	}
+
+	stdout, stderr = captureOutput(func() {
+		Unwatch(argumentList{"LOGIN"}, config)
+		Unwatch(argumentList{"--query", "status:closed", "--to", "webhook:https://example.com/hook"}, config)
+		Unwatch(argumentList{"LOGIN"}, config)
+	}, t)
+	if stdout != "email:tester@example.com stopped watching Login fails\n"+
+		"webhook:https://example.com/hook stopped watching status:closed\n" ||
+		!strings.HasPrefix(stderr, "Error: email:tester@example.com does not watch") {
+		t.Errorf("Unexpected output unwatching: %s %s", stdout, stderr)
+	}
+}
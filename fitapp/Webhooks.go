@@ -0,0 +1,313 @@
+package fitapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// webhookClient posts webhook events, a receiver not answering does not
+// hold up a commit for long.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// webhookQueueFile is the file in the .git or .hg directory with the
+// webhook events not delivered yet.
+const webhookQueueFile = "fit-webhooks.json"
+
+// webhookMaxBackoff is the longest wait before retrying an event.
+const webhookMaxBackoff = 24 * time.Hour
+
+// WebhookEvent is the json body posted to WebhookUrls for a committed
+// change of an issue. Id is the same for every url, so receivers can
+// recognize events delivered again.
+type WebhookEvent struct {
+	Id       string
+	Event    string
+	Revision string
+	Site     string
+	Time     string
+	Change   outputRecord
+}
+
+// queuedWebhook is an event for a url waiting in the queue file. Body is
+// the json that is signed and posted.
+type queuedWebhook struct {
+	Url         string
+	Id          string
+	Event       string
+	Body        string
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+}
+
+// webhookBackoff returns the wait before the next attempt after a number
+// of failed attempts, a minute doubled after each up to a day.
+func webhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	if attempts > 11 {
+		return webhookMaxBackoff
+	}
+	if d := time.Minute << uint(attempts-1); d < webhookMaxBackoff {
+		return d
+	}
+	return webhookMaxBackoff
+}
+
+// signWebhook returns the X-Fit-Signature-256 header of a body, the hex
+// HMAC-SHA256 of the body with the secret after sha256=.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEvents returns the events of the changes of a commit.
+func webhookEvents(rev string, changes []bugs.IssueChange, config bugs.Config) []WebhookEvent {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var events []WebhookEvent
+	for _, c := range changes {
+		sum := sha256.Sum256([]byte(rev + "\x00" + c.Kind + "\x00" + c.Name))
+		events = append(events, WebhookEvent{
+			Id:       hex.EncodeToString(sum[:10]),
+			Event:    "issue." + c.Kind,
+			Revision: rev,
+			Site:     config.FitSite,
+			Time:     now,
+			Change:   diffRecord(c),
+		})
+	}
+	return events
+}
+
+// webhookUrls returns the urls of WebhookUrls.
+func webhookUrls(config bugs.Config) []string {
+	var urls []string
+	for _, u := range strings.Split(config.WebhookUrls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// readWebhookQueue returns the events of the queue file. A queue file
+// that can not be parsed is moved aside to a .corrupt file and reported,
+// so new events are still queued.
+func readWebhookQueue(file string) ([]queuedWebhook, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var queue []queuedWebhook
+	if err := json.Unmarshal(data, &queue); err != nil {
+		if rerr := os.Rename(file, file+".corrupt"); rerr != nil {
+			return nil, fmt.Errorf("Could not read %s: %s", file, err.Error())
+		}
+		fmt.Fprintf(os.Stderr, "Error: Could not read %s: %s, moved it to %s.corrupt\n", file, err.Error(), file)
+		return nil, nil
+	}
+	return queue, nil
+}
+
+// writeWebhookQueue replaces the queue file with the events, or removes
+// it without any. The events are written to a temporary file renamed
+// over the queue, so an interrupted write does not lose the queue.
+func writeWebhookQueue(file string, queue []queuedWebhook) error {
+	if len(queue) == 0 {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), webhookQueueFile+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// deliverWebhook posts a queued event, signed when there is a secret.
+func deliverWebhook(q queuedWebhook, secret string) error {
+	req, err := http.NewRequest("POST", q.Url, strings.NewReader(q.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Fit-Event", q.Event)
+	req.Header.Set("X-Fit-Delivery", q.Id)
+	if secret != "" {
+		req.Header.Set("X-Fit-Signature-256", signWebhook(secret, []byte(q.Body)))
+	}
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s answered %s", q.Url, resp.Status)
+	}
+	return nil
+}
+
+// deliverWebhooks posts the events of the queue due at now, or every
+// event with all, and returns the events left in the queue with their
+// next attempt put off.
+func deliverWebhooks(queue []queuedWebhook, all bool, now time.Time, config bugs.Config) []queuedWebhook {
+	var left []queuedWebhook
+	for _, q := range queue {
+		if !all && q.NextAttempt.After(now) {
+			left = append(left, q)
+			continue
+		}
+		if err := deliverWebhook(q, config.WebhookSecret); err != nil {
+			q.Attempts++
+			q.NextAttempt = now.Add(webhookBackoff(q.Attempts))
+			q.LastError = err.Error()
+			fmt.Fprintf(os.Stderr, "Error: delivering %s %s to %s: %s, retrying after %s\n",
+				q.Event, q.Id, q.Url, q.LastError, q.NextAttempt.Local().Format("2006-01-02 15:04"))
+			left = append(left, q)
+			continue
+		}
+		fmt.Printf("Delivered %s %s to %s\n", q.Event, q.Id, q.Url)
+	}
+	return left
+}
+
+// sendWebhooks queues the events of the changes of the commit rev for
+// every url of WebhookUrls, then delivers the events due in the queue
+// file in the scm directory.
+func sendWebhooks(handler scm.SCMHandler, scmdir bugs.Directory, rev string, config bugs.Config) error {
+	urls := webhookUrls(config)
+	if len(urls) == 0 {
+		return fmt.Errorf("No WebhookUrls in the config")
+	}
+	rev, changes, err := scm.CommitIssues(handler, rev, config)
+	if err != nil {
+		return err
+	}
+	file := string(scmdir) + sops + webhookQueueFile
+	queue, err := readWebhookQueue(file)
+	if err != nil {
+		return err
+	}
+	for _, e := range webhookEvents(rev, changes, config) {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		for _, u := range urls {
+			queue = append(queue, queuedWebhook{Url: u, Id: e.Id, Event: e.Event, Body: string(body)})
+		}
+	}
+	return writeWebhookQueue(file, deliverWebhooks(queue, false, time.Now(), config))
+}
+
+// webhooksUsage prints how to use the webhooks subcommand.
+func webhooksUsage() {
+	fmt.Printf("Usage: %s webhooks [list]\n", os.Args[0])
+	fmt.Printf("       %s webhooks send [<revision>]\n", os.Args[0])
+	fmt.Printf("       %s webhooks retry [--all]\n", os.Args[0])
+}
+
+// Webhooks is a subcommand to post the issue changes of a commit to
+// WebhookUrls, from a post-commit hook, and to list and retry the events
+// that were not delivered.
+func Webhooks(args argumentList, config bugs.Config) {
+	handler, scmdir, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		return
+	}
+	file := string(scmdir) + sops + webhookQueueFile
+	command := "list"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "send":
+		if len(args) > 1 {
+			webhooksUsage()
+			return
+		}
+		if os.Getenv("FIT_COMMIT") != "" {
+			// fit commit sends the events after committing
+			return
+		}
+		rev := "HEAD"
+		if handler.SCMTyper() == "hg" {
+			rev = "."
+		}
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		if err := sendWebhooks(handler, scmdir, rev, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		}
+	case "retry":
+		args, all := args.RemoveFlag("--all")
+		if len(args) != 0 {
+			webhooksUsage()
+			return
+		}
+		queue, err := readWebhookQueue(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+			return
+		}
+		if len(queue) == 0 {
+			fmt.Printf("No undelivered events\n")
+			return
+		}
+		if err := writeWebhookQueue(file, deliverWebhooks(queue, all, time.Now(), config)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		}
+	case "list":
+		if len(args) != 0 {
+			webhooksUsage()
+			return
+		}
+		queue, err := readWebhookQueue(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+			return
+		}
+		if len(queue) == 0 {
+			fmt.Printf("No undelivered events\n")
+		}
+		for _, q := range queue {
+			fmt.Printf("%s %s to %s, %d attempts, next %s: %s\n", q.Event, q.Id, q.Url, q.Attempts,
+				q.NextAttempt.Local().Format("2006-01-02 15:04"), q.LastError)
+		}
+	default:
+		webhooksUsage()
+	}
+}
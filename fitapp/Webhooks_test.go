@@ -0,0 +1,136 @@
+package fitapp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	for attempts, expected := range map[int]time.Duration{
+		0:   0,
+		1:   time.Minute,
+		2:   2 * time.Minute,
+		5:   16 * time.Minute,
+		11:  1024 * time.Minute,
+		12:  webhookMaxBackoff,
+		100: webhookMaxBackoff,
+	} {
+		if d := webhookBackoff(attempts); d != expected {
+			t.Errorf("Unexpected backoff after %d attempts: %s", attempts, d)
+		}
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	config, _, done := setupNotifyRepo(t) // from Notify_test.go
+	defer done()
+
+	var mu sync.Mutex
+	failing := true
+	var events []WebhookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("X-Fit-Signature-256") != signWebhook("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if failing && r.URL.Path == "/flaky" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var e WebhookEvent
+		json.Unmarshal(body, &e)
+		if r.Header.Get("X-Fit-Event") != e.Event || r.Header.Get("X-Fit-Delivery") != e.Id {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events = append(events, e)
+	}))
+	defer srv.Close()
+	config.WebhookUrls = srv.URL + "/hook, " + srv.URL + "/flaky"
+	config.WebhookSecret = "s3cret"
+
+	captureOutput(func() {
+		Status(argumentList{"LOGIN", "closed"}, config)
+	}, t)
+	stdout, stderr := captureOutput(func() {
+		Commit(argumentList{}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "Delivered issue.closed ") || !strings.HasSuffix(stdout, " to "+srv.URL+"/hook\n") ||
+		!strings.Contains(stderr, "/flaky answered 503") {
+		t.Errorf("Unexpected output of commit: %s %s", stdout, stderr)
+	}
+	if len(events) != 1 || events[0].Event != "issue.closed" || len(events[0].Revision) != 40 ||
+		events[0].Change["Identifier"] != "LOGIN" {
+		t.Errorf("Unexpected events %+v", events)
+	}
+	queue, err := readWebhookQueue(".git" + sops + webhookQueueFile)
+	if err != nil || len(queue) != 1 || queue[0].Attempts != 1 || queue[0].Id != events[0].Id ||
+		queue[0].NextAttempt.Before(time.Now()) {
+		t.Errorf("Unexpected queue %+v %v", queue, err)
+	}
+	if tmp, _ := filepath.Glob(".git" + sops + webhookQueueFile + ".tmp*"); len(tmp) != 0 {
+		t.Errorf("Temporary queue files left %v", tmp)
+	}
+
+	stdout, _ = captureOutput(func() {
+		Webhooks(argumentList{}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "issue.closed "+events[0].Id+" to "+srv.URL+"/flaky, 1 attempts") {
+		t.Errorf("Unexpected list of the queue: %s", stdout)
+	}
+	failing = false
+	stdout, _ = captureOutput(func() {
+		Webhooks(argumentList{"retry"}, config)
+	}, t)
+	if stdout != "" || len(events) != 1 {
+		t.Errorf("Unexpected retry before the backoff: %s", stdout)
+	}
+	stdout, stderr = captureOutput(func() {
+		Webhooks(argumentList{"retry", "--all"}, config)
+	}, t)
+	if stdout != "Delivered issue.closed "+events[0].Id+" to "+srv.URL+"/flaky\n" || stderr != "" || len(events) != 2 {
+		t.Errorf("Unexpected retry: %s %s", stdout, stderr)
+	}
+	if _, err := os.Stat(".git" + sops + webhookQueueFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the queue file to be removed")
+	}
+
+	// the first commit creates every issue
+	stdout, _ = captureOutput(func() {
+		Webhooks(argumentList{"send", "HEAD~1"}, config)
+	}, t)
+	if strings.Count(stdout, "Delivered issue.created ") != 4 {
+		t.Errorf("Unexpected events of the first commit: %s", stdout)
+	}
+
+	// a queue file that can not be parsed is moved aside
+	ioutil.WriteFile(".git"+sops+webhookQueueFile, []byte("[{\"Url\": "), 0600)
+	stdout, stderr = captureOutput(func() {
+		Webhooks(argumentList{"send", "HEAD~1"}, config)
+	}, t)
+	if strings.Count(stdout, "Delivered issue.created ") != 4 || !strings.Contains(stderr, "moved it to ") {
+		t.Errorf("Unexpected send with a corrupt queue: %s %s", stdout, stderr)
+	}
+	if data, _ := ioutil.ReadFile(".git" + sops + webhookQueueFile + ".corrupt"); string(data) != "[{\"Url\": " {
+		t.Errorf("Corrupt queue not kept: %q", data)
+	}
+	os.Setenv("FIT_COMMIT", "1")
+	defer os.Unsetenv("FIT_COMMIT")
+	stdout, _ = captureOutput(func() {
+		Webhooks(argumentList{"send"}, config)
+	}, t)
+	if stdout != "" {
+		t.Errorf("Unexpected events sent during fit commit: %s", stdout)
+	}
+}
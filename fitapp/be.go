@@ -1,13 +1,16 @@
 package fitapp
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
 	"io/ioutil"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
@@ -40,6 +43,17 @@ func beImportComments(b bugs.Issue, directory string, includeHeaders bool) strin
 	return "---------- Comment ---------\nFrom:" + beComment.Author + "\nDate:" + beComment.Date + "\n\n" + string(data)
 }
 
+// beCommentDate returns the Date of the values of a comment directory.
+func beCommentDate(directory string) time.Time {
+	var values struct {
+		Date string `json:"Date"`
+	}
+	data, _ := ioutil.ReadFile(directory + sops + "values")
+	json.Unmarshal(data, &values)
+	t, _ := time.Parse(time.RFC1123Z, values.Date)
+	return t
+}
+
 func beImportIssue(identifier, issuesDir, fullbepath string, config bugs.Config) {
 	/* BE appears to store the top level data of a bug
 	   in a json file with the format:
@@ -82,7 +96,7 @@ func beImportIssue(identifier, issuesDir, fullbepath string, config bugs.Config)
 
 	bugdir := bugs.TitleToDir(beIssue.Summary)
 
-	b := bugs.Issue{Dir: bugs.Directory(issuesDir) + bugdir}
+	b := bugs.Issue{Dir: bugs.Directory(strings.TrimSuffix(issuesDir, sops)) + dops + bugdir}
 	if dir := b.Direr(); dir != "" {
 		os.Mkdir(string(dir), 0755)
 	}
@@ -94,6 +108,10 @@ func beImportIssue(identifier, issuesDir, fullbepath string, config bugs.Config)
 	dir, err := os.Open(comments)
 
 	files, err := dir.Readdir(-1)
+	// oldest comment first, as written by beExport
+	sort.SliceStable(files, func(i, j int) bool {
+		return beCommentDate(comments + files[i].Name()).Before(beCommentDate(comments + files[j].Name()))
+	})
 	var DescriptionStr string
 	if len(files) > 0 && err == nil {
 		for _, file := range files {
@@ -220,3 +238,212 @@ func shortestPrefix(name, nextIdentifier, lastIdentifier string, min int) string
 	}
 	return name
 }
+
+// beCommentHeader starts each comment of a Description imported from BE
+// with more than one comment, see beImportComments.
+const beCommentHeader = "---------- Comment ---------\n"
+
+// beStatuses are the status values BE knows, other fit statuses are
+// mapped by beStatusMap or become open.
+var beStatuses = []string{"unconfirmed", "open", "assigned", "test", "closed", "fixed", "wontfix", "disabled"}
+
+var beStatusMap = map[string]string{
+	"new":         "unconfirmed",
+	"in progress": "assigned",
+	"started":     "assigned",
+	"testing":     "test",
+	"done":        "fixed",
+	"resolved":    "fixed",
+	"won't fix":   "wontfix",
+	"invalid":     "wontfix",
+	"duplicate":   "closed",
+}
+
+// beSeverities are the severity values BE knows, from least to most severe.
+var beSeverities = []string{"wishlist", "minor", "serious", "critical", "fatal"}
+
+var beSeverityMap = map[string]string{
+	"low":     "minor",
+	"normal":  "minor",
+	"medium":  "serious",
+	"major":   "serious",
+	"high":    "critical",
+	"urgent":  "fatal",
+	"blocker": "fatal",
+}
+
+// beStatusSeverity maps the Status and Priority of an issue to a BE status
+// and severity. Imported issues have a Status of status:severity.
+func beStatusSeverity(b bugs.Issue) (string, string) {
+	status := strings.ToLower(strings.TrimSpace(b.Status()))
+	severity := ""
+	if i := strings.Index(status, ":"); i != -1 {
+		status, severity = status[:i], status[i+1:]
+	}
+	if mapped, ok := beStatusMap[status]; ok {
+		status = mapped
+	} else if !findString(beStatuses, status) {
+		status = "open"
+	}
+
+	if priority := strings.ToLower(strings.TrimSpace(b.Priority())); priority != "" {
+		severity = priority
+		// integer priorities, higher is more urgent
+		if n, err := strconv.Atoi(priority); err == nil {
+			if n < 0 {
+				n = 0
+			} else if n >= len(beSeverities) {
+				n = len(beSeverities) - 1
+			}
+			severity = beSeverities[n]
+		}
+	}
+	if mapped, ok := beSeverityMap[severity]; ok {
+		severity = mapped
+	} else if !findString(beSeverities, severity) {
+		severity = "minor"
+	}
+	return status, severity
+}
+
+func findString(arr []string, s string) bool {
+	for _, a := range arr {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
+
+// beUUID returns a name based (version 5) uuid so exporting again writes
+// the same directories.
+func beUUID(name string) string {
+	// the uuid url namespace 6ba7b811-9dad-11d1-80b4-00c04fd430c8
+	namespace := []byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	h := sha1.Sum(append(namespace, []byte(name)...))
+	h[6] = (h[6] & 0x0f) | 0x50
+	h[8] = (h[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// beDescriptionComments splits a Description into BE comments. Comments
+// imported from BE keep the author and date of their headers, other text
+// is one comment by author at t.
+func beDescriptionComments(description, author string, t time.Time) []bugs.Comment {
+	comments := []bugs.Comment{}
+	for _, chunk := range strings.Split(description, "\n"+beCommentHeader) {
+		c := bugs.Comment{Author: author, Time: t, Body: chunk}
+		if strings.HasPrefix(chunk, "From:") {
+			if i := strings.Index(chunk, "\n\n"); i != -1 {
+				for _, line := range strings.Split(chunk[:i], "\n") {
+					if strings.HasPrefix(line, "From:") {
+						c.Author = strings.TrimPrefix(line, "From:")
+					} else if d, err := time.Parse(time.RFC1123Z, strings.TrimPrefix(line, "Date:")); err == nil {
+						c.Time = d
+					}
+				}
+				c.Body = chunk[i+2:]
+			}
+		}
+		c.Body = strings.Trim(c.Body, "\n")
+		if c.Body != "" {
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
+
+// beWriteJSON writes values as BE does, indented json with sorted keys.
+func beWriteJSON(file string, values map[string]interface{}) error {
+	data, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// beExport writes the issues of the fit directory, or those matching query
+// when it is not nil, as a BugsEverywhere repository in dir/.be and
+// returns the number of issues written. The Description and comments of
+// an issue become BE comments, oldest first.
+func beExport(dir string, query bugs.Query, config bugs.Config) (int, error) {
+	bedir := dir + sops + ".be"
+	if err := os.MkdirAll(bedir, 0755); err != nil {
+		return 0, err
+	}
+	if err := ioutil.WriteFile(bedir+sops+"version", []byte("Bugs Everywhere Directory v1.4\n"), 0644); err != nil {
+		return 0, err
+	}
+	// the bug directory is replaced by each export
+	bugdir := bedir + sops + beUUID("fit:"+config.FitDirName)
+	if err := os.RemoveAll(bugdir); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(bugdir+sops+"bugs", 0755); err != nil {
+		return 0, err
+	}
+	if err := beWriteJSON(bugdir+sops+"settings", map[string]interface{}{}); err != nil {
+		return 0, err
+	}
+
+	user := os.Getenv("USER")
+	issues := bugs.GetAllIssues(config)
+	if query != nil {
+		issues = bugs.FindIssuesByQuery(query, config)
+	}
+	for _, b := range issues {
+		name := b.Identifier()
+		if name == "" {
+			name = string(b.Dir.ShortNamer())
+		}
+		bugpath := bugdir + sops + "bugs" + sops + beUUID(name)
+		created := b.Dir.ModTime().UTC()
+		if stat, err := os.Stat(string(b.Dir) + sops + config.DescriptionFileName); err == nil {
+			created = stat.ModTime().UTC()
+		}
+		comments := beDescriptionComments(b.Description(), user, created)
+		creator := user
+		if len(comments) > 0 {
+			creator = comments[0].Author
+			created = comments[0].Time
+		}
+		for _, c := range b.Comments() {
+			if c.Author == "" {
+				c.Author = user
+			}
+			comments = append(comments, c)
+		}
+
+		status, severity := beStatusSeverity(b)
+		if err := os.MkdirAll(bugpath+sops+"comments", 0755); err != nil {
+			return 0, err
+		}
+		if err := beWriteJSON(bugpath+sops+"values", map[string]interface{}{
+			"creator":  creator,
+			"reporter": creator,
+			"severity": severity,
+			"status":   status,
+			"summary":  b.Title(""),
+			"time":     created.Format(time.RFC1123Z),
+		}); err != nil {
+			return 0, err
+		}
+		for i, c := range comments {
+			commentpath := bugpath + sops + "comments" + sops + beUUID(name+"/"+strconv.Itoa(i+1))
+			if err := os.Mkdir(commentpath, 0755); err != nil {
+				return 0, err
+			}
+			if err := beWriteJSON(commentpath+sops+"values", map[string]interface{}{
+				"Author":       c.Author,
+				"Content-type": "text/plain",
+				"Date":         c.Time.UTC().Format(time.RFC1123Z),
+			}); err != nil {
+				return 0, err
+			}
+			if err := ioutil.WriteFile(commentpath+sops+"body", []byte(c.Body+"\n"), 0644); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(issues), nil
+}
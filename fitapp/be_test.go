@@ -1 +1,159 @@
 package fitapp
+
+import (
+	"encoding/json"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+// beTestComments reads the comments of the exported bug with summary.
+func beTestComments(t *testing.T, bugsdir, summary string) (map[string]string, []bugs.Comment) {
+	dirs, _ := ioutil.ReadDir(bugsdir)
+	for _, d := range dirs {
+		var values map[string]string
+		data, _ := ioutil.ReadFile(bugsdir + sops + d.Name() + sops + "values")
+		json.Unmarshal(data, &values)
+		if values["summary"] != summary {
+			continue
+		}
+		comments := []bugs.Comment{}
+		commentsdir := bugsdir + sops + d.Name() + sops + "comments"
+		cdirs, _ := ioutil.ReadDir(commentsdir)
+		for _, c := range cdirs {
+			body, _ := ioutil.ReadFile(commentsdir + sops + c.Name() + sops + "body")
+			comments = append(comments, bugs.Comment{
+				Author: beTestAuthor(commentsdir + sops + c.Name()),
+				Time:   beCommentDate(commentsdir + sops + c.Name()),
+				Body:   string(body)})
+		}
+		sort.SliceStable(comments, func(i, j int) bool {
+			return comments[i].Time.Before(comments[j].Time)
+		})
+		return values, comments
+	}
+	t.Fatalf("Bug %s not exported to %s", summary, bugsdir)
+	return nil, nil
+}
+
+func beTestAuthor(directory string) string {
+	var values map[string]string
+	data, _ := ioutil.ReadFile(directory + sops + "values")
+	json.Unmarshal(data, &values)
+	return values["Author"]
+}
+
+func TestBeStatusSeverity(t *testing.T) {
+	config := bugs.Config{}
+	dir, err := ioutil.TempDir("", "betest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	b := bugs.Issue{Dir: bugs.Directory(dir)}
+	for _, test := range []struct {
+		status, priority, beStatus, beSeverity string
+	}{
+		{"", "", "open", "minor"},
+		{"open:serious", "", "open", "serious"},
+		{"closed:minor", "critical", "closed", "critical"},
+		{"Resolved", "high", "fixed", "critical"},
+		{"someday", "3", "open", "critical"},
+		{"new", "9", "unconfirmed", "fatal"},
+		{"wontfix", "-1", "wontfix", "wishlist"},
+	} {
+		os.Remove(dir + sops + "Status")
+		os.Remove(dir + sops + "Priority")
+		if test.status != "" {
+			b.SetStatus(test.status, config)
+		}
+		if test.priority != "" {
+			b.SetPriority(test.priority, config)
+		}
+		if status, severity := beStatusSeverity(b); status != test.beStatus || severity != test.beSeverity {
+			t.Errorf("Status %q priority %q: expected %s %s, got %s %s", test.status, test.priority,
+				test.beStatus, test.beSeverity, status, severity)
+		}
+	}
+}
+
+func TestBeExportRoundTrip(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "betest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+	user := os.Getenv("USER")
+	defer os.Setenv("USER", user)
+	os.Setenv("USER", "Tester <tester@example.com>")
+
+	export := dir + sops + "export"
+	reimport := dir + sops + "reimport"
+	os.MkdirAll(export+sops+"fit", 0700)
+	os.MkdirAll(reimport+sops+"fit", 0700)
+	os.Chdir(export)
+	os.Setenv("FIT", export)
+	captureOutput(func() {
+		Create(argumentList{"-n", "Exported", "bug", "--status", "open", "--priority", "3"}, config)
+		Create(argumentList{"-n", "Imported", "bug", "--status", "closed:serious"}, config)
+	}, t)
+	b := bugs.Issue{Dir: bugs.Directory(export + sops + "fit" + sops + "Exported-bug"), DescriptionFileName: "Description"}
+	b.SetDescription("first line", config)
+	// BE dates have seconds, keep the comment after the Description
+	b.CommentIssue(bugs.Comment{Body: "a comment", Time: time.Now().Add(time.Minute)}, config)
+
+	stdout, stderr := captureOutput(func() {
+		Export(argumentList{"--be"}, config)
+	}, t)
+	if stderr != "" || stdout != "Exported 2 issues to "+export+sops+".be\n" {
+		t.Errorf("Unexpected export output %s %s", stdout, stderr)
+	}
+	if version, _ := ioutil.ReadFile(export + sops + ".be" + sops + "version"); string(version) != "Bugs Everywhere Directory v1.4\n" {
+		t.Errorf("Unexpected version %q", version)
+	}
+	bugdir := beUUID("fit:fit")
+	bugsdir := export + sops + ".be" + sops + bugdir + sops + "bugs"
+	values, comments := beTestComments(t, bugsdir, "Exported bug")
+	if values["status"] != "open" || values["severity"] != "critical" ||
+		values["creator"] != "Tester <tester@example.com>" ||
+		len(comments) != 2 || comments[0].Body != "first line\n" || comments[1].Body != "a comment\n" {
+		t.Errorf("Unexpected exported bug %v %v", values, comments)
+	}
+	if values, _ := beTestComments(t, bugsdir, "Imported bug"); values["status"] != "closed" || values["severity"] != "serious" {
+		t.Errorf("Unexpected exported status %v", values)
+	}
+
+	// BE to fit and back keeps status, severity, authors and dates
+	os.Chdir(reimport)
+	os.Setenv("FIT", reimport)
+	captureOutput(func() {
+		beImportIssues("abc", reimport+sops+"fit", export+sops+".be", bugdir, config)
+	}, t)
+	imported := bugs.Issue{Dir: bugs.Directory(reimport + sops + "fit" + sops + "Exported-bug")}
+	if imported.Status() != "open:critical" {
+		t.Errorf("Unexpected imported status %s", imported.Status())
+	}
+	os.Setenv("USER", "Somebody Else")
+	captureOutput(func() {
+		Export(argumentList{"--be", reimport}, config)
+	}, t)
+	values2, comments2 := beTestComments(t, reimport+sops+".be"+sops+bugdir+sops+"bugs", "Exported bug")
+	if values2["status"] != values["status"] || values2["severity"] != values["severity"] ||
+		values2["creator"] != values["creator"] || values2["time"] != values["time"] || len(comments2) != len(comments) {
+		t.Fatalf("Unexpected bug after round trip %v %v", values2, comments2)
+	}
+	for i := range comments {
+		if comments2[i].Author != comments[i].Author || !comments2[i].Time.Equal(comments[i].Time) ||
+			comments2[i].Body != comments[i].Body {
+			t.Errorf("Unexpected comment after round trip %v, expected %v", comments2[i], comments[i])
+		}
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
+	"github.com/driusan/bug/scm"
 	"github.com/google/go-github/github" // handles json
 	"golang.org/x/oauth2"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
@@ -25,84 +32,94 @@ func fetchIssueComments(owner string, repo string, comment int, opt *github.Issu
 	return comments, response, err
 }
 
-// githubImportIssues downloads issues and comments from a github repository
-func githubImportIssues(user, repo string, config bugs.Config) {
-	i := 0
+// githubClient returns a client for api.github.com or the GithubApiUrl
+// of config, using GithubPersonalAccessToken when one is set.
+func githubClient(config bugs.Config) (*github.Client, error) {
+	var client *github.Client
+	if config.GithubPersonalAccessToken != "" {
+		ts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: config.GithubPersonalAccessToken},
+		)
+		client = github.NewClient(oauth2.NewClient(context.Background(), ts))
+	} else {
+		client = github.NewClient(nil)
+	}
+	if config.GithubApiUrl != "" {
+		base := config.GithubApiUrl
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+		u, err := url.Parse(base)
+		if err != nil {
+			return nil, err
+		}
+		client.BaseURL = u
+	}
+	return client, nil
+}
+
+// githubIssuesByIdentifier returns the existing issues by Identifier.
+func githubIssuesByIdentifier(config bugs.Config) map[string]bugs.Issue {
+	existing := map[string]bugs.Issue{}
+	for _, b := range bugs.GetAllIssues(config) {
+		if id := b.Identifier(); id != "" {
+			existing[id] = b
+		}
+	}
+	return existing
+}
+
+// githubImportCounts are the results of githubImportIssues.
+type githubImportCounts struct {
+	Created, Updated, Unchanged int
+}
+
+// githubImportIssues downloads issues and comments from a github repository.
+// Issues imported before are found by their GitHub:user/repo#N Identifier
+// and updated in place, so importing again does not duplicate them.
+func githubImportIssues(user, repo string, config bugs.Config) githubImportCounts {
+	var counts githubImportCounts
 	opt := &github.IssueListByRepoOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	client := github.NewClient(nil)
+	client, err := githubClient(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return counts
+	}
 	// https://api.github.com/repos/<user>/<repo>/issues
 	//fmt.Printf("debug fetch args\n    user : %+v\n    repo : %+v\n    opt : %+v\n    client : %+v\n", user, repo, opt, client)
 	issues, resp, err := fetchIssues(user, repo, opt, client)
 	//fmt.Printf("debug fetch resp : %+v\n", resp)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		return
+		return counts
 	}
+	existing := githubIssuesByIdentifier(config)
 
 	for lastPage := false; lastPage != true; {
-		i = 0
 		//fmt.Printf("debug issues : %+v\n", issues)
 		fmt.Printf("api.github.com/repos/%s/%s/issues fetch count : %v\n", user, repo, len(issues))
 		for _, issue := range issues {
-			i += 1
 			// issues includes pull requests, so skip each pull request
-			if issue.PullRequestLinks == nil {
-				ititle := string(bugs.TitleToDir(fmt.Sprintf("%s%s%v", *issue.Title, "-", *issue.Number)))
-				fmt.Printf("Importing issue %s\n", ititle)
-				// add issue.Number to title
-				b := bugs.Issue{Dir: bugs.Directory(config.FitDir + sops + config.FitDirName + sops + ititle)}
-				if dir := b.Direr(); dir != "" {
-					os.Mkdir(string(dir), 0755)
-				}
-				if issue.Body != nil {
-					b.SetDescription(*issue.Body, config)
-				} else {
-					b.SetDescription("", config)
-				}
-				if issue.Milestone != nil {
-					b.SetMilestone(*issue.Milestone.Title, config)
-				}
-				if config.ImportXmlDump == true {
-					// b.SetXml()
-					xml, _ := json.MarshalIndent(issue, "", "    ")
-					err = ioutil.WriteFile(string(b.Direr())+sops+"issue.xml", append(xml, '\n'), 0644)
-					check(err)
-				}
-				// Don't set a bug identifier, but put an empty line and
-				// then a GitHub identifier, so that bug commit can include
-				// "Closes ..." in the commit message.
-				b.SetIdentifier(fmt.Sprintf("GitHub:%s%s%s%s%d", user, "/", repo, "#", *issue.Number), config)
-				for _, lab := range issue.Labels {
-					b.TagIssue(bugs.TagBoolTrue(*lab.Name), config)
-				}
-				j := 1
-				if *issue.Comments > 0 {
-					comments, _, err := fetchIssueComments(user, repo, *issue.Number, nil, client)
-					if err != nil {
-						fmt.Fprintln(os.Stderr, err)
-						return
-					}
-					for _, co := range comments {
-						xml, err := json.MarshalIndent(co, "", "    ")
-						check(err)
-						x := bugs.Comment{
-							Author: *co.User.Login,
-							Time:   *co.CreatedAt,
-							Body:   *co.Body,
-							Order:  j,
-							Xml:    xml}
-						b.CommentIssue(x, config)
-						if config.ImportXmlDump == true {
-							// b.SetXml()
-							comname := "comment-" + string(bugs.ShortTitleToDir(string(*co.Body))) + "-" + fmt.Sprintf("%v", j)
-							err = ioutil.WriteFile(string(b.Direr())+sops+comname+".xml", append(xml, '\n'), 0644)
-							check(err)
-						}
-						j += 1
-					}
-				}
+			if issue.PullRequestLinks != nil {
+				continue
+			}
+			identifier := fmt.Sprintf("GitHub:%s%s%s%s%d", user, "/", repo, "#", *issue.Number)
+			b, found := existing[identifier]
+			changed, err := githubImportIssue(user, repo, identifier, issue, &b, found, client, config)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return counts
+			}
+			existing[identifier] = b
+			switch {
+			case !found:
+				counts.Created += 1
+			case changed:
+				counts.Updated += 1
+			default:
+				counts.Unchanged += 1
 			}
 		}
 		if resp.NextPage == 0 {
@@ -110,9 +127,452 @@ func githubImportIssues(user, repo string, config bugs.Config) {
 		} else {
 			opt.ListOptions.Page = resp.NextPage
 			issues, resp, err = fetchIssues(user, repo, opt, client)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return counts
+			}
+		}
+	}
+	fmt.Printf("GitHub import: %d created, %d updated, %d unchanged\n", counts.Created, counts.Updated, counts.Unchanged)
+	return counts
+}
+
+// githubImportIssue creates b from issue, or updates the title, description,
+// milestone, labels and new comments of b when found. It returns true when
+// an existing issue changed.
+func githubImportIssue(user, repo, identifier string, issue *github.Issue, b *bugs.Issue, found bool, client *github.Client, config bugs.Config) (bool, error) {
+	// add issue.Number to title
+	ititle := string(bugs.TitleToDir(fmt.Sprintf("%s%s%v", *issue.Title, "-", *issue.Number)))
+	dir := bugs.FitDirer(config) + dops + bugs.Directory(ititle)
+	changed := false
+	if !found {
+		fmt.Printf("Importing issue %s\n", ititle)
+		*b = bugs.Issue{Dir: dir, DescriptionFileName: config.DescriptionFileName}
+		if err := bugs.CurrentStore().MkdirAll(string(dir)); err != nil {
+			return false, err
+		}
+	} else if b.Dir.ShortNamer() != bugs.Directory(ititle) {
+		// the title changed on GitHub
+		fmt.Printf("Renaming issue %s to %s\n", b.Dir.ShortNamer(), ititle)
+		if err := bugs.CurrentStore().Rename(string(b.Dir), string(dir)); err != nil {
+			return false, err
+		}
+		b.Dir = dir
+		bugs.InvalidateCache(b.Dir, config)
+		changed = true
+	}
+
+	body := ""
+	if issue.Body != nil {
+		body = *issue.Body
+	}
+	if !found || strings.TrimRight(b.Description(), "\n") != strings.TrimRight(body, "\n") {
+		b.SetDescription(body, config)
+		changed = true
+	}
+	if issue.Milestone != nil && b.Milestone() != *issue.Milestone.Title {
+		b.SetMilestone(*issue.Milestone.Title, config)
+		changed = true
+	}
+	if config.ImportXmlDump == true {
+		// b.SetXml()
+		xml, _ := json.MarshalIndent(issue, "", "    ")
+		err := bugs.CurrentStore().WriteFile(string(b.Direr())+sops+"issue.xml", append(xml, '\n'))
+		check(err)
+	}
+	if issue.UpdatedAt != nil {
+		githubSetSynced(*b, *issue.UpdatedAt, config)
+	}
+	if !found {
+		// Don't set a bug identifier, but put an empty line and
+		// then a GitHub identifier, so that bug commit can include
+		// "Closes ..." in the commit message.
+		b.SetIdentifier(identifier, config)
+	}
+	for _, lab := range issue.Labels {
+		if !b.HasTag(bugs.TagBoolTrue(*lab.Name)) {
+			b.TagIssue(bugs.TagBoolTrue(*lab.Name), config)
+			changed = true
+		}
+	}
+	if found {
+		// drop the tags of labels removed on GitHub, so that sync does
+		// not put them back
+		labels := map[string]bool{}
+		for _, lab := range issue.Labels {
+			labels[strings.ToLower(*lab.Name)] = true
+		}
+		for _, tag := range githubLabels(*b) {
+			if !labels[tag] {
+				githubRemoveLabel(b, tag, config)
+				changed = true
+			}
+		}
+	}
+	j := 1
+	if issue.Comments != nil && *issue.Comments > 0 {
+		comments, _, err := fetchIssueComments(user, repo, *issue.Number, nil, client)
+		if err != nil {
+			return changed, err
+		}
+		for _, co := range comments {
+			if b.HasComment(*co.Body) {
+				j += 1
+				continue
+			}
+			xml, err := json.MarshalIndent(co, "", "    ")
 			check(err)
+			x := bugs.Comment{
+				Author: *co.User.Login,
+				Time:   *co.CreatedAt,
+				Body:   *co.Body,
+				Order:  j,
+				Xml:    xml}
+			b.CommentIssue(x, config)
+			changed = true
+			if config.ImportXmlDump == true {
+				// b.SetXml()
+				comname := "comment-" + string(bugs.ShortTitleToDir(string(*co.Body))) + "-" + fmt.Sprintf("%v", j)
+				err = bugs.CurrentStore().WriteFile(string(b.Direr())+sops+comname+".xml", append(xml, '\n'))
+				check(err)
+			}
+			j += 1
 		}
 	}
+	return found && changed, nil
+}
+
+// githubSyncFile stores the GitHub updated_at time of the last import or
+// sync of an issue, the marker used to detect conflicting remote edits.
+const githubSyncFile = "GithubSync"
+
+// githubSynced returns the last sync marker of b.
+func githubSynced(b bugs.Issue) (time.Time, bool) {
+	data, err := bugs.CurrentStore().ReadFile(string(b.Direr()) + sops + githubSyncFile)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	return t, err == nil
+}
+
+// githubSetSynced writes the sync marker of b.
+func githubSetSynced(b bugs.Issue, t time.Time, config bugs.Config) {
+	err := bugs.CurrentStore().WriteFile(string(b.Direr())+sops+githubSyncFile, []byte(t.UTC().Format(time.RFC3339)+"\n"))
+	check(err)
+	bugs.InvalidateCache(b.Direr(), config)
+}
+
+// githubIdentifierRegex matches the Identifier of imported GitHub issues.
+var githubIdentifierRegex = regexp.MustCompile(`^GitHub:([^/]+)/([^#]+)#([0-9]+)$`)
+
+// githubSyncCounts are the results of githubSyncIssues.
+type githubSyncCounts struct {
+	Pushed, Unchanged, Conflicts int
+}
+
+// githubSyncIssues pushes local changes of issues imported from GitHub
+// back to GitHub. Only issues of user/repo are synced when repo is set.
+// Issues changed on GitHub since their last sync are conflicts and are
+// skipped unless force is true. Issues whose directory was removed by
+// fit close are closed on GitHub.
+func githubSyncIssues(user, repo string, force bool, config bugs.Config) githubSyncCounts {
+	var counts githubSyncCounts
+	client, err := githubClient(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return counts
+	}
+	milestones := map[string]map[string]int{}
+	for _, b := range bugs.GetAllIssues(config) {
+		m := githubIdentifierRegex.FindStringSubmatch(b.Identifier())
+		if m == nil || (repo != "" && (m[1] != user || m[2] != repo)) {
+			continue
+		}
+		number, _ := strconv.Atoi(m[3])
+		if milestones[m[1]+"/"+m[2]] == nil {
+			milestones[m[1]+"/"+m[2]] = map[string]int{}
+		}
+		pushed, err := githubSyncIssue(m[1], m[2], number, b, force, milestones[m[1]+"/"+m[2]], client, config)
+		switch {
+		case err == errGithubConflict:
+			fmt.Fprintf(os.Stderr, "Conflict: %s changed on GitHub since the last sync, import it again or use --force\n", b.Identifier())
+			counts.Conflicts += 1
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error syncing %s: %s\n", b.Identifier(), err.Error())
+			return counts
+		case pushed:
+			counts.Pushed += 1
+		default:
+			counts.Unchanged += 1
+		}
+	}
+	// fit close removes the directory unless CloseStatusTag is set
+	removed, synced, err := githubRemovedIssues(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding removed issues: %s\n", err.Error())
+	}
+	for _, c := range removed {
+		m := githubIdentifierRegex.FindStringSubmatch(c.Identifier)
+		if repo != "" && (m[1] != user || m[2] != repo) {
+			continue
+		}
+		number, _ := strconv.Atoi(m[3])
+		pushed, err := githubCloseIssue(m[1], m[2], number, force, client)
+		switch {
+		case err == errGithubConflict:
+			fmt.Fprintf(os.Stderr, "Conflict: %s was reopened on GitHub since it was closed, import it again or use --force\n", c.Identifier)
+			counts.Conflicts += 1
+			// look at the issue again on the next sync
+			synced = nil
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error syncing %s: %s\n", c.Identifier, err.Error())
+			return counts
+		case pushed:
+			fmt.Printf("Pushed close to %s\n", c.Identifier)
+			counts.Pushed += 1
+		default:
+			counts.Unchanged += 1
+		}
+	}
+	if synced != nil && repo == "" {
+		synced()
+	}
+	fmt.Printf("GitHub sync: %d pushed, %d unchanged, %d conflicts\n", counts.Pushed, counts.Unchanged, counts.Conflicts)
+	return counts
+}
+
+// githubRemovedIssues returns the issues imported from GitHub whose
+// directory was removed since they were committed, and that were not
+// imported again. Only the commits after the revision in the GithubSync
+// marker of the repository are looked at, and the returned function
+// moves the marker to the commit checked out once the issues are closed.
+// Without a repository there is no history to look at.
+func githubRemovedIssues(config bugs.Config) ([]bugs.IssueChange, func(), error) {
+	handler, scmdir, err := scm.DetectSCM(map[string]bool{}, config)
+	if err != nil {
+		return nil, nil, nil
+	}
+	marker := string(scmdir) + sops + githubSyncFile
+	from := ""
+	if data, err := ioutil.ReadFile(marker); err == nil {
+		from = strings.TrimSpace(string(data))
+	}
+	closed, err := scm.ClosedIssues(handler, from, "", config)
+	if err != nil && from != "" {
+		// the revision is gone, after a rebase for example
+		closed, err = scm.ClosedIssues(handler, "", "", config)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	local := map[string]bool{}
+	for _, b := range bugs.GetAllIssues(config) {
+		local[b.Identifier()] = true
+	}
+	var removed []bugs.IssueChange
+	for _, c := range closed {
+		if c.New == nil && !local[c.Identifier] && githubIdentifierRegex.MatchString(c.Identifier) {
+			removed = append(removed, c)
+		}
+	}
+	synced := func() {
+		if head, err := scm.HeadRevision(handler, config); err == nil {
+			ioutil.WriteFile(marker, []byte(head+"\n"), 0644)
+		}
+	}
+	return removed, synced, nil
+}
+
+// githubCloseIssue closes GitHub issue number of a removed issue. An
+// issue reopened on GitHub after it was closed is a conflict.
+func githubCloseIssue(user, repo string, number int, force bool, client *github.Client) (bool, error) {
+	ctx := context.Background()
+	issue, _, err := client.Issues.Get(ctx, user, repo, number)
+	if err != nil {
+		return false, err
+	}
+	if issue.GetState() == "closed" {
+		return false, nil
+	}
+	if issue.ClosedAt != nil && !force {
+		return false, errGithubConflict
+	}
+	state := "closed"
+	if _, _, err := client.Issues.Edit(ctx, user, repo, number, &github.IssueRequest{State: &state}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// errGithubConflict is returned by githubSyncIssue for remote edits.
+var errGithubConflict = errors.New("changed on GitHub since the last sync")
+
+// githubLabels returns the tags of b that are labels, tags without a
+// field name.
+func githubLabels(b bugs.Issue) []string {
+	labels := []string{}
+	for _, tag := range b.StringTags() {
+		if !strings.Contains(tag, ":") {
+			labels = append(labels, tag)
+		}
+	}
+	return labels
+}
+
+// githubRemoveLabel removes the tag of a label from b, whatever the case
+// of the tag file name.
+func githubRemoveLabel(b *bugs.Issue, label string, config bugs.Config) {
+	files, _ := ioutil.ReadDir(string(b.Direr()) + sops + "tags")
+	for _, f := range files {
+		if strings.ToLower(f.Name()) == label && f.Name() != label {
+			b.RemoveTag(bugs.TagBoolTrue(f.Name()), config)
+		}
+	}
+	b.RemoveTag(bugs.TagBoolTrue(label), config)
+}
+
+// githubSyncIssue pushes the title, description, labels, milestone, new
+// comment files and the closed status of b to GitHub issue number. The
+// milestones of the repository are cached by title in milestones.
+func githubSyncIssue(user, repo string, number int, b bugs.Issue, force bool, milestones map[string]int, client *github.Client, config bugs.Config) (bool, error) {
+	ctx := context.Background()
+	issue, _, err := client.Issues.Get(ctx, user, repo, number)
+	if err != nil {
+		return false, err
+	}
+	if synced, ok := githubSynced(b); ok && !force && issue.UpdatedAt != nil && issue.UpdatedAt.After(synced) {
+		return false, errGithubConflict
+	}
+
+	req := &github.IssueRequest{}
+	changes := []string{}
+	// the directory is the title with the issue number appended on import
+	dir := strings.TrimSuffix(string(b.Dir.ShortNamer()), "-"+strconv.Itoa(number))
+	if bugs.TitleToDirString(issue.GetTitle()) != dir {
+		title := bugs.Directory(dir).ToTitle()
+		req.Title = &title
+		changes = append(changes, "title")
+	}
+	if body := strings.TrimRight(b.Description(), "\n"); body != strings.TrimRight(issue.GetBody(), "\n") {
+		req.Body = &body
+		changes = append(changes, "description")
+	}
+
+	// labels of GitHub are kept when tagged locally, new tags are added
+	local := map[string]bool{}
+	for _, l := range githubLabels(b) {
+		local[l] = true
+	}
+	labels := []string{}
+	for _, lab := range issue.Labels {
+		if local[strings.ToLower(lab.GetName())] {
+			labels = append(labels, lab.GetName())
+			delete(local, strings.ToLower(lab.GetName()))
+		}
+	}
+	kept := len(labels)
+	for _, l := range githubLabels(b) {
+		if local[l] {
+			labels = append(labels, l)
+		}
+	}
+	if kept != len(issue.Labels) || kept != len(labels) {
+		req.Labels = &labels
+		changes = append(changes, "labels")
+	}
+
+	if milestone := b.Milestone(); milestone != "" && (issue.Milestone == nil || !strings.EqualFold(issue.Milestone.GetTitle(), milestone)) {
+		n, err := githubMilestone(user, repo, milestone, milestones, client)
+		if err != nil {
+			return false, err
+		}
+		req.Milestone = &n
+		changes = append(changes, "milestone")
+	}
+	if strings.ToLower(b.Status()) == "closed" && issue.GetState() != "closed" {
+		state := "closed"
+		req.State = &state
+		changes = append(changes, "close")
+	}
+	if len(changes) > 0 {
+		if _, _, err := client.Issues.Edit(ctx, user, repo, number, req); err != nil {
+			return false, err
+		}
+	}
+
+	// comment files, the comments file of ImportCommentsTogether is not pushed
+	var remote []*github.IssueComment
+	if issue.GetComments() > 0 {
+		if remote, _, err = fetchIssueComments(user, repo, number, nil, client); err != nil {
+			return false, err
+		}
+	}
+	for _, c := range b.Comments() {
+		if c.File == "comments" {
+			continue
+		}
+		body := c.Body
+		found := false
+		for _, co := range remote {
+			if strings.TrimRight(co.GetBody(), "\n") == body {
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		if _, _, err := client.Issues.CreateComment(ctx, user, repo, number, &github.IssueComment{Body: &body}); err != nil {
+			return false, err
+		}
+		if len(changes) == 0 || changes[len(changes)-1] != "comments" {
+			changes = append(changes, "comments")
+		}
+	}
+	if len(changes) == 0 {
+		return false, nil
+	}
+	fmt.Printf("Pushed %s to %s\n", strings.Join(changes, ", "), b.Identifier())
+
+	// pushing changed updated_at, the new value is the marker
+	if issue, _, err = client.Issues.Get(ctx, user, repo, number); err != nil {
+		return true, err
+	}
+	if issue.UpdatedAt != nil {
+		githubSetSynced(b, *issue.UpdatedAt, config)
+	}
+	return true, nil
+}
+
+// githubMilestone returns the number of the milestone title of user/repo,
+// creating the milestone when it does not exist.
+func githubMilestone(user, repo, title string, milestones map[string]int, client *github.Client) (int, error) {
+	ctx := context.Background()
+	if len(milestones) == 0 {
+		opt := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			page, resp, err := client.Issues.ListMilestones(ctx, user, repo, opt)
+			if err != nil {
+				return 0, err
+			}
+			for _, m := range page {
+				milestones[strings.ToLower(m.GetTitle())] = m.GetNumber()
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.ListOptions.Page = resp.NextPage
+		}
+	}
+	if n, ok := milestones[strings.ToLower(title)]; ok {
+		return n, nil
+	}
+	m, _, err := client.Issues.CreateMilestone(ctx, user, repo, &github.Milestone{Title: &title})
+	if err != nil {
+		return 0, err
+	}
+	milestones[strings.ToLower(title)] = m.GetNumber()
+	return m.GetNumber(), nil
 }
 
 func fetchProjectCards(columnid int64, opt *github.ProjectCardListOptions, client *github.Client) ([]*github.ProjectCard, *github.Response, error) {
@@ -137,12 +597,11 @@ func fetchProjects(owner string, repo string, opt *github.ProjectListOptions, cl
 }
 
 func githubImportProjects(user, repo string, config bugs.Config) {
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: config.GithubPersonalAccessToken},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc) // oauthClient
+	client, err := githubClient(config) // oauthClient
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
 	i := 0
 	opt := &github.ProjectListOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
@@ -1 +1,372 @@
 package fitapp
+
+import (
+	"encoding/json"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// githubTestAPI stands in for api.github.com with one repository u/r.
+// Every change moves updated_at of the issue one minute forward.
+type githubTestAPI struct {
+	issues     []map[string]interface{}
+	comments   map[string][]map[string]interface{}
+	milestones []map[string]interface{}
+	clock      time.Time
+}
+
+// touch sets updated_at of issue to the next minute.
+func (api *githubTestAPI) touch(issue map[string]interface{}) {
+	if api.clock.IsZero() {
+		api.clock = time.Date(2019, 1, 2, 3, 4, 0, 0, time.UTC)
+	}
+	api.clock = api.clock.Add(time.Minute)
+	issue["updated_at"] = api.clock.Format(time.RFC3339)
+}
+
+func (api *githubTestAPI) issue(number string) map[string]interface{} {
+	for _, issue := range api.issues {
+		if fmt.Sprint(issue["number"]) == number {
+			if issue["updated_at"] == nil {
+				api.touch(issue)
+			}
+			return issue
+		}
+	}
+	return nil
+}
+
+func (api *githubTestAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/u/r/"), "/")
+	var request map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&request)
+	switch {
+	case len(path) == 1 && path[0] == "issues":
+		for _, issue := range api.issues {
+			api.issue(fmt.Sprint(issue["number"]))
+		}
+		json.NewEncoder(w).Encode(api.issues)
+	case len(path) == 2 && path[0] == "issues" && api.issue(path[1]) != nil:
+		issue := api.issue(path[1])
+		if r.Method == "PATCH" {
+			for k, v := range request {
+				switch k {
+				case "labels":
+					labels := []map[string]interface{}{}
+					for _, l := range v.([]interface{}) {
+						labels = append(labels, map[string]interface{}{"name": l})
+					}
+					issue[k] = labels
+				case "milestone":
+					for _, m := range api.milestones {
+						if m["number"] == v {
+							issue[k] = m
+						}
+					}
+				default:
+					issue[k] = v
+				}
+			}
+			api.touch(issue)
+		}
+		json.NewEncoder(w).Encode(issue)
+	case len(path) == 3 && path[0] == "issues" && path[2] == "comments" && api.issue(path[1]) != nil:
+		comments := api.comments[path[1]]
+		if r.Method == "POST" {
+			comments = append(comments, githubTestComment(request["body"].(string)))
+			api.comments[path[1]] = comments
+			issue := api.issue(path[1])
+			issue["comments"] = len(comments)
+			api.touch(issue)
+			json.NewEncoder(w).Encode(comments[len(comments)-1])
+			return
+		}
+		if comments == nil {
+			comments = []map[string]interface{}{}
+		}
+		json.NewEncoder(w).Encode(comments)
+	case len(path) == 1 && path[0] == "milestones":
+		if r.Method == "POST" {
+			request["number"] = float64(len(api.milestones) + 1)
+			api.milestones = append(api.milestones, request)
+			json.NewEncoder(w).Encode(request)
+			return
+		}
+		if api.milestones == nil {
+			api.milestones = []map[string]interface{}{}
+		}
+		json.NewEncoder(w).Encode(api.milestones)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func githubTestComment(body string) map[string]interface{} {
+	return map[string]interface{}{
+		"body":       body,
+		"user":       map[string]interface{}{"login": "octocat"},
+		"created_at": "2019-01-02T03:04:05Z",
+	}
+}
+
+func TestGithubImportIdempotent(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	dir, err := ioutil.TempDir("", "githubtest")
+	if err != nil {
+		t.Error("Could not create temporary dir for test")
+		return
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	defer os.RemoveAll(dir)
+	err = os.Setenv("FIT", dir)
+	if err != nil {
+		t.Error("Could not set environment variable: " + err.Error())
+		return
+	}
+
+	api := &githubTestAPI{
+		issues: []map[string]interface{}{
+			{"number": 1, "title": "First bug", "body": "first", "comments": 1,
+				"labels":    []map[string]interface{}{{"name": "cli"}},
+				"milestone": map[string]interface{}{"title": "v1.0"}},
+			{"number": 2, "title": "Second bug", "body": "second", "comments": 0},
+			{"number": 3, "title": "A pull request", "body": "", "comments": 0,
+				"pull_request": map[string]interface{}{"url": "http://example.com"}},
+		},
+		comments: map[string][]map[string]interface{}{
+			"1": {githubTestComment("looks bad")},
+		},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	config.GithubApiUrl = srv.URL
+
+	var counts githubImportCounts
+	captureOutput(func() {
+		counts = githubImportIssues("u", "r", config)
+	}, t)
+	if counts != (githubImportCounts{Created: 2}) {
+		t.Errorf("Unexpected first import counts %+v", counts)
+	}
+	captureOutput(func() {
+		counts = githubImportIssues("u", "r", config)
+	}, t)
+	if counts != (githubImportCounts{Unchanged: 2}) {
+		t.Errorf("Unexpected second import counts %+v", counts)
+	}
+	if issues, _ := ioutil.ReadDir(dir + sops + "fit"); len(issues) != 2 {
+		t.Errorf("Expected 2 issue directories, got %v", len(issues))
+	}
+
+	api.issues[0]["title"] = "First bug renamed"
+	api.issues[0]["body"] = "first, edited"
+	api.issues[0]["comments"] = 2
+	api.issues[0]["labels"] = []map[string]interface{}{{"name": "cli"}, {"name": "crash"}}
+	api.comments["1"] = append(api.comments["1"], githubTestComment("still bad"))
+	stdout, _ := captureOutput(func() {
+		counts = githubImportIssues("u", "r", config)
+	}, t)
+	if counts != (githubImportCounts{Updated: 1, Unchanged: 1}) {
+		t.Errorf("Unexpected third import counts %+v", counts)
+	}
+	if !strings.Contains(stdout, "GitHub import: 0 created, 1 updated, 1 unchanged") {
+		t.Errorf("Unexpected import output %s", stdout)
+	}
+	b, err := bugs.LoadIssueByHeuristic("GitHub:u/r#1", config)
+	if err != nil {
+		t.Fatalf("Could not load updated issue: %s", err.Error())
+	}
+	if b.Dir.ShortNamer() != "First-bug-renamed-1" || b.Description() != "first, edited\n" ||
+		b.Milestone() != "v1.0" || !b.HasTag(bugs.TagBoolTrue("crash")) {
+		t.Errorf("Unexpected updated issue %s %q %s %v", b.Dir.ShortNamer(), b.Description(), b.Milestone(), b.StringTags())
+	}
+	if comments, _ := filepath.Glob(string(b.Dir) + sops + "comment-*"); len(comments) != 2 {
+		t.Errorf("Expected 2 comments, got %v", comments)
+	}
+	if issues, _ := ioutil.ReadDir(dir + sops + "fit"); len(issues) != 2 {
+		t.Errorf("Expected 2 issue directories after rename, got %v", len(issues))
+	}
+
+	// a label removed on GitHub is removed locally and not pushed back
+	api.issues[0]["labels"] = []map[string]interface{}{{"name": "cli"}}
+	api.touch(api.issues[0])
+	captureOutput(func() {
+		counts = githubImportIssues("u", "r", config)
+	}, t)
+	if counts != (githubImportCounts{Updated: 1, Unchanged: 1}) {
+		t.Errorf("Unexpected import counts after removing a label %+v", counts)
+	}
+	b, _ = bugs.LoadIssueByHeuristic("GitHub:u/r#1", config)
+	if b.HasTag(bugs.TagBoolTrue("crash")) || !b.HasTag(bugs.TagBoolTrue("cli")) {
+		t.Errorf("Unexpected tags after removing a label %v", b.StringTags())
+	}
+	b.SetDescription("first, edited locally", config)
+	var synced githubSyncCounts
+	captureOutput(func() {
+		synced = githubSyncIssues("u", "r", false, config)
+	}, t)
+	if synced.Pushed != 1 || api.issues[0]["body"] != "first, edited locally" {
+		t.Errorf("Unexpected sync %+v %v", synced, api.issues[0]["body"])
+	}
+	if labels := fmt.Sprint(api.issues[0]["labels"]); labels != "[map[name:cli]]" {
+		t.Errorf("Unexpected labels after sync %s", labels)
+	}
+	os.Chdir(pwd)
+}
+
+func TestGithubImportStore(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	config.ImportXmlDump = true
+	dir, err := ioutil.TempDir("", "githubtest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer func() {
+		os.Chdir(pwd)
+		os.RemoveAll(dir)
+	}()
+	os.MkdirAll(config.FitDirName, 0700)
+	os.Setenv("FIT", dir)
+	old := bugs.SetStore(bugs.NewMemStore())
+	defer bugs.SetStore(old)
+	bugs.CurrentStore().MkdirAll(dir + sops + config.FitDirName)
+
+	api := &githubTestAPI{
+		issues: []map[string]interface{}{
+			{"number": 1, "title": "Stored bug", "body": "first", "comments": 1},
+		},
+		comments: map[string][]map[string]interface{}{
+			"1": {githubTestComment("looks bad")},
+		},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	config.GithubApiUrl = srv.URL
+
+	captureOutput(func() {
+		githubImportIssues("u", "r", config)
+	}, t)
+	api.issues[0]["title"] = "Stored bug renamed"
+	api.touch(api.issues[0])
+	var counts githubImportCounts
+	captureOutput(func() {
+		counts = githubImportIssues("u", "r", config)
+	}, t)
+	if counts != (githubImportCounts{Updated: 1}) {
+		t.Errorf("Unexpected import counts %+v", counts)
+	}
+	b, err := bugs.LoadIssueByHeuristic("GitHub:u/r#1", config)
+	if err != nil || b.Dir.ShortNamer() != "Stored-bug-renamed-1" {
+		t.Fatalf("Unexpected renamed issue %+v %v", b, err)
+	}
+	if _, ok := githubSynced(*b); !ok {
+		t.Errorf("No sync marker in the store")
+	}
+	if _, err := bugs.CurrentStore().Stat(string(b.Dir) + sops + "issue.xml"); err != nil {
+		t.Errorf("No issue dump in the store: %s", err.Error())
+	}
+	if files, _ := ioutil.ReadDir(dir + sops + config.FitDirName); len(files) != 0 {
+		t.Errorf("Unexpected files written around the store %v", files)
+	}
+}
+
+func TestGithubSyncRemoved(t *testing.T) {
+	config, commit, done := setupNotifyRepo(t) // from Notify_test.go
+	defer done()
+
+	api := &githubTestAPI{
+		issues: []map[string]interface{}{
+			{"number": 1, "title": "First bug", "body": "first", "comments": 0, "state": "open"},
+			{"number": 2, "title": "Second bug", "body": "second", "comments": 0, "state": "open"},
+		},
+		comments: map[string][]map[string]interface{}{},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	config.GithubApiUrl = srv.URL
+
+	captureOutput(func() {
+		githubImportIssues("u", "r", config)
+	}, t)
+	commit("Import issues")
+	stdout, _ := captureOutput(func() {
+		Close(argumentList{"GitHub:u/r#1"}, config)
+	}, t)
+	if !strings.HasPrefix(stdout, "Removing ") {
+		t.Fatalf("Could not close issue: %s", stdout)
+	}
+
+	var counts githubSyncCounts
+	stdout, _ = captureOutput(func() {
+		counts = githubSyncIssues("", "", false, config)
+	}, t)
+	if counts != (githubSyncCounts{Pushed: 1, Unchanged: 1}) || !strings.Contains(stdout, "Pushed close to GitHub:u/r#1\n") {
+		t.Errorf("Unexpected sync of a removed issue %+v: %s", counts, stdout)
+	}
+	if api.issues[0]["state"] != "closed" || api.issues[1]["state"] != "open" {
+		t.Errorf("Unexpected states %v %v", api.issues[0]["state"], api.issues[1]["state"])
+	}
+	marker := ".git" + sops + githubSyncFile
+	imported, _ := exec.Command("git", "rev-parse", "HEAD").Output()
+	if data, _ := ioutil.ReadFile(marker); string(data) != string(imported) {
+		t.Errorf("Unexpected sync marker %q, expected %q", data, imported)
+	}
+
+	// reopened on GitHub after it was closed
+	api.issues[0]["state"] = "open"
+	api.issues[0]["closed_at"] = "2019-01-02T03:04:05Z"
+	commit("Close issue")
+	captureOutput(func() {
+		counts = githubSyncIssues("", "", false, config)
+	}, t)
+	if counts != (githubSyncCounts{Unchanged: 1, Conflicts: 1}) || api.issues[0]["state"] != "open" {
+		t.Errorf("Unexpected sync of a reopened issue %+v", counts)
+	}
+	if data, _ := ioutil.ReadFile(marker); string(data) != string(imported) {
+		t.Errorf("Sync marker moved after a conflict %q", data)
+	}
+	captureOutput(func() {
+		counts = githubSyncIssues("", "", true, config)
+	}, t)
+	if counts != (githubSyncCounts{Pushed: 1, Unchanged: 1}) || api.issues[0]["state"] != "closed" {
+		t.Errorf("Unexpected forced sync of a reopened issue %+v", counts)
+	}
+
+	// only the commits after the last sync are looked at
+	closed, _ := exec.Command("git", "rev-parse", "HEAD").Output()
+	if data, _ := ioutil.ReadFile(marker); string(data) != string(closed) {
+		t.Errorf("Unexpected sync marker %q, expected %q", data, closed)
+	}
+	captureOutput(func() {
+		counts = githubSyncIssues("", "", false, config)
+	}, t)
+	if counts != (githubSyncCounts{Unchanged: 1}) {
+		t.Errorf("Unexpected sync after the last sync %+v", counts)
+	}
+	// an unknown revision looks at every commit
+	ioutil.WriteFile(marker, []byte("0123456789012345678901234567890123456789\n"), 0644)
+	captureOutput(func() {
+		counts = githubSyncIssues("", "", false, config)
+	}, t)
+	if counts != (githubSyncCounts{Unchanged: 2}) {
+		t.Errorf("Unexpected sync with an unknown revision %+v", counts)
+	}
+}
@@ -0,0 +1,397 @@
+package fitapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//var dops = bugs.Directory(os.PathSeparator)
+//var sops = string(os.PathSeparator)
+
+// jiraIssue is an issue read from the Jira REST api or an export.
+type jiraIssue struct {
+	Key         string
+	Summary     string
+	Description string
+	Status      string
+	Priority    string
+	Milestone   string
+	Labels      []string
+	Comments    []jiraComment
+	Xml         []byte
+}
+
+// jiraComment is a comment of a jiraIssue.
+type jiraComment struct {
+	Author  string
+	Created time.Time
+	Body    string
+	Xml     []byte
+}
+
+/*
+The Jira REST api returns issues from /rest/api/2/search as
+
+	{
+	    "startAt": 0, "maxResults": 50, "total": 1,
+	    "issues": [{
+	        "key": "PROJ-1",
+	        "fields": {
+	            "summary": "abc",
+	            "description": "text",
+	            "status": {"name": "Open"},
+	            "priority": {"name": "Major"},
+	            "fixVersions": [{"name": "1.0"}],
+	            "labels": ["cli"],
+	            "comment": {"comments": [{
+	                "author": {"name": "jdoe", "displayName": "J Doe"},
+	                "body": "text",
+	                "created": "2019-01-02T03:04:05.000+0000"
+	            }]}
+	        }
+	    }]
+	}
+
+	and single issues from /rest/api/2/issue/PROJ-1 as one element of issues.
+*/
+type jiraJSONName struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type jiraJSONComment struct {
+	Author  jiraJSONName `json:"author"`
+	Body    string       `json:"body"`
+	Created string       `json:"created"`
+}
+
+type jiraJSONIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string         `json:"summary"`
+		Description string         `json:"description"`
+		Status      jiraJSONName   `json:"status"`
+		Priority    jiraJSONName   `json:"priority"`
+		FixVersions []jiraJSONName `json:"fixVersions"`
+		Labels      []string       `json:"labels"`
+		Comment     struct {
+			Comments []json.RawMessage `json:"comments"`
+		} `json:"comment"`
+	} `json:"fields"`
+}
+
+type jiraJSONSearch struct {
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+	Issues     []json.RawMessage `json:"issues"`
+}
+
+/*
+Jira exports search results as xml in an rss feed
+
+	<rss version="0.92"><channel>
+	<item>
+	    <key id="10000">PROJ-1</key>
+	    <summary>abc</summary>
+	    <description>text</description>
+	    <status id="1">Open</status>
+	    <priority id="3">Major</priority>
+	    <fixVersion>1.0</fixVersion>
+	    <labels><label>cli</label></labels>
+	    <comments>
+	        <comment id="1" author="jdoe" created="Wed, 2 Jan 2019 03:04:05 +0000">text</comment>
+	    </comments>
+	</item>
+	</channel></rss>
+*/
+type jiraXMLComment struct {
+	Author  string `xml:"author,attr"`
+	Created string `xml:"created,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type jiraXMLIssue struct {
+	Key         string           `xml:"key"`
+	Summary     string           `xml:"summary"`
+	Description string           `xml:"description"`
+	Status      string           `xml:"status"`
+	Priority    string           `xml:"priority"`
+	FixVersions []string         `xml:"fixVersion"`
+	Labels      []string         `xml:"labels>label"`
+	Comments    []jiraXMLComment `xml:"comments>comment"`
+}
+
+type jiraXMLExport struct {
+	Items []struct {
+		jiraXMLIssue
+		Inner []byte `xml:",innerxml"`
+	} `xml:"channel>item"`
+}
+
+// jiraTime parses the created time of a comment.
+func jiraTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02T15:04:05.000-0700", time.RFC3339, time.RFC1123Z, time.RFC1123} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// jiraParseJSONIssue reads one issue of the REST api.
+func jiraParseJSONIssue(raw json.RawMessage) (jiraIssue, error) {
+	var ji jiraJSONIssue
+	if err := json.Unmarshal(raw, &ji); err != nil {
+		return jiraIssue{}, err
+	}
+	issue := jiraIssue{
+		Key:         ji.Key,
+		Summary:     ji.Fields.Summary,
+		Description: ji.Fields.Description,
+		Status:      ji.Fields.Status.Name,
+		Priority:    ji.Fields.Priority.Name,
+		Labels:      ji.Fields.Labels,
+	}
+	if len(ji.Fields.FixVersions) > 0 {
+		issue.Milestone = ji.Fields.FixVersions[0].Name
+	}
+	issue.Xml, _ = json.MarshalIndent(raw, "", "    ")
+	for _, rawComment := range ji.Fields.Comment.Comments {
+		var jc jiraJSONComment
+		if err := json.Unmarshal(rawComment, &jc); err != nil {
+			return issue, err
+		}
+		author := jc.Author.DisplayName
+		if author == "" {
+			author = jc.Author.Name
+		}
+		xml, _ := json.MarshalIndent(rawComment, "", "    ")
+		issue.Comments = append(issue.Comments, jiraComment{
+			Author:  author,
+			Created: jiraTime(jc.Created),
+			Body:    jc.Body,
+			Xml:     xml,
+		})
+	}
+	return issue, nil
+}
+
+// jiraParse reads issues from a REST api response, a json dump of issues
+// or an xml export. The search total is returned for paging.
+func jiraParse(data []byte) ([]jiraIssue, int, error) {
+	data = bytes.TrimSpace(data)
+	var issues []jiraIssue
+	if len(data) > 0 && data[0] == '<' {
+		var export jiraXMLExport
+		if err := xml.Unmarshal(data, &export); err != nil {
+			return nil, 0, err
+		}
+		for _, item := range export.Items {
+			issue := jiraIssue{
+				Key:         item.Key,
+				Summary:     item.Summary,
+				Description: item.Description,
+				Status:      item.Status,
+				Priority:    item.Priority,
+				Labels:      item.Labels,
+				Xml:         append([]byte("<item>"), append(item.Inner, []byte("</item>")...)...),
+			}
+			if len(item.FixVersions) > 0 {
+				issue.Milestone = item.FixVersions[0]
+			}
+			for _, c := range item.Comments {
+				cxml, _ := xml.MarshalIndent(c, "", "    ")
+				issue.Comments = append(issue.Comments, jiraComment{
+					Author:  c.Author,
+					Created: jiraTime(c.Created),
+					Body:    c.Body,
+					Xml:     cxml,
+				})
+			}
+			issues = append(issues, issue)
+		}
+		return issues, len(issues), nil
+	}
+
+	var raws []json.RawMessage
+	total := 0
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return nil, 0, err
+		}
+		total = len(raws)
+	} else {
+		var search jiraJSONSearch
+		if err := json.Unmarshal(data, &search); err != nil {
+			return nil, 0, err
+		}
+		if search.Issues != nil {
+			raws = search.Issues
+			total = search.Total
+		} else {
+			raws = []json.RawMessage{data}
+			total = 1
+		}
+	}
+	for _, raw := range raws {
+		issue, err := jiraParseJSONIssue(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+		issues = append(issues, issue)
+	}
+	return issues, total, nil
+}
+
+// jiraFetch reads all issues of a Jira url. Search results of the REST
+// api are requested page by page using startAt.
+func jiraFetch(source string, config bugs.Config) ([]jiraIssue, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	var issues []jiraIssue
+	for {
+		req, err := http.NewRequest("GET", u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if config.JiraUser != "" || config.JiraApiToken != "" {
+			req.SetBasicAuth(config.JiraUser, config.JiraApiToken)
+		}
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", u.String(), resp.Status)
+		}
+		page, total, err := jiraParse(data)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, page...)
+		if len(page) == 0 || len(issues) >= total {
+			return issues, nil
+		}
+		q := u.Query()
+		q.Set("startAt", strconv.Itoa(len(issues)))
+		u.RawQuery = q.Encode()
+	}
+}
+
+// jiraImportIssues reads issues from a Jira url or a saved json or xml
+// export and creates them in the issues directory.
+func jiraImportIssues(source string, config bugs.Config) {
+	var issues []jiraIssue
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		issues, err = jiraFetch(source, config)
+	} else {
+		var data []byte
+		if data, err = ioutil.ReadFile(source); err == nil {
+			issues, _, err = jiraParse(data)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading Jira issues from %s: %s\n", source, err.Error())
+		return
+	}
+	fmt.Printf("Jira %s fetch count : %v\n", source, len(issues))
+	for _, issue := range issues {
+		if err := jiraImportIssue(issue, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error importing Jira issue %s: %s\n", issue.Key, err.Error())
+			return
+		}
+	}
+}
+
+// jiraDumpExt returns the extension of the dump of an issue or comment,
+// .xml for an xml export and .json for the REST api.
+func jiraDumpExt(dump []byte) string {
+	if len(dump) > 0 && dump[0] == '<' {
+		return ".xml"
+	}
+	return ".json"
+}
+
+// jiraImportIssue creates one issue like githubImportIssues, or updates
+// it and adds the comments it does not have yet when imported again.
+func jiraImportIssue(issue jiraIssue, config bugs.Config) error {
+	ititle := string(bugs.TitleToDir(fmt.Sprintf("%s%s%s", issue.Summary, "-", issue.Key)))
+	fmt.Printf("Importing issue %s\n", ititle)
+	b, err := bugs.New(ititle, config)
+	if os.IsExist(err) {
+		b = &bugs.Issue{Dir: bugs.FitDirer(config) + dops + bugs.Directory(ititle)}
+	} else if err != nil {
+		return err
+	}
+	b.DescriptionFileName = config.DescriptionFileName
+	defer bugs.InvalidateCache(b.Dir, config)
+	b.SetDescription(issue.Description, config)
+	if issue.Status != "" {
+		if err := b.SetStatus(issue.Status, config); err != nil {
+			return err
+		}
+	}
+	if issue.Priority != "" {
+		if err := b.SetPriority(issue.Priority, config); err != nil {
+			return err
+		}
+	}
+	if issue.Milestone != "" {
+		if err := b.SetMilestone(issue.Milestone, config); err != nil {
+			return err
+		}
+	}
+	if config.ImportXmlDump == true {
+		err := bugs.CurrentStore().WriteFile(string(b.Direr())+sops+"issue"+jiraDumpExt(issue.Xml), append(issue.Xml, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+	if err := b.SetIdentifier("Jira:"+issue.Key, config); err != nil {
+		return err
+	}
+	for _, label := range issue.Labels {
+		if err := b.TagIssue(bugs.TagBoolTrue(label), config); err != nil {
+			return err
+		}
+	}
+	for j, co := range issue.Comments {
+		if b.HasComment(co.Body) {
+			continue
+		}
+		b.CommentIssue(bugs.Comment{
+			Author: co.Author,
+			Time:   co.Created,
+			Body:   co.Body,
+			Order:  j + 1,
+			Xml:    co.Xml}, config)
+		if config.ImportXmlDump == true {
+			comname := "comment-" + string(bugs.ShortTitleToDir(co.Body)) + "-" + fmt.Sprintf("%v", j+1)
+			err := bugs.CurrentStore().WriteFile(string(b.Direr())+sops+comname+jiraDumpExt(co.Xml), append(co.Xml, '\n'))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package fitapp
+
+import (
+	"fmt"
+	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// jiraTestdata is read at init, tests may change the working directory.
+var jiraTestdata, _ = filepath.Abs("testdata")
+
+// jiratest imports source into the fit directory of dir and returns the
+// directory of issue PROJ-1.
+func jiratest(t *testing.T, dir, source string, config bugs.Config) string {
+	os.Chdir(dir)
+	os.MkdirAll(config.FitDirName, 0700)
+	if err := os.Setenv("FIT", dir); err != nil {
+		t.Fatal("Could not set environment variable: " + err.Error())
+	}
+	_, stderr := captureOutput(func() {
+		Import(argumentList{"--jira", source}, config)
+	}, t)
+	if stderr != "" {
+		t.Errorf("Unexpected error importing %s: %s", source, stderr)
+	}
+
+	b, err := bugs.LoadIssueByHeuristic("Jira:PROJ-1", config)
+	if err != nil {
+		t.Fatalf("Issue PROJ-1 not imported from %s: %s", source, err.Error())
+	}
+	if b.Title("") != "Crash on start PROJ 1" || b.Description() != "It crashes.\n" ||
+		b.Status() != "Open" || b.Priority() != "Major" || b.Milestone() != "1.0" ||
+		!b.HasTag(bugs.TagBoolTrue("cli")) || !b.HasTag(bugs.TagBoolTrue("crash")) {
+		t.Errorf("Unexpected issue PROJ-1 from %s: %s %q %s %s %s %v", source,
+			b.Title(""), b.Description(), b.Status(), b.Priority(), b.Milestone(), b.StringTags())
+	}
+	b2, err := bugs.LoadIssueByHeuristic("Jira:PROJ-2", config)
+	if err != nil || b2.Status() != "Done" || b2.Milestone() != "" {
+		t.Errorf("Unexpected issue PROJ-2 from %s: %v", source, err)
+	}
+	return string(b.Direr())
+}
+
+func TestJiraImport(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+
+	for _, fixture := range []string{"jira.json", "jira.xml"} {
+		tmp, err := ioutil.TempDir("", "jiratest")
+		if err != nil {
+			t.Fatal("Could not create temporary dir for test")
+		}
+		defer os.RemoveAll(tmp)
+		// a second import adds no comments
+		jiratest(t, tmp, filepath.Join(jiraTestdata, fixture), config)
+		dir := jiratest(t, tmp, filepath.Join(jiraTestdata, fixture), config)
+		comments, _ := filepath.Glob(dir + sops + "comment-*")
+		if len(comments) != 2 {
+			t.Errorf("Expected 2 comments from %s, got %v", fixture, comments)
+		}
+	}
+
+	together := config
+	together.ImportCommentsTogether = true
+	together.ImportXmlDump = true
+	tmp, err := ioutil.TempDir("", "jiratest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(tmp)
+	jiratest(t, tmp, filepath.Join(jiraTestdata, "jira.json"), together)
+	dir := jiratest(t, tmp, filepath.Join(jiraTestdata, "jira.json"), together)
+	data, _ := ioutil.ReadFile(dir + sops + "comments")
+	if string(data) != "Seen it too\n\nFixed in master\n" {
+		t.Errorf("Unexpected comments file %q", data)
+	}
+	if _, err := os.Stat(dir + sops + "issue.json"); err != nil {
+		t.Errorf("Expected issue.json with ImportXmlDump: %s", err.Error())
+	}
+	if _, err := os.Stat(dir + sops + "issue.xml"); err == nil {
+		t.Errorf("Unexpected issue.xml from the REST api")
+	}
+	dumps, _ := filepath.Glob(dir + sops + "comment-*.json")
+	if len(dumps) != 2 {
+		t.Errorf("Expected 2 comment dumps, got %v", dumps)
+	}
+
+	tmp, err = ioutil.TempDir("", "jiratest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(tmp)
+	dir = jiratest(t, tmp, filepath.Join(jiraTestdata, "jira.xml"), together)
+	if _, err := os.Stat(dir + sops + "issue.xml"); err != nil {
+		t.Errorf("Expected issue.xml from an xml export: %s", err.Error())
+	}
+}
+
+func TestJiraImportStore(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	config.ImportXmlDump = true
+	tmp, err := ioutil.TempDir("", "jiratest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	pwd, _ := os.Getwd()
+	defer func() {
+		os.Chdir(pwd)
+		os.RemoveAll(tmp)
+	}()
+	old := bugs.SetStore(bugs.NewMemStore())
+	defer bugs.SetStore(old)
+	bugs.CurrentStore().MkdirAll(tmp + sops + config.FitDirName)
+
+	// jiratest loads the issues through the cache
+	dir := jiratest(t, tmp, filepath.Join(jiraTestdata, "jira.json"), config)
+	if _, err := bugs.CurrentStore().Stat(dir + sops + "issue.json"); err != nil {
+		t.Errorf("Expected issue.json in the store: %s", err.Error())
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Errorf("Unexpected issue %s written past the store", dir)
+	}
+}
+
+func TestJiraImportRest(t *testing.T) {
+	config := bugs.Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	config.JiraUser = "jdoe"
+	config.JiraApiToken = "secret"
+	pwd, _ := os.Getwd()
+	defer os.Chdir(pwd)
+
+	data, _ := ioutil.ReadFile(filepath.Join(jiraTestdata, "jira.json"))
+	fixture := string(data)
+	first := fixture[:strings.Index(fixture, `        {
+            "key": "PROJ-2"`)]
+	first = strings.TrimRight(first, ", \n") + "]}"
+	second := `{"startAt": 1, "total": 2, "issues": [` + fixture[strings.Index(fixture, `{
+            "key": "PROJ-2"`):]
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "jdoe" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("jql") != "project=PROJ" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("startAt") {
+		case "":
+			fmt.Fprint(w, first)
+		case "1":
+			fmt.Fprint(w, second)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tmp, err := ioutil.TempDir("", "jiratest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(tmp)
+	jiratest(t, tmp, srv.URL+"/rest/api/2/search?jql=project=PROJ", config)
+}
@@ -78,6 +78,21 @@ func (args argumentList) GetAndRemoveArguments(argnames []string) (argumentList,
 	return retArgs, matches
 }
 
+// RemoveFlag returns args without flag, an argument without a value, and
+// if flag was one of them.
+func (args argumentList) RemoveFlag(flag string) (argumentList, bool) {
+	var rest argumentList
+	found := false
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return rest, found
+}
+
 // check will panic with an error
 func check(e error) {
 	if e != nil {
@@ -196,6 +211,11 @@ func SkipRootCheck(args *[]string) bool {
 			(*args)[1] == "help" {
 			ret = true
 		}
+	case 4:
+		if (*args)[1] == "env" &&
+			(*args)[2] == "--format" {
+			ret = true
+		}
 	}
 	return ret
 }
@@ -220,7 +240,7 @@ func removeFi(slice []os.FileInfo, i int) []os.FileInfo {
 // also in issues/utils.go
 func readIssues(dirname string) []os.FileInfo {
 	//var issueList []os.FileInfo
-	fis, _ := ioutil.ReadDir(string(dirname))
+	fis, _ := bugs.CurrentStore().ReadDir(string(dirname))
 	issueList := fis
 	for idx, fi := range issueList {
 		//Debug("debug fi " + string(fi.Name()) + "idx " + string(idx) + "\n")
@@ -48,4 +48,8 @@ func TestSkipRootCheck(t *testing.T) {
 	if !SkipRootCheck(&list) {
 		t.Error("SkipRootCheck 3 --help should return true")
 	}
+	list = []string{"bin", "env", "--format", "json"}
+	if !SkipRootCheck(&list) {
+		t.Error("SkipRootCheck 4 env --format should return true")
+	}
 }
@@ -0,0 +1,198 @@
+package issues
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cacheVersion changes when IssueRecord changes, older caches are rebuilt.
+const cacheVersion = 2
+
+// IssueCache is the cache file of a fit directory. Issues are in the
+// order of issue numbers.
+type IssueCache struct {
+	Version int
+	FitDir  string
+	Issues  []IssueRecord
+}
+
+// CacheFile returns the file of the cache of the fit directory. It is
+// kept in the .git or .hg directory of the repository, or next to the
+// fit directory without one, never in the fit directory itself.
+func CacheFile(config Config) string {
+	fitdir := string(FitDirer(config))
+	sum := sha1.Sum([]byte(fitdir))
+	name := "fit-cache-" + hex.EncodeToString(sum[:6]) + ".json"
+	root := filepath.Dir(fitdir)
+	for dir := root; ; dir = filepath.Dir(dir) {
+		for _, scmdir := range []string{".git", ".hg"} {
+			if fi, err := os.Stat(dir + sops + scmdir); err == nil && fi.IsDir() {
+				return dir + sops + scmdir + sops + name
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return root + sops + "." + name
+}
+
+// cacheJournal returns the file listing the issues whose fields or tags
+// changed since the cache file was written.
+func cacheJournal(config Config) string {
+	return CacheFile(config) + ".changed"
+}
+
+// InvalidateCache records that the fields or tags of the issue in dir
+// changed without adding or removing files of the issue directory, for
+// example by rewriting its Status file.
+func InvalidateCache(dir Directory, config Config) {
+	if !isOSStore() {
+		return
+	}
+	if _, err := os.Stat(CacheFile(config)); err != nil {
+		return
+	}
+	f, err := os.OpenFile(cacheJournal(config), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(string(dir.ShortNamer()) + "\n")
+}
+
+// readJournal moves the journal of changes aside and returns the issues
+// it lists and the file it was moved to.
+func readJournal(config Config) (map[string]bool, string) {
+	changed := map[string]bool{}
+	file := cacheJournal(config) + ".reading"
+	if os.Rename(cacheJournal(config), file) != nil {
+		return changed, ""
+	}
+	data, _ := ioutil.ReadFile(file)
+	for _, name := range strings.Split(string(data), "\n") {
+		if name != "" {
+			changed[name] = true
+		}
+	}
+	return changed, file
+}
+
+// readCache returns the cache file of config, empty when it is missing,
+// of another version or of another fit directory.
+func readCache(config Config) IssueCache {
+	cache := IssueCache{}
+	data, err := ioutil.ReadFile(CacheFile(config))
+	if err != nil || json.Unmarshal(data, &cache) != nil ||
+		cache.Version != cacheVersion || cache.FitDir != string(FitDirer(config)) {
+		return IssueCache{}
+	}
+	return cache
+}
+
+// writeCache replaces the cache file atomically. Errors are returned but
+// a cache that can not be written only makes lookups slower.
+func writeCache(cache IssueCache, config Config) error {
+	file := CacheFile(config)
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".fit-cache")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// updateCache validates the records of cache against the fit directory,
+// rereading the issues that changed or are listed in changed with
+// LoadIssueRecords, and returns whether any did.
+func updateCache(cache *IssueCache, changed map[string]bool, config Config) bool {
+	fitdir := FitDirer(config)
+	old := map[string]IssueRecord{}
+	for _, r := range cache.Issues {
+		old[r.Name] = r
+	}
+	fis, _ := CurrentStore().ReadDir(string(fitdir))
+	issues := []IssueRecord{}
+	var stale []int
+	var dirs []Directory
+	for _, fi := range fis {
+		if !fi.IsDir() {
+			continue
+		}
+		r, ok := old[fi.Name()]
+		if !ok || !r.DirTime.Equal(fi.ModTime()) || changed[fi.Name()] {
+			stale = append(stale, len(issues))
+			dirs = append(dirs, fitdir+dops+Directory(fi.Name()))
+		}
+		delete(old, fi.Name())
+		issues = append(issues, r)
+	}
+	for i, r := range LoadIssueRecords(dirs, 0, config) {
+		issues[stale[i]] = r
+	}
+	sort.Sort(byRecordDir(issues))
+	cache.Version = cacheVersion
+	cache.FitDir = string(fitdir)
+	cache.Issues = issues
+	return len(stale) > 0 || len(old) > 0
+}
+
+// CachedIssues returns the records of every issue in the order of
+// issue numbers. Only issues whose directory changed or that are in the
+// journal of changes are read again. Validating the cache only reads the
+// fit directory, so fields changed in place by other programs are only
+// seen after InvalidateCache or Reindex. Only the file system is cached,
+// issues of other stores are read every time.
+func CachedIssues(config Config) []IssueRecord {
+	if FitDirer(config) == "" {
+		return nil
+	}
+	if !isOSStore() {
+		cache := IssueCache{}
+		updateCache(&cache, nil, config)
+		return cache.Issues
+	}
+	cache := readCache(config)
+	changed, journal := readJournal(config)
+	if updateCache(&cache, changed, config) && writeCache(cache, config) != nil {
+		// keep the changes for the next try
+		for name := range changed {
+			InvalidateCache(Directory(name), config)
+		}
+	}
+	if journal != "" {
+		os.Remove(journal)
+	}
+	return cache.Issues
+}
+
+// Reindex rebuilds the cache file from every issue and returns the
+// number of issues.
+func Reindex(config Config) (int, error) {
+	cache := IssueCache{}
+	_, journal := readJournal(config)
+	updateCache(&cache, nil, config)
+	if journal != "" {
+		os.Remove(journal)
+	}
+	return len(cache.Issues), writeCache(cache, config)
+}
@@ -0,0 +1,188 @@
+package issues
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCachedIssues(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+	os.Mkdir(test.dir+sops+".git", 0755)
+
+	a, _ := New("First", config)
+	a.SetStatus("open", config)
+	a.SetIdentifier("abc", config)
+	b, _ := New("Second", config)
+	b.TagIssue("cli", config)
+
+	issues := CachedIssues(config)
+	file := CacheFile(config)
+	if !strings.HasPrefix(file, test.dir+sops+".git"+sops+"fit-cache-") {
+		t.Errorf("Unexpected cache file %s", file)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("Cache file not written: %s", err.Error())
+	}
+	// the same issue numbers as without the cache
+	dirs := readIssues(string(FitDirer(config)))
+	sort.Sort(byDir(dirs))
+	if len(issues) != len(dirs) {
+		t.Fatalf("Expected %d cached issues, got %+v", len(dirs), issues)
+	}
+	for i := range dirs {
+		if issues[i].Name != dirs[i].Name() {
+			t.Errorf("Issue %d: expected %s, got %s", i+1, dirs[i].Name(), issues[i].Name)
+		}
+	}
+	cached := map[string]IssueRecord{}
+	for _, e := range issues {
+		cached[e.Name] = e
+	}
+	if e := cached["First"]; e.Status != "open" || e.Identifier != "abc" || e.Title != "First" ||
+		strings.Join(e.Tags, ",") != "identifier:abc,status:open" {
+		t.Errorf("Unexpected cache entry %+v", e)
+	}
+	if e := cached["Second"]; !e.HasTag("cli") || e.HasTag("docs") {
+		t.Errorf("Unexpected tags of %+v", e)
+	}
+
+	// fields and tags changed by fit are read again
+	a.SetStatus("closed", config)
+	b.RemoveTag("cli", config)
+	c, _ := New("Third", config)
+	os.RemoveAll(string(c.Dir))
+	for _, e := range CachedIssues(config) {
+		switch e.Name {
+		case "First":
+			if e.Status != "closed" {
+				t.Errorf("Changed Status not read again %+v", e)
+			}
+		case "Second":
+			if e.HasTag("cli") {
+				t.Errorf("Removed tag still cached %+v", e)
+			}
+		case "Third":
+			t.Errorf("Removed issue still cached")
+		}
+	}
+	// files changed in place by others after InvalidateCache
+	ioutil.WriteFile(string(a.Dir)+sops+"Priority", []byte("high\n"), 0644)
+	ioutil.WriteFile(string(a.Dir)+sops+"Priority", []byte("low\n"), 0644)
+	CachedIssues(config)
+	ioutil.WriteFile(string(a.Dir)+sops+"Priority", []byte("high\n"), 0644)
+	for _, e := range CachedIssues(config) {
+		if e.Name == "First" && e.Priority != "low" {
+			t.Errorf("Expected the cached Priority before InvalidateCache, got %+v", e)
+		}
+	}
+	InvalidateCache(a.Dir, config)
+	for _, e := range CachedIssues(config) {
+		if e.Name == "First" && e.Priority != "high" {
+			t.Errorf("Changed Priority not read again %+v", e)
+		}
+	}
+	if _, err := os.Stat(cacheJournal(config)); err == nil {
+		t.Errorf("Journal of changes not removed")
+	}
+	// a Description rewritten in place
+	a.SetDescription("First description", config)
+	CachedIssues(config)
+	a.SetDescription("First description, longer", config)
+	for _, e := range CachedIssues(config) {
+		if e.Name == "First" && e.DescriptionSize != int64(len("First description, longer\n")) {
+			t.Errorf("Changed Description not read again %+v", e)
+		}
+	}
+	if x, err := LoadIssueByHeuristic("abc", config); err != nil || x.Dir != a.Dir {
+		t.Errorf("Unexpected issue %+v %v", x, err)
+	}
+	if found := FindIssuesByTag([]string{"status:closed"}, config); len(found) != 1 || found[0].Dir != a.Dir {
+		t.Errorf("Unexpected issues by tag %+v", found)
+	}
+
+	// an unreadable cache is rebuilt
+	ioutil.WriteFile(file, []byte("{"), 0644)
+	if n, err := Reindex(config); err != nil || n != 3 {
+		t.Errorf("Unexpected reindex %d %v", n, err)
+	}
+	if cache := readCache(config); len(cache.Issues) != 3 {
+		t.Errorf("Unexpected cache after reindex %+v", cache)
+	}
+}
+
+// benchmarkIssues creates n issues with fields and tags.
+func benchmarkIssues(b *testing.B, n int) (Config, func()) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "issues"
+	dir, err := ioutil.TempDir("", "cachebench")
+	if err != nil {
+		b.Fatal("Could not create temporary dir for benchmark")
+	}
+	pwd, _ := os.Getwd()
+	os.Chdir(dir)
+	os.Unsetenv("FIT")
+	os.Mkdir(dir+sops+".git", 0755)
+	os.Mkdir(dir+sops+config.FitDirName, 0755)
+	for i := 0; i < n; i++ {
+		issue, _ := New(fmt.Sprintf("Issue number %d", i), config)
+		issue.SetDescription("A description", config)
+		issue.SetStatus("open", config)
+		issue.SetIdentifier(fmt.Sprintf("id%d", i), config)
+		issue.TagIssue(TagBoolTrue(fmt.Sprintf("tag%d", i%10)), config)
+	}
+	return config, func() {
+		os.Chdir(pwd)
+		os.RemoveAll(dir)
+	}
+}
+
+func BenchmarkGetAllIssues(b *testing.B) {
+	config, done := benchmarkIssues(b, 1000)
+	defer done()
+	CachedIssues(config)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetAllIssues(config)
+	}
+}
+
+func BenchmarkGetAllIssuesNoCache(b *testing.B) {
+	config, done := benchmarkIssues(b, 1000)
+	defer done()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		os.Remove(CacheFile(config))
+		GetAllIssues(config)
+	}
+}
+
+func BenchmarkLoadIssueByIdentifier(b *testing.B) {
+	config, done := benchmarkIssues(b, 1000)
+	defer done()
+	CachedIssues(config)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadIssueByHeuristic("id999", config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindIssuesByTag(b *testing.B) {
+	config, done := benchmarkIssues(b, 1000)
+	defer done()
+	CachedIssues(config)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindIssuesByTag([]string{"tag3"}, config)
+	}
+}
@@ -47,6 +47,12 @@ type Config struct {
 	NewFieldLowerCase bool `json:"NewFieldLowerCase"`
 	// github.com/settings/tokens
 	GithubPersonalAccessToken string `json:"GithubPersonalAccessToken"`
+	// base url of the GitHub api (https://api.github.com/ default)
+	GithubApiUrl string `json:"GithubApiUrl"`
+	// Jira user name or email for the REST API of import --jira
+	JiraUser string `json:"JiraUser"`
+	// id.atlassian.com/manage/api-tokens or the Jira password
+	JiraApiToken string `json:"JiraApiToken"`
 	//* twilio.com/console "Dashboard" has the "account sid" public acct identifier
 	TwilioAccountSid string `json:"TwilioAccountSid"`
 	//* twilio "Auth Token" is the "Rest API Key" is for access
@@ -55,6 +61,23 @@ type Config struct {
 	TwilioPhoneNumberFrom string `json:"TwilioPhoneNumberFrom"`
 	//* base url for notifications
 	FitSite string `json:"FitSite"`
+	// base url of the twilio api (https://api.twilio.com/2010-04-01/Accounts/ default)
+	TwilioApiUrl string `json:"TwilioApiUrl"`
+	// host:port of the SMTP server sending email notifications
+	SmtpServer string `json:"SmtpServer"`
+	// SMTP user and password, no authentication when empty
+	SmtpUser     string `json:"SmtpUser"`
+	SmtpPassword string `json:"SmtpPassword"`
+	// From address of email notifications
+	NotifyFrom string `json:"NotifyFrom"`
+	// recipients of every notification, comma separated channel:address
+	// like email:ops@example.com, sms:+14155551212 or webhook:<url>
+	NotifyTo string `json:"NotifyTo"`
+	// urls receiving a signed json event of every issue change committed,
+	// comma separated
+	WebhookUrls string `json:"WebhookUrls"`
+	// secret key of the HMAC-SHA256 signature of webhook events
+	WebhookSecret string `json:"WebhookSecret"`
 	// fit directories always recursive (true) or need -r cli option (false, default)
 	MultipleFitDirs bool `json:"MultipleFitDirs"`
 	// close will add tag_status_close (true) or deletes issue (false, default)
@@ -170,6 +193,23 @@ func ConfigRead(bugYmls string, c *Config, progVersion string) (err error) {
 		} else {
 			c.GithubPersonalAccessToken = ""
 		}
+		//* GitHub Enterprise or another GitHub api base url
+		if temp.GithubApiUrl != "" {
+			c.GithubApiUrl = temp.GithubApiUrl
+		} else {
+			c.GithubApiUrl = ""
+		}
+		//* Jira user and api token for import of projects that need authentication
+		if temp.JiraUser != "" {
+			c.JiraUser = temp.JiraUser
+		} else {
+			c.JiraUser = ""
+		}
+		if temp.JiraApiToken != "" {
+			c.JiraApiToken = temp.JiraApiToken
+		} else {
+			c.JiraApiToken = ""
+		}
 		//* twilio.com/console "Dashboard" has the "account sid" public acct identifier
 		if temp.TwilioAccountSid != "" {
 			c.TwilioAccountSid = temp.TwilioAccountSid
@@ -194,6 +234,52 @@ func ConfigRead(bugYmls string, c *Config, progVersion string) (err error) {
 		} else {
 			c.FitSite = ""
 		}
+		//* twilio api or a stand in for tests
+		if temp.TwilioApiUrl != "" {
+			c.TwilioApiUrl = temp.TwilioApiUrl
+		} else {
+			c.TwilioApiUrl = ""
+		}
+		//* SMTP server, user and password for email notifications
+		if temp.SmtpServer != "" {
+			c.SmtpServer = temp.SmtpServer
+		} else {
+			c.SmtpServer = ""
+		}
+		if temp.SmtpUser != "" {
+			c.SmtpUser = temp.SmtpUser
+		} else {
+			c.SmtpUser = ""
+		}
+		if temp.SmtpPassword != "" {
+			c.SmtpPassword = temp.SmtpPassword
+		} else {
+			c.SmtpPassword = ""
+		}
+		//* From address of email notifications
+		if temp.NotifyFrom != "" {
+			c.NotifyFrom = temp.NotifyFrom
+		} else {
+			c.NotifyFrom = ""
+		}
+		//* recipients of every notification
+		if temp.NotifyTo != "" {
+			c.NotifyTo = temp.NotifyTo
+		} else {
+			c.NotifyTo = ""
+		}
+		//* urls of webhook events of committed changes
+		if temp.WebhookUrls != "" {
+			c.WebhookUrls = temp.WebhookUrls
+		} else {
+			c.WebhookUrls = ""
+		}
+		//* secret of the signature of webhook events
+		if temp.WebhookSecret != "" {
+			c.WebhookSecret = temp.WebhookSecret
+		} else {
+			c.WebhookSecret = ""
+		}
 		//* MultipleFitDirs: true or false,
 		//      Default false, need to use -r cli option
 		if temp.MultipleFitDirs {
@@ -241,10 +327,21 @@ TagKeyValue: false
 NewFieldAsTag: false
 NewFieldLowerCase: false
 GithubPersonalAccessToken:
+GithubApiUrl:
+JiraUser:
+JiraApiToken:
 TwilioAccountSid:
 TwilioAuthToken:
 TwilioPhoneNumberFrom:
 FitSite: https://github.com/<you>/<proj>/tree/master/<proj>/
+TwilioApiUrl:
+SmtpServer:
+SmtpUser:
+SmtpPassword:
+NotifyFrom:
+NotifyTo:
+WebhookUrls:
+WebhookSecret:
 MultipleFitDirs: false
 CloseStatusTag: false
 IdAbbreviate: false
@@ -0,0 +1,186 @@
+package issues
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date fields of issues. Reported is set by create, Closed by close and
+// Due and Started with the due command.
+const (
+	DateReported = "Reported"
+	DateDue      = "Due"
+	DateStarted  = "Started"
+	DateClosed   = "Closed"
+)
+
+// DateFields are the date fields of issues.
+var DateFields = []string{DateReported, DateDue, DateStarted, DateClosed}
+
+// dayLayout is the layout of a Date without a time of day and timeLayout
+// of a time, in UTC so that it is also a valid tag file name.
+const (
+	dayLayout  = "2006-01-02"
+	timeLayout = "2006-01-02T150405Z"
+)
+
+// dateLayouts are the layouts ParseDate accepts besides dayLayout and
+// timeLayout, in the time zone given unless the date has one.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04",
+	"2006-01-02 15:04:05 -0700", "2006-01-02 15:04 -0700",
+	"2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// relativeDate is a duration relative to now like 7d, -2w or 12h.
+var relativeDate = regexp.MustCompile(`^([+-]?)([0-9]+)([hdw])$`)
+
+// Date is the value of a date field, a time or a calendar day when Day
+// is true. Days are compared with times by the day of the time.
+type Date struct {
+	time.Time
+	Day bool
+}
+
+// ParseDate parses a date like 2026-11-01, 2026-11-01 17:00, RFC 3339
+// or a duration relative to now like 7d, -2w or 12h. Dates without an
+// offset are in the time zone of now, unless followed by the name of a
+// time zone like 2026-11-01 17:00 Europe/Paris.
+func ParseDate(value string, now time.Time) (Date, error) {
+	value = strings.TrimSpace(value)
+	loc := now.Location()
+	if i := strings.LastIndex(value, " "); i > 0 {
+		if name := value[i+1:]; name == "UTC" || strings.Contains(name, "/") {
+			zone, err := time.LoadLocation(name)
+			if err != nil {
+				return Date{}, fmt.Errorf("Unknown time zone %s", name)
+			}
+			value, loc = value[:i], zone
+		}
+	}
+	if m := relativeDate.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		switch m[3] {
+		case "h":
+			return Date{Time: now.Add(time.Duration(n) * time.Hour)}, nil
+		case "w":
+			n *= 7
+		}
+		y, mo, d := now.AddDate(0, 0, n).Date()
+		return Date{Time: time.Date(y, mo, d, 0, 0, 0, 0, loc), Day: true}, nil
+	}
+	if t, err := time.ParseInLocation(dayLayout, value, loc); err == nil {
+		return Date{Time: t, Day: true}, nil
+	}
+	if t, err := time.Parse(timeLayout, value); err == nil {
+		return Date{Time: t}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return Date{Time: t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("Invalid date %s, use YYYY-MM-DD[ HH:MM[:SS]][ zone] or a duration like 7d", value)
+}
+
+// String returns the date as it is written to date fields.
+func (d Date) String() string {
+	if d.Day {
+		return d.Format(dayLayout)
+	}
+	return d.UTC().Format(timeLayout)
+}
+
+// day returns the calendar day of the date, of a time in the local zone.
+func (d Date) day() string {
+	if d.Day {
+		return d.Format(dayLayout)
+	}
+	return d.Local().Format(dayLayout)
+}
+
+// Compare returns -1, 0 or 1 when d is before, the same as or after o.
+// When either is a day the days are compared.
+func (d Date) Compare(o Date) int {
+	switch {
+	case d.Day || o.Day:
+		return strings.Compare(d.day(), o.day())
+	case d.Before(o.Time):
+		return -1
+	case d.After(o.Time):
+		return 1
+	}
+	return 0
+}
+
+// Date returns the value of a date field of an issue, false when it is
+// not set or not a date.
+func (b Issue) Date(field string) (Date, bool) {
+	value := b.fielder(field)
+	if value == "" {
+		return Date{}, false
+	}
+	d, err := ParseDate(value, time.Now())
+	if err != nil {
+		// tag_due_<date> values are read in lower case
+		d, err = ParseDate(strings.ToUpper(value), time.Now())
+	}
+	return d, err == nil
+}
+
+// SetDate writes a date field of an issue with SetField, replacing the
+// tag file of an earlier date.
+func (b Issue) SetDate(field string, d Date, config Config) error {
+	if config.NewFieldAsTag {
+		if err := b.removeDateTags(field); err != nil {
+			return err
+		}
+	}
+	return b.SetField(field, d.String(), config)
+}
+
+// RemoveDate removes a date field of an issue.
+func (b Issue) RemoveDate(field string, config Config) error {
+	if err := b.removeDateTags(field); err != nil {
+		return err
+	}
+	dir := string(b.Direr())
+	for _, name := range []string{field, strings.ToLower(field)} {
+		if _, err := CurrentStore().Stat(dir + sops + name); err == nil {
+			if err := CurrentStore().Remove(dir + sops + name); err != nil {
+				return err
+			}
+		}
+	}
+	InvalidateCache(b.Direr(), config)
+	return nil
+}
+
+// removeDateTags removes the tag_<field>_<date> and tag_<field> files of
+// a date field.
+func (b Issue) removeDateTags(field string) error {
+	dir := string(b.Direr())
+	files, _ := storeGlob(dir, "tag_*")
+	for _, file := range files {
+		if k, _, _, _, err := b.tager(file); err == nil && strings.EqualFold(k, field) {
+			if err := CurrentStore().Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Overdue returns true when an issue that is not closed has a Due date
+// before now.
+func (b Issue) Overdue(now time.Time) bool {
+	due, ok := b.Date(DateDue)
+	if !ok || strings.EqualFold(strings.TrimSpace(b.Status()), "closed") {
+		return false
+	}
+	return due.Compare(Date{Time: now}) < 0
+}
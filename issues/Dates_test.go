@@ -0,0 +1,129 @@
+package issues
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("No time zone database")
+	}
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, paris)
+	for _, test := range []struct {
+		value, expected string
+		day             bool
+	}{
+		{"2026-11-01", "2026-11-01", true},
+		{"2026-11-01 17:00", "2026-11-01T160000Z", false},
+		{"2026-11-01 17:00 UTC", "2026-11-01T170000Z", false},
+		{"2026-11-01 17:00 America/New_York", "2026-11-01T220000Z", false},
+		{"2026-11-01T17:00:00+02:00", "2026-11-01T150000Z", false},
+		{"2026-11-01T150000Z", "2026-11-01T150000Z", false},
+		{"7d", "2026-10-25", true},
+		{"-2w", "2026-10-04", true},
+		{"12h", "2026-10-19T013000Z", false},
+	} {
+		d, err := ParseDate(test.value, now)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %s: %s", test.value, err.Error())
+		} else if d.String() != test.expected || d.Day != test.day {
+			t.Errorf("Parsing %s: expected %s, got %s", test.value, test.expected, d.String())
+		}
+	}
+	for _, value := range []string{"", "tomorrow", "2026-13-01", "2026-11-01 17:00 Nowhere/Else"} {
+		if _, err := ParseDate(value, now); err == nil {
+			t.Errorf("Expected an error parsing %q", value)
+		}
+	}
+
+	day, _ := ParseDate("2026-11-01", now)
+	evening, _ := ParseDate("2026-11-01 23:00", now)
+	morning, _ := ParseDate("2026-11-02 08:00", now)
+	if day.Compare(evening) != 0 || evening.Compare(morning) != -1 || morning.Compare(day) != 1 {
+		t.Errorf("Unexpected comparison of %s, %s and %s", day, evening, morning)
+	}
+}
+
+func TestIssueDates(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	now := time.Now()
+	date := func(value string) Date {
+		d, err := ParseDate(value, now)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %s: %s", value, err.Error())
+		}
+		return d
+	}
+	late, _ := New("Late", config)
+	late.SetDate(DateDue, date("-3d"), config)
+	late.SetDate(DateReported, date("-30d"), config)
+	soon, _ := New("Soon", config)
+	soon.SetDate(DateDue, date("2d"), config)
+	soon.SetDate(DateReported, date("-1d"), config)
+	later, _ := New("Later", config)
+	later.SetDate(DateDue, date("20d"), config)
+	done, _ := New("Done", config)
+	done.SetDate(DateDue, date("-5d"), config)
+	done.SetStatus("closed", config)
+
+	if d, ok := soon.Date(DateDue); !ok || d.String() != date("2d").String() {
+		t.Errorf("Unexpected due date %s", d)
+	}
+	if !late.Overdue(now) || soon.Overdue(now) || done.Overdue(now) {
+		t.Errorf("Unexpected overdue issues")
+	}
+
+	for _, test := range []struct {
+		query    string
+		expected []string
+	}{
+		{"overdue", []string{"Late"}},
+		{"due<7d", []string{"Done", "Late", "Soon"}},
+		{"due<7d AND NOT overdue AND NOT status:closed", []string{"Soon"}},
+		{"due>-2d", []string{"Later", "Soon"}},
+		{"reported>-7d", []string{"Soon"}},
+		{"due:" + date("20d").String(), []string{"Later"}},
+		{"reported: AND due:", []string{"Test Issue"}},
+		{"reported: AND NOT due:", []string{"Done", "Later"}},
+	} {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %s: %s", test.query, err.Error())
+		}
+		var titles []string
+		for _, b := range FindIssuesByQuery(q, config) {
+			titles = append(titles, b.Title(""))
+		}
+		sort.Strings(titles)
+		if strings.Join(titles, ", ") != strings.Join(test.expected, ", ") {
+			t.Errorf("Query %s: expected %v, got %v", test.query, test.expected, titles)
+		}
+	}
+
+	config.NewFieldAsTag = true
+	config.NewFieldLowerCase = true
+	started := date("2026-10-01 09:30")
+	soon.SetDate(DateStarted, date("2026-09-30"), config)
+	soon.SetDate(DateStarted, started, config)
+	if files, _ := storeGlob(string(soon.Direr()), "tag_started_*"); len(files) != 1 {
+		t.Errorf("Unexpected started tags %v", files)
+	}
+	if d, ok := soon.Date(DateStarted); !ok || !d.Equal(started.Time) {
+		t.Errorf("Unexpected started date %s, expected %s", d, started)
+	}
+	if err := soon.RemoveDate(DateStarted, config); err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if d, ok := soon.Date(DateStarted); ok {
+		t.Errorf("Unexpected started date %s after removing it", d)
+	}
+}
@@ -0,0 +1,267 @@
+package issues
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// IssueSnapshot is an issue as DiffIssues compares it, with its
+// Description and comments read along the record. Watchers are the
+// recipients watching the issue or a query matching it, which DiffIssues
+// does not compare.
+type IssueSnapshot struct {
+	IssueRecord
+	Description string
+	Comments    []Comment
+	Watchers    []string
+}
+
+// SnapshotIssues returns the snapshots of every issue of the current
+// Store in the order of issue numbers.
+func SnapshotIssues(config Config) []IssueSnapshot {
+	fitdir := FitDirer(config)
+	watches := QueryWatches(config)
+	var snapshots []IssueSnapshot
+	for _, r := range CachedIssues(config) {
+		b := r.issue(fitdir, config)
+		snapshots = append(snapshots, IssueSnapshot{
+			IssueRecord: r,
+			Description: b.Description(),
+			Comments:    b.Comments(),
+			Watchers:    issueWatchers(b, watches),
+		})
+	}
+	return snapshots
+}
+
+// plainTags returns the tags of a snapshot other than the fields, which
+// Issue.Tags lists as key:value tags too.
+func (s IssueSnapshot) plainTags() []string {
+	fields := map[string]bool{}
+	for k, v := range map[string]string{"status": s.Status, "priority": s.Priority, "milestone": s.Milestone, "identifier": s.Identifier} {
+		fields[k+":"+strings.ToLower(v)] = true
+	}
+	var tags []string
+	for _, tag := range s.Tags {
+		if !fields[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Kinds of IssueChange, in the order DiffIssues returns them.
+const (
+	IssueClosed  = "closed"
+	IssueCreated = "created"
+	IssueRenamed = "renamed"
+	IssueChanged = "changed"
+)
+
+// FieldChange is the transition of a field of an issue.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// IssueChange describes how an issue changed between two snapshots.
+// Old is nil for created issues and New is nil for issues whose
+// directory was removed, which are closed. OldName is only set when the
+// issue was renamed.
+type IssueChange struct {
+	Kind               string
+	Name               string
+	OldName            string
+	Title              string
+	Identifier         string
+	Fields             []FieldChange
+	TagsAdded          []string
+	TagsRemoved        []string
+	Comments           []Comment
+	DescriptionChanged bool
+	Old                *IssueSnapshot
+	New                *IssueSnapshot
+}
+
+// diffStrings returns the strings of b missing from a.
+func diffStrings(a, b []string) []string {
+	seen := map[string]bool{}
+	for _, s := range a {
+		seen[s] = true
+	}
+	var missing []string
+	for _, s := range b {
+		if !seen[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// defaultDescription returns the DefaultDescriptionFile template new
+// issues are created with, or "" without one.
+func defaultDescription(config Config) string {
+	if config.DefaultDescriptionFile == "" {
+		return ""
+	}
+	data, err := ioutil.ReadFile(config.FitYmlDir + sops + config.DefaultDescriptionFile)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// titleWords returns the lower case words of at least 3 letters or
+// digits of a title.
+func titleWords(title string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= 3 {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// similarTitles returns true when two titles share a word.
+func similarTitles(a, b string) bool {
+	return len(diffStrings(titleWords(b), titleWords(a))) < len(titleWords(a))
+}
+
+// sameIssue returns true when a removed and an added issue directory are
+// one renamed issue, with the same Identifier, or without identifiers
+// the same Description other than the template and a similar title.
+func sameIssue(old, new IssueSnapshot, template string) bool {
+	if old.Identifier != "" || new.Identifier != "" {
+		return old.Identifier == new.Identifier
+	}
+	description := strings.TrimSpace(old.Description)
+	return description != "" && description != strings.TrimSpace(template) &&
+		old.Description == new.Description &&
+		(similarTitles(old.Title, new.Title) || similarTitles(old.Name, new.Name))
+}
+
+// diffIssue returns the change between two snapshots of an issue, either
+// of which may be nil, and false when nothing changed.
+func diffIssue(old, new *IssueSnapshot) (IssueChange, bool) {
+	c := IssueChange{Old: old, New: new}
+	switch {
+	case old == nil:
+		c.Kind, c.Name, c.Title, c.Identifier = IssueCreated, new.Name, new.Title, new.Identifier
+		c.Comments = new.Comments
+		return c, true
+	case new == nil:
+		c.Kind, c.Name, c.Title, c.Identifier = IssueClosed, old.Name, old.Title, old.Identifier
+		return c, true
+	}
+	c.Name, c.Title, c.Identifier = new.Name, new.Title, new.Identifier
+	for _, f := range []FieldChange{
+		{"Status", old.Status, new.Status},
+		{"Priority", old.Priority, new.Priority},
+		{"Milestone", old.Milestone, new.Milestone},
+		{"Identifier", old.Identifier, new.Identifier},
+	} {
+		if f.Old != f.New {
+			c.Fields = append(c.Fields, f)
+		}
+	}
+	c.TagsAdded = diffStrings(old.plainTags(), new.plainTags())
+	c.TagsRemoved = diffStrings(new.plainTags(), old.plainTags())
+	seen := map[string]bool{}
+	for _, comment := range old.Comments {
+		seen[comment.File+"\x00"+comment.Body] = true
+	}
+	for _, comment := range new.Comments {
+		if !seen[comment.File+"\x00"+comment.Body] {
+			c.Comments = append(c.Comments, comment)
+		}
+	}
+	c.DescriptionChanged = old.Description != new.Description
+	switch {
+	case new.Closed() && !old.Closed():
+		c.Kind = IssueClosed
+	case old.Name != new.Name:
+		c.Kind = IssueRenamed
+	default:
+		c.Kind = IssueChanged
+	}
+	if old.Name != new.Name {
+		c.OldName = old.Name
+	}
+	changed := c.Kind != IssueChanged || len(c.Fields) > 0 || len(c.TagsAdded) > 0 ||
+		len(c.TagsRemoved) > 0 || len(c.Comments) > 0 || c.DescriptionChanged
+	return c, changed
+}
+
+// DiffIssues returns the changes from the snapshots old to the snapshots
+// new: created, closed, renamed and otherwise changed issues in that
+// order, each sorted by name. Issues are matched by directory name, and
+// a removed and an added directory with the same Identifier, or the same
+// Description and a similar title, are a renamed issue.
+func DiffIssues(old, new []IssueSnapshot, config Config) []IssueChange {
+	template := defaultDescription(config)
+	olds := map[string]*IssueSnapshot{}
+	for i := range old {
+		olds[old[i].Name] = &old[i]
+	}
+	news := map[string]*IssueSnapshot{}
+	for i := range new {
+		news[new[i].Name] = &new[i]
+	}
+	var removed, added []*IssueSnapshot
+	for i := range old {
+		if news[old[i].Name] == nil {
+			removed = append(removed, &old[i])
+		}
+	}
+	for i := range new {
+		if olds[new[i].Name] == nil {
+			added = append(added, &new[i])
+		}
+	}
+
+	var changes []IssueChange
+	add := func(o, n *IssueSnapshot) {
+		if c, ok := diffIssue(o, n); ok {
+			changes = append(changes, c)
+		}
+	}
+	for _, o := range removed {
+		renamed := false
+		for i, n := range added {
+			if n != nil && sameIssue(*o, *n, template) {
+				add(o, n)
+				added[i] = nil
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			add(o, nil)
+		}
+	}
+	for _, n := range added {
+		if n != nil {
+			add(nil, n)
+		}
+	}
+	for i := range new {
+		if o := olds[new[i].Name]; o != nil {
+			add(o, &new[i])
+		}
+	}
+
+	order := map[string]int{IssueClosed: 0, IssueCreated: 1, IssueRenamed: 2, IssueChanged: 3}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return order[changes[i].Kind] < order[changes[j].Kind]
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
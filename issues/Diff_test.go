@@ -0,0 +1,124 @@
+package issues
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDiffIssues(t *testing.T) {
+	snapshot := func(name, id, status, desc string, tags []string, comments ...string) IssueSnapshot {
+		s := IssueSnapshot{Description: desc}
+		s.Name, s.Title, s.Identifier, s.Status, s.Tags = name, Directory(name).ToTitle(), id, status, tags
+		for _, body := range comments {
+			s.Comments = append(s.Comments, Comment{Body: body, File: "comment-" + body})
+		}
+		return s
+	}
+	old := []IssueSnapshot{
+		snapshot("Removed", "", "open", "gone", nil),
+		snapshot("Old-title", "", "", "same text", nil),
+		snapshot("Fixed", "", "open", "bug", []string{"bug", "ui"}, "first"),
+		snapshot("Id-issue", "GH-1", "", "one", nil),
+		snapshot("Unchanged", "", "open", "text", []string{"ui"}),
+		snapshot("Edited", "", "", "before", nil),
+	}
+	new := []IssueSnapshot{
+		snapshot("New-title", "", "", "same text", nil),
+		snapshot("Fixed", "", "closed", "bug", []string{"bug", "security"}, "first", "second"),
+		snapshot("Renamed-id-issue", "GH-1", "", "two", nil),
+		snapshot("Unchanged", "", "open", "text", []string{"ui"}),
+		snapshot("Edited", "", "", "after", nil),
+		snapshot("Added", "", "open", "new", nil),
+	}
+	changes := DiffIssues(old, new, Config{})
+	var kinds, names []string
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind)
+		names = append(names, c.OldName+">"+c.Name)
+	}
+	if !reflect.DeepEqual(kinds, []string{IssueClosed, IssueClosed, IssueCreated, IssueRenamed, IssueRenamed, IssueChanged}) ||
+		!reflect.DeepEqual(names, []string{">Fixed", ">Removed", ">Added", "Old-title>New-title", "Id-issue>Renamed-id-issue", ">Edited"}) {
+		t.Fatalf("Unexpected changes %v %v", kinds, names)
+	}
+	fixed := changes[0]
+	if !reflect.DeepEqual(fixed.Fields, []FieldChange{{"Status", "open", "closed"}}) ||
+		!reflect.DeepEqual(fixed.TagsAdded, []string{"security"}) || !reflect.DeepEqual(fixed.TagsRemoved, []string{"ui"}) ||
+		len(fixed.Comments) != 1 || fixed.Comments[0].Body != "second" || fixed.DescriptionChanged {
+		t.Errorf("Unexpected change of a closed issue %+v", fixed)
+	}
+	if removed := changes[1]; removed.New != nil || removed.Old == nil || removed.Title != "Removed" {
+		t.Errorf("Unexpected change of a removed issue %+v", removed)
+	}
+	if added := changes[2]; added.Old != nil || added.New == nil {
+		t.Errorf("Unexpected change of an added issue %+v", added)
+	}
+	if renamed := changes[4]; !renamed.DescriptionChanged || renamed.Identifier != "GH-1" {
+		t.Errorf("Unexpected change of a renamed issue %+v", renamed)
+	}
+	if edited := changes[5]; !edited.DescriptionChanged || len(edited.Fields) != 0 {
+		t.Errorf("Unexpected change of an edited issue %+v", edited)
+	}
+	if changes := DiffIssues(new, new, Config{}); len(changes) != 0 {
+		t.Errorf("Unexpected changes of the same issues %+v", changes)
+	}
+}
+
+func TestDiffIssuesRenamed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "difftest")
+	if err != nil {
+		t.Fatal("Could not create temporary dir for test")
+	}
+	defer os.RemoveAll(dir)
+	config := Config{FitYmlDir: dir, DefaultDescriptionFile: "DescriptionTemplate.txt"}
+	ioutil.WriteFile(dir+sops+config.DefaultDescriptionFile, []byte("Steps to reproduce:\n"), 0644)
+	snapshot := func(name, desc string) IssueSnapshot {
+		s := IssueSnapshot{Description: desc}
+		s.Name, s.Title = name, Directory(name).ToTitle()
+		return s
+	}
+	for _, test := range []struct {
+		old, new IssueSnapshot
+		renamed  bool
+	}{
+		{snapshot("Crash-on-start", "It crashes\n"), snapshot("Crash-at-startup", "It crashes\n"), true},
+		// unrelated issues with the same Description
+		{snapshot("Crash-on-start", "It crashes\n"), snapshot("Slow-menus", "It crashes\n"), false},
+		// the template of issues not described yet
+		{snapshot("Crash-on-start", "Steps to reproduce:\n"), snapshot("Crash-at-startup", "Steps to reproduce:\n"), false},
+		{snapshot("Crash-on-start", ""), snapshot("Crash-at-startup", ""), false},
+	} {
+		changes := DiffIssues([]IssueSnapshot{test.old}, []IssueSnapshot{test.new}, config)
+		if renamed := len(changes) == 1 && changes[0].Kind == IssueRenamed; renamed != test.renamed {
+			t.Errorf("Unexpected changes from %s to %s: %+v", test.old.Name, test.new.Name, changes)
+		}
+	}
+}
+
+func TestSnapshotIssues(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	b, _ := New("Snapshot issue", config)
+	b.SetDescription("Some text", config)
+	b.SetStatus("open", config)
+	b.CommentIssue(Comment{Author: "Tester", Body: "A comment"}, config)
+	var snapshot *IssueSnapshot
+	snapshots := SnapshotIssues(config)
+	for i := range snapshots {
+		if snapshots[i].Name == "Snapshot-issue" {
+			snapshot = &snapshots[i]
+		}
+	}
+	if snapshot == nil {
+		t.Fatalf("Snapshot issue not found")
+	}
+	if snapshot.Description != "Some text\n" || snapshot.Status != "open" ||
+		len(snapshot.Comments) != 1 || snapshot.Comments[0].Body != "A comment" || snapshot.Closed() {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+}
@@ -11,14 +11,15 @@ import (
 type Directory string
 
 func findFitDir(dir string, config *Config) Directory {
-	if dirinfo, err := os.Stat(dir); err == nil && dirinfo.IsDir() {
-		if dirinfo, err = os.Stat(dir + sops + "fit"); err == nil && dirinfo.IsDir() {
+	s := CurrentStore()
+	if dirinfo, err := s.Stat(dir); err == nil && dirinfo.IsDir() {
+		if dirinfo, err = s.Stat(dir + sops + "fit"); err == nil && dirinfo.IsDir() {
 			// has a fit dir
 			config.FitDir = dir
 			config.FitDirName = "fit"
 			os.Chdir(dir)
 			return Directory(dir)
-		} else if dirinfo, err = os.Stat(dir + sops + "issues"); err == nil && dirinfo.IsDir() {
+		} else if dirinfo, err = s.Stat(dir + sops + "issues"); err == nil && dirinfo.IsDir() {
 			// has an issues dir
 			config.FitDir = dir
 			config.FitDirName = "issues"
@@ -121,10 +122,10 @@ func (d Directory) ToTitle() string {
 	})
 }
 
-// ModTime returns the last modified time from the file system.
+// ModTime returns the last modified time from the Store of issues.
 func (d Directory) ModTime() time.Time {
 	var t time.Time
-	stat, err := os.Stat(string(d))
+	stat, err := CurrentStore().Stat(string(d))
 	if err != nil {
 		panic("Directory " + string(d) + " stat error : " + err.Error())
 	}
@@ -133,9 +134,7 @@ func (d Directory) ModTime() time.Time {
 		return stat.ModTime()
 	}
 
-	dir, _ := os.Open(string(d))
-	defer dir.Close() // discards error for now
-	files, _ := dir.Readdir(-1)
+	files, _ := CurrentStore().ReadDir(string(d)) // discards error for now
 	if len(files) == 0 {
 		t = stat.ModTime()
 	}
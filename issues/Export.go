@@ -0,0 +1,293 @@
+package issues
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrIssueExists is returned by ImportTree when an issue directory
+// already exists. Nothing is written in that case.
+var ErrIssueExists = errors.New("Issue directory already exists")
+
+// ExportFile is one file or subdirectory of an exported issue.
+// Content is the file as text, or base64 when Encoding is "base64".
+type ExportFile struct {
+	Path     string
+	Dir      bool   `json:",omitempty"`
+	Content  string `json:",omitempty"`
+	Encoding string `json:",omitempty"`
+	Mode     os.FileMode
+	ModTime  time.Time
+}
+
+// ExportIssue holds every file of one issue directory, including the
+// Description, fields, tag_* files, the tags/ subdir and comments.
+type ExportIssue struct {
+	Dir     string
+	Title   string
+	ModTime time.Time
+	Files   []ExportFile
+}
+
+// ExportTree is a whole fit directory. Files holds anything next to the
+// issue directories.
+type ExportTree struct {
+	Issues []ExportIssue
+	Files  []ExportFile `json:",omitempty"`
+}
+
+// exportFile reads the file at path, stored as rel in the export.
+func exportFile(path, rel string, info os.FileInfo) (ExportFile, error) {
+	f := ExportFile{
+		Path:    filepath.ToSlash(rel),
+		Dir:     info.IsDir(),
+		Mode:    info.Mode().Perm(),
+		ModTime: info.ModTime(),
+	}
+	if info.IsDir() {
+		return f, nil
+	}
+	content, err := CurrentStore().ReadFile(path)
+	if err != nil {
+		return f, err
+	}
+	if utf8.Valid(content) {
+		f.Content = string(content)
+	} else {
+		f.Content = base64.StdEncoding.EncodeToString(content)
+		f.Encoding = "base64"
+	}
+	return f, nil
+}
+
+// exportFiles returns the files and subdirectories below dir in path order.
+func exportFiles(dir string) ([]ExportFile, error) {
+	files := []ExportFile{}
+	var walk func(path, rel string) error
+	walk = func(path, rel string) error {
+		fis, err := CurrentStore().ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, fi := range fis {
+			f, err := exportFile(path+sops+fi.Name(), filepath.Join(rel, fi.Name()), fi)
+			files = append(files, f)
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				if err := walk(path+sops+fi.Name(), filepath.Join(rel, fi.Name())); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return files, walk(dir, "")
+}
+
+// ExportIssues reads every issue of the fit directory in directory name
+// order, so that ImportTree can rebuild it.
+func ExportIssues(config Config) (ExportTree, error) {
+	fitdir := string(FitDirer(config))
+	tree := ExportTree{Issues: []ExportIssue{}}
+	fis, err := CurrentStore().ReadDir(fitdir)
+	if err != nil {
+		return tree, err
+	}
+	for _, fi := range fis {
+		path := fitdir + sops + fi.Name()
+		if !fi.IsDir() {
+			f, err := exportFile(path, fi.Name(), fi)
+			if err != nil {
+				return tree, err
+			}
+			tree.Files = append(tree.Files, f)
+			continue
+		}
+		files, err := exportFiles(path)
+		if err != nil {
+			return tree, err
+		}
+		tree.Issues = append(tree.Issues, ExportIssue{
+			Dir:     fi.Name(),
+			Title:   Directory(fi.Name()).ToTitle(),
+			ModTime: fi.ModTime(),
+			Files:   files,
+		})
+	}
+	return tree, nil
+}
+
+// importPath returns the file name of path below dir. Paths leaving dir
+// are refused so an export can not write outside the fit directory.
+func importPath(dir, path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if path == "" || filepath.IsAbs(clean) || clean == "." || clean == ".." ||
+		strings.HasPrefix(clean, ".."+sops) {
+		return "", fmt.Errorf("Invalid path in export: %s", path)
+	}
+	return dir + sops + clean, nil
+}
+
+// importFiles writes files below dir, then sets their modes and
+// modification times, children before their parent directories. Modes
+// and times are only set on the file system.
+func importFiles(dir string, files []ExportFile) error {
+	s := CurrentStore()
+	paths := make([]string, len(files))
+	for i, f := range files {
+		path, err := importPath(dir, f.Path)
+		if err != nil {
+			return err
+		}
+		paths[i] = path
+		if f.Dir {
+			if err := s.MkdirAll(path); err != nil {
+				return err
+			}
+			continue
+		}
+		content := []byte(f.Content)
+		if f.Encoding == "base64" {
+			if content, err = base64.StdEncoding.DecodeString(f.Content); err != nil {
+				return fmt.Errorf("Invalid base64 in %s: %s", f.Path, err.Error())
+			}
+		} else if f.Encoding != "" {
+			return fmt.Errorf("Unknown encoding %s of %s", f.Encoding, f.Path)
+		}
+		if err := s.MkdirAll(filepath.Dir(path)); err != nil {
+			return err
+		}
+		if err := s.WriteFile(path, content); err != nil {
+			return err
+		}
+	}
+	if !isOSStore() {
+		return nil
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].Mode != 0 {
+			os.Chmod(paths[i], files[i].Mode)
+		}
+		if !files[i].ModTime.IsZero() {
+			os.Chtimes(paths[i], files[i].ModTime, files[i].ModTime)
+		}
+	}
+	return nil
+}
+
+// idNextNumber returns the next identifier of a .fit_idnext_<i> file.
+func idNextNumber(name string) (int, bool) {
+	if !strings.HasPrefix(name, ".fit_idnext_") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(name, ".fit_idnext_"))
+	return i, err == nil
+}
+
+// mergeTreeFiles merges the files of an export next to the issue
+// directories with those of the fit directory and returns the files to
+// write. The .fit_idnext_ file with the highest identifier is kept and
+// the query watches of both are kept, other files already present are
+// not replaced.
+func mergeTreeFiles(fitdir string, files []ExportFile) ([]ExportFile, error) {
+	var write []ExportFile
+	for _, f := range files {
+		path, err := importPath(fitdir, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := idNextNumber(f.Path); ok {
+			present, _ := storeGlob(fitdir, ".fit_idnext_*")
+			highest, highestPath := 0, ""
+			for _, p := range present {
+				if i, ok := idNextNumber(filepath.Base(p)); ok && i >= highest {
+					highest, highestPath = i, p
+				}
+			}
+			switch {
+			case highestPath == "":
+				write = append(write, f)
+			case next > highest:
+				if err := CurrentStore().Rename(highestPath, path); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if _, err := CurrentStore().Stat(path); err != nil {
+			write = append(write, f)
+			continue
+		}
+		if f.Path == QueryWatchesFile && f.Encoding == "" {
+			lines := readLines(path)
+			for _, line := range strings.Split(f.Content, "\n") {
+				if line = strings.TrimSpace(line); line != "" && !findArrayString(lines, line) {
+					lines = append(lines, line)
+				}
+			}
+			if err := writeLines(path, lines); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return write, nil
+}
+
+// ImportTree rebuilds the issues and files of tree in the fit directory
+// with their contents, modes and modification times. Nothing is written
+// when an issue directory already exists. Files next to the issue
+// directories are merged with those present, see mergeTreeFiles.
+func ImportTree(tree ExportTree, config Config) error {
+	fitdir := string(FitDirer(config))
+	for _, issue := range tree.Issues {
+		path, err := importPath(fitdir, issue.Dir)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(issue.Dir, "/") || strings.Contains(issue.Dir, sops) {
+			return fmt.Errorf("Invalid issue directory in export: %s", issue.Dir)
+		}
+		if _, err := CurrentStore().Stat(path); err == nil {
+			return fmt.Errorf("%w: %s", ErrIssueExists, issue.Dir)
+		}
+		for _, f := range issue.Files {
+			if _, err := importPath(path, f.Path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range tree.Files {
+		if _, err := importPath(fitdir, f.Path); err != nil {
+			return err
+		}
+	}
+	files, err := mergeTreeFiles(fitdir, tree.Files)
+	if err != nil {
+		return err
+	}
+	if err := importFiles(fitdir, files); err != nil {
+		return err
+	}
+	for _, issue := range tree.Issues {
+		path := fitdir + sops + issue.Dir
+		if err := CurrentStore().MkdirAll(path); err != nil {
+			return err
+		}
+		if err := importFiles(path, issue.Files); err != nil {
+			return err
+		}
+		if !issue.ModTime.IsZero() && isOSStore() {
+			os.Chtimes(path, issue.ModTime, issue.ModTime)
+		}
+	}
+	return nil
+}
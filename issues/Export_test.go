@@ -0,0 +1,133 @@
+package issues
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "issues"
+	test := tester{}
+	test.Setup()
+	defer test.Teardown()
+
+	b := test.issue
+	b.SetDescription("line one\nline two", config)
+	b.SetStatus("open", config)
+	b.TagIssue(TagBoolTrue("cli"), config)
+	dir := string(b.Direr())
+	os.MkdirAll(dir+sops+"tags", 0755)
+	ioutil.WriteFile(dir+sops+"tags"+sops+"old", []byte(""), 0644)
+	ioutil.WriteFile(dir+sops+"tag_priority_high", []byte(""), 0644)
+	ioutil.WriteFile(dir+sops+"comment-1", []byte("a comment\n"), 0600)
+	ioutil.WriteFile(dir+sops+"screenshot.png", []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}, 0644)
+	ioutil.WriteFile(string(FitDirer(config))+sops+".notes", []byte("notes\n"), 0644)
+	old := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	os.Chtimes(dir+sops+"comment-1", old, old)
+	os.Chtimes(dir, old, old)
+
+	tree, err := ExportIssues(config)
+	if err != nil {
+		t.Fatalf("Unexpected export error %s", err.Error())
+	}
+	if len(tree.Issues) != 1 || tree.Issues[0].Title != "Test Issue" || len(tree.Files) != 1 {
+		t.Fatalf("Unexpected export %+v", tree)
+	}
+	encoded, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ExportTree
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ImportTree(decoded, config); err == nil {
+		t.Error("Expected an error importing over an existing issue")
+	}
+	fitdir := string(FitDirer(config))
+	os.RemoveAll(fitdir)
+	os.Mkdir(fitdir, 0755)
+	if err := ImportTree(decoded, config); err != nil {
+		t.Fatalf("Unexpected import error %s", err.Error())
+	}
+	again, err := ExportIssues(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compare the json encodings, time.Time values differ in location.
+	reencoded, _ := json.Marshal(again)
+	if string(reencoded) != string(encoded) {
+		t.Errorf("Import did not rebuild the tree\nExpected: %s\nGot: %s", encoded, reencoded)
+	}
+	if png, _ := ioutil.ReadFile(dir + sops + "screenshot.png"); string(png) != "\x89PNG\xff\x00" {
+		t.Errorf("Unexpected binary file %q", png)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.ModTime().Equal(old) {
+		t.Errorf("Issue modification time not restored %v", fi)
+	}
+
+	evil := ExportTree{Issues: []ExportIssue{{Dir: "evil", Files: []ExportFile{{Path: "../../escape"}}}}}
+	if err := ImportTree(evil, config); err == nil {
+		t.Error("Expected an error for a path outside the issue")
+	}
+	if _, err := os.Stat(fitdir + sops + "evil"); err == nil {
+		t.Error("Expected nothing written for an invalid export")
+	}
+}
+
+func TestImportTreeExisting(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "issues"
+	test := tester{}
+	test.Setup()
+	defer test.Teardown()
+	fitdir := string(FitDirer(config))
+	ioutil.WriteFile(fitdir+sops+".fit_idnext_1003", []byte("\n"), 0644)
+	ioutil.WriteFile(fitdir+sops+QueryWatchesFile, []byte("email:a@example.com status:open\n"), 0644)
+	ioutil.WriteFile(fitdir+sops+".notes", []byte("mine\n"), 0644)
+
+	tree := ExportTree{
+		Issues: []ExportIssue{{Dir: "Imported-issue", Files: []ExportFile{{Path: "Description", Content: "text\n"}}}},
+		Files: []ExportFile{
+			{Path: ".fit_idnext_1005", Content: "\n"},
+			{Path: QueryWatchesFile, Content: "email:b@example.com tag:cli\nemail:a@example.com status:open\n"},
+			{Path: ".notes", Content: "theirs\n"},
+		},
+	}
+	if err := ImportTree(tree, config); err != nil {
+		t.Fatalf("Unexpected error importing into an existing fit directory %s", err.Error())
+	}
+	if next, _ := storeGlob(fitdir, ".fit_idnext_*"); len(next) != 1 || next[0] != fitdir+sops+".fit_idnext_1005" {
+		t.Errorf("Unexpected next identifier files %v", next)
+	}
+	if watches, _ := ioutil.ReadFile(fitdir + sops + QueryWatchesFile); string(watches) != "email:a@example.com status:open\nemail:b@example.com tag:cli\n" {
+		t.Errorf("Unexpected query watches %q", watches)
+	}
+	if notes, _ := ioutil.ReadFile(fitdir + sops + ".notes"); string(notes) != "mine\n" {
+		t.Errorf("Unexpected replaced file %q", notes)
+	}
+	if desc, _ := ioutil.ReadFile(fitdir + sops + "Imported-issue" + sops + "Description"); string(desc) != "text\n" {
+		t.Errorf("Unexpected imported issue %q", desc)
+	}
+
+	// a lower next identifier is not taken, an issue present is refused
+	tree.Files = []ExportFile{{Path: ".fit_idnext_1001", Content: "\n"}}
+	tree.Issues = []ExportIssue{{Dir: "Another-issue"}}
+	if err := ImportTree(tree, config); err != nil {
+		t.Fatalf("Unexpected import error %s", err.Error())
+	}
+	if next, _ := storeGlob(fitdir, ".fit_idnext_*"); len(next) != 1 || next[0] != fitdir+sops+".fit_idnext_1005" {
+		t.Errorf("Unexpected next identifier files %v", next)
+	}
+	if err := ImportTree(tree, config); !errors.Is(err, ErrIssueExists) {
+		t.Errorf("Expected ErrIssueExists importing an issue present, got %v", err)
+	}
+}
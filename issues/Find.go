@@ -1,8 +1,6 @@
 package issues
 
 import (
-	"fmt"
-	"io/ioutil"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,21 +22,13 @@ func (b IssueNotFoundError) Error() string {
 
 // FindIssuesByTag returns an array of tagged issues.
 func FindIssuesByTag(tags []string, config Config) []Issue {
-	root := RootDirer(&config)
-	issuesroot := FitDirer(config)
-	issues := readIssues(string(issuesroot))
-	sort.Sort(byDir(issues))
-
+	fitdir := FitDirer(config)
 	var bugs []Issue
-	for _, issue := range issues { // idx not needed
-		if issue.IsDir() == true {
-			bug := Issue{}
-			bug.LoadIssue(root+dops+Directory(config.FitDirName)+dops+Directory(issue.Name()), config)
-			for _, tag := range tags {
-				if bug.HasTag(TagBoolTrue(tag)) {
-					bugs = append(bugs, bug)
-					break
-				}
+	for _, e := range CachedIssues(config) {
+		for _, tag := range tags {
+			if e.HasTag(TagBoolTrue(tag)) {
+				bugs = append(bugs, e.load(fitdir, config))
+				break
 			}
 		}
 	}
@@ -48,10 +38,21 @@ func FindIssuesByTag(tags []string, config Config) []Issue {
 	return bugs
 }
 
+// FindIssuesByQuery returns an array of issues matching a query.
+func FindIssuesByQuery(query Query, config Config) []Issue {
+	var bugs []Issue
+	for _, bug := range GetAllIssues(config) {
+		if query.Match(bug) {
+			bugs = append(bugs, bug)
+		}
+	}
+	return bugs
+}
+
 // LoadIssueByDirectory returns an issue from the directory name.
 func LoadIssueByDirectory(dir string, config Config) (*Issue, error) {
 	root := RootDirer(&config)
-	_, err := ioutil.ReadDir(string(root) + sops + config.FitDirName + sops + dir)
+	_, err := CurrentStore().ReadDir(string(root) + sops + config.FitDirName + sops + dir)
 	if err != nil {
 		return nil, IssueNotFoundError("Not found " + dir)
 	}
@@ -62,14 +63,7 @@ func LoadIssueByDirectory(dir string, config Config) (*Issue, error) {
 
 // LoadIssueByHeuristic returns an issue.
 func LoadIssueByHeuristic(id string, config Config) (*Issue, error) {
-	root := RootDirer(&config)
-	issuesroot := FitDirer(config)
-	issues := readIssues(string(issuesroot))
-	sort.Sort(byDir(issues))
-	//fmt.Printf("debug Found %v files\n", len(issues)) //, strings.Join(issues, ", "))
-	//for _, issue := range issues {                    // idx not needed
-	//	fmt.Printf("debug %v\n", issue.Name())
-	//}
+	issues := CachedIssues(config)
 
 	if idx, err := strconv.Atoi(id); err == nil { // && idx > 0 && idx <= len(issues) {
 		// check for an assigned id before index
@@ -82,21 +76,20 @@ func LoadIssueByHeuristic(id string, config Config) (*Issue, error) {
 	}
 	// just a string, not an string of integers
 
-	var candidate *Issue
-	for _, issue := range issues { // idx not needed
-		if issue.IsDir() == true {
+	var candidate *IssueRecord
+	for i, e := range issues { // idx not needed
+		if e.Identifier == id {
 			bug := Issue{}
-			bug.LoadIssue(root+dops+Directory(config.FitDirName)+dops+Directory(issue.Name()), config)
-			if bugid := bug.Identifier(); bugid == id {
-				return &bug, nil
-			} else if strings.Index(bugid, id) >= 0 {
-				candidate = &bug
-			}
-
+			bug.LoadIssue(e.Issue(config).Dir, config)
+			return &bug, nil
+		} else if strings.Index(e.Identifier, id) >= 0 {
+			candidate = &issues[i]
 		}
 	}
 	if candidate != nil {
-		return candidate, nil
+		bug := Issue{}
+		bug.LoadIssue(candidate.Issue(config).Dir, config)
+		return &bug, nil
 	}
 	return nil, IssueNotFoundError("Not found " + id)
 }
@@ -122,18 +115,11 @@ func LoadIssueByHeuristic(id string, config Config) (*Issue, error) {
 
 // LoadIssueByIdentifier returns an issue from a string Identifier
 func LoadIssueByIdentifier(id string, config Config) (*Issue, error) {
-	root := RootDirer(&config)
-	issuesroot := FitDirer(config)
-	issues := readIssues(string(issuesroot))
-	sort.Sort(byDir(issues))
-
-	for _, issue := range issues { // idx not needed
-		if issue.IsDir() == true {
+	for _, e := range CachedIssues(config) { // idx not needed
+		if e.Identifier == id {
 			bug := Issue{}
-			bug.LoadIssue(root+dops+Directory(config.FitDirName)+dops+Directory(issue.Name()), config)
-			if bug.Identifier() == id {
-				return &bug, nil
-			}
+			bug.LoadIssue(e.Issue(config).Dir, config)
+			return &bug, nil
 		}
 	}
 	return nil, IssueNotFoundError("No issue named " + id)
@@ -141,36 +127,22 @@ func LoadIssueByIdentifier(id string, config Config) (*Issue, error) {
 
 // LoadIssueByIndex returns an issue from an int index.
 func LoadIssueByIndex(idx int, config Config) (*Issue, error) {
-	root := RootDirer(&config)
-	issuesroot := FitDirer(config)
-	issues := readIssues(string(issuesroot))
-	sort.Sort(byDir(issues))
+	issues := CachedIssues(config)
 	if idx < 1 || idx > len(issues) {
 		return nil, IssueNotFoundError("Invalid issue index")
 	}
 
 	b := Issue{}
-	directoryString := fmt.Sprintf("%s%s%s%s%s", root, sops, config.FitDirName, sops, issues[idx-1].Name())
-	// TODO: fix, can return files that are non-issues located in the issues directory
-	b.LoadIssue(Directory(directoryString), config)
+	b.LoadIssue(issues[idx-1].Issue(config).Dir, config)
 	return &b, nil
 }
 
 // GetAllIssues returns an array of all issues.
 func GetAllIssues(config Config) []Issue {
-	root := RootDirer(&config)
-	issuesroot := FitDirer(config)
-	issues := readIssues(string(issuesroot))
-	sort.Sort(byDir(issues))
-	//fmt.Printf("%+v\n", issues)
-
+	fitdir := FitDirer(config)
 	var bugs []Issue
-	for _, issue := range issues { // idx not needed
-		if issue.IsDir() == true {
-			bug := Issue{}
-			bug.LoadIssue(root+dops+Directory(config.FitDirName)+dops+Directory(issue.Name()), config)
-			bugs = append(bugs, bug)
-		}
+	for _, e := range CachedIssues(config) { // idx not needed
+		bugs = append(bugs, e.load(fitdir, config))
 	}
 
 	//fmt.Printf("a %+v\n", bugs)
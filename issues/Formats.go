@@ -4,17 +4,20 @@ import (
 	"encoding/json"
 )
 
-// ToJSONString encodes an issue. A string and an error are returned.
-func (i Issue) ToJSONString() (string, error) {
-	iJSONStruct := struct {
-		Identifier  string `json:",omitempty"`
-		Title       string
-		Description string
-		Status      string   `json:",omitempty"`
-		Priority    string   `json:",omitempty"`
-		Milestone   string   `json:",omitempty"`
-		Tags        []string `json:",omitempty"`
-	}{
+// IssueJSON holds the fields of an issue encoded by ToJSONString.
+type IssueJSON struct {
+	Identifier  string `json:",omitempty"`
+	Title       string
+	Description string
+	Status      string   `json:",omitempty"`
+	Priority    string   `json:",omitempty"`
+	Milestone   string   `json:",omitempty"`
+	Tags        []string `json:",omitempty"`
+}
+
+// ToJSON returns the IssueJSON fields of an issue.
+func (i Issue) ToJSON() IssueJSON {
+	return IssueJSON{
 		Identifier:  i.Identifier(),
 		Title:       i.Title(""),
 		Description: i.Description(),
@@ -23,8 +26,11 @@ func (i Issue) ToJSONString() (string, error) {
 		Milestone:   i.Milestone(),
 		Tags:        i.StringTags(),
 	}
+}
 
-	iJSON, err := json.Marshal(iJSONStruct)
+// ToJSONString encodes an issue. A string and an error are returned.
+func (i Issue) ToJSONString() (string, error) {
+	iJSON, err := json.Marshal(i.ToJSON())
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,252 @@
+package issues
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GitStore is a read only Store of the files of a commit of a git
+// repository, read from the objects of the repository with git ls-tree
+// and git cat-file without a checkout. A file is modified at the time of
+// the last commit changing it.
+type GitStore struct {
+	*RevisionStore
+	// Commit is the hash of the commit.
+	Commit string
+
+	objects map[string]string
+	mu      sync.Mutex
+	batch   *exec.Cmd
+	in      io.WriteCloser
+	out     *bufio.Reader
+}
+
+// git runs a git command in the repository and returns its output.
+func (g *GitStore) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-c", "core.quotepath=off"}, args...)...)
+	cmd.Dir = g.Root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// NewGitStore returns the Store of rev, any revision git understands like
+// a branch, tag or commit hash, of the repository with the working tree
+// root.
+func NewGitStore(root, rev string) (*GitStore, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	g := &GitStore{RevisionStore: &RevisionStore{Root: root}}
+	out, err := g.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("Unknown revision %s", rev)
+	}
+	g.Commit = strings.TrimSpace(string(out))
+
+	times, err := g.times()
+	if err != nil {
+		return nil, err
+	}
+	out, err = g.git("ls-tree", "-r", "-l", "-z", "--full-tree", g.Commit)
+	if err != nil {
+		return nil, err
+	}
+	g.objects = map[string]string{}
+	var files []RevisionFile
+	for _, line := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> SP <size> TAB <path>
+		tab := strings.Index(line, "\t")
+		if tab == -1 {
+			continue
+		}
+		fields := strings.Fields(line[:tab])
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		path := line[tab+1:]
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		g.objects[path] = fields[2]
+		files = append(files, RevisionFile{Path: path, Size: size, ModTime: times[path]})
+	}
+	g.RevisionStore = NewRevisionStore(root, files, g.cat)
+	return g, nil
+}
+
+// gitCommit is a commit of a gitHistory.
+type gitCommit struct {
+	parents []string
+	time    time.Time
+	files   []string
+}
+
+// gitHistory holds the commits of a repository read with git log so far,
+// so the stores of many revisions, like every commit replayed by a diff
+// or changelog, read each commit once.
+type gitHistory struct {
+	mu      sync.Mutex
+	commits map[string]*gitCommit
+	// tips are the commits read with their ancestors
+	tips []string
+}
+
+// gitHistories are the histories of the repositories by root.
+var gitHistories = struct {
+	sync.Mutex
+	roots map[string]*gitHistory
+}{roots: map[string]*gitHistory{}}
+
+// history returns the history of the repository with the commits of
+// g.Commit, reading only the commits not read before.
+func (g *GitStore) history() (*gitHistory, error) {
+	gitHistories.Lock()
+	h := gitHistories.roots[g.Root]
+	if h == nil {
+		h = &gitHistory{commits: map[string]*gitCommit{}}
+		gitHistories.roots[g.Root] = h
+	}
+	gitHistories.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.commits[g.Commit]; ok {
+		return h, nil
+	}
+	// the commits read before are left out
+	revs := g.Commit + "\n"
+	for _, c := range h.tips {
+		revs += "^" + c + "\n"
+	}
+	cmd := exec.Command("git", "-c", "core.quotepath=off", "log", "--stdin",
+		"--format=%x00%H %ct %P", "--name-only", "--no-renames")
+	cmd.Dir = g.Root
+	cmd.Stdin = strings.NewReader(revs)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %s", g.Commit, strings.TrimSpace(stderr.String()))
+	}
+	var c *gitCommit
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "\x00") {
+			// <hash> SP <time> [SP <parent>]...
+			fields := strings.Fields(line[1:])
+			if len(fields) < 2 {
+				c = nil
+				continue
+			}
+			ct, _ := strconv.ParseInt(fields[1], 10, 64)
+			c = &gitCommit{parents: fields[2:], time: time.Unix(ct, 0)}
+			h.commits[fields[0]] = c
+		} else if line != "" && c != nil {
+			c.files = append(c.files, line)
+		}
+	}
+	h.tips = append(h.tips, g.Commit)
+	return h, nil
+}
+
+// times returns the time of the last commit changing each file, the
+// newest of the commits reachable from g.Commit that changed it.
+func (g *GitStore) times() (map[string]time.Time, error) {
+	h, err := g.history()
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	times := map[string]time.Time{}
+	seen := map[string]bool{g.Commit: true}
+	for todo := []string{g.Commit}; len(todo) > 0; {
+		c := h.commits[todo[len(todo)-1]]
+		todo = todo[:len(todo)-1]
+		if c == nil {
+			// a shallow clone
+			continue
+		}
+		for _, f := range c.files {
+			if t, ok := times[f]; !ok || c.time.After(t) {
+				times[f] = c.time
+			}
+		}
+		for _, p := range c.parents {
+			if !seen[p] {
+				seen[p] = true
+				todo = append(todo, p)
+			}
+		}
+	}
+	return times, nil
+}
+
+// cat reads the object of a file with git cat-file --batch, started on
+// first use.
+func (g *GitStore) cat(path string) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.batch == nil {
+		cmd := exec.Command("git", "cat-file", "--batch")
+		cmd.Dir = g.Root
+		in, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		g.batch, g.in, g.out = cmd, in, bufio.NewReader(out)
+	}
+	object := g.objects[path]
+	if _, err := fmt.Fprintln(g.in, object); err != nil {
+		return nil, err
+	}
+	// <object> SP <type> SP <size> LF <contents> LF
+	header, err := g.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file %s: %s", object, strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(g.out, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// Close stops reading objects.
+func (g *GitStore) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.batch == nil {
+		return nil
+	}
+	g.in.Close()
+	err := g.batch.Wait()
+	g.batch = nil
+	return err
+}
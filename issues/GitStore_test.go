@@ -0,0 +1,137 @@
+package issues
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestGitStore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Tester", "-c", "user.email=tester@example.com"}, args...)...)
+		cmd.Dir = test.dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	git("init", "-q")
+	b, _ := New("Old issue", config)
+	b.SetDescription("Committed", config)
+	b.SetStatus("open", config)
+	b.TagIssue("cli", config)
+	git("add", "-A")
+	git("commit", "-q", "-m", "Add issue")
+	git("tag", "v1")
+	// the working tree moves on
+	b.SetStatus("closed", config)
+	b.SetDescription("Changed", config)
+	New("New issue", config)
+
+	g, err := NewGitStore(test.dir, "v1")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+	defer g.Close()
+	old := SetStore(g)
+	defer SetStore(old)
+
+	// the empty Test Issue of tester is not in git
+	issues := GetAllIssues(config)
+	if len(issues) != 1 || issues[0].Dir != b.Dir {
+		t.Fatalf("Expected the issue of v1, got %+v", issues)
+	}
+	if b.Status() != "open" || b.Description() != "Committed\n" || !b.HasTag("cli") {
+		t.Errorf("Unexpected issue at v1 %s %q %v", b.Status(), b.Description(), b.StringTags())
+	}
+	if fi, err := g.Stat(string(b.Dir)); err != nil || !fi.IsDir() || fi.ModTime().IsZero() {
+		t.Errorf("Unexpected issue directory %+v %v", fi, err)
+	}
+	if err := b.SetStatus("fixed", config); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected a read only error, got %v", err)
+	}
+	// files outside the repository are read from the file system
+	outside, _ := ioutil.TempFile("", "outside")
+	outside.WriteString("outside")
+	outside.Close()
+	defer os.Remove(outside.Name())
+	if data, err := g.ReadFile(outside.Name()); err != nil || string(data) != "outside" {
+		t.Errorf("Unexpected file outside the repository %q %v", data, err)
+	}
+
+	if _, err := NewGitStore(test.dir, "missing"); err == nil {
+		t.Errorf("Expected an error for an unknown revision")
+	}
+}
+
+func TestGitStoreTimes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+	commit := func(date time.Time, msg string) {
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", msg}} {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=Tester", "-c", "user.email=tester@example.com"}, args...)...)
+			cmd.Dir = test.dir
+			cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date.Format(time.RFC3339), "GIT_AUTHOR_DATE="+date.Format(time.RFC3339))
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %s", args, out)
+			}
+		}
+	}
+	exec.Command("git", "-C", test.dir, "init", "-q").Run()
+	first := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	second := first.AddDate(1, 0, 0)
+	third := second.AddDate(1, 0, 0)
+	b, _ := New("Timed issue", config)
+	b.SetDescription("Committed", config)
+	b.SetStatus("open", config)
+	commit(first, "Add issue")
+	b.SetStatus("closed", config)
+	commit(second, "Close issue")
+	b.SetPriority("high", config)
+	commit(third, "Prioritize issue")
+
+	// revisions read in any order, each commit is read once
+	for _, c := range []struct {
+		rev         string
+		status      time.Time
+		description time.Time
+	}{
+		{"HEAD~1", second, first},
+		{"HEAD~2", first, first},
+		{"HEAD", second, first},
+	} {
+		g, err := NewGitStore(test.dir, c.rev)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err.Error())
+		}
+		for file, expected := range map[string]time.Time{"Status": c.status, "Description": c.description} {
+			if fi, err := g.Stat(string(b.Dir) + sops + file); err != nil || !fi.ModTime().Equal(expected) {
+				t.Errorf("Unexpected time of %s at %s: %v %v", file, c.rev, fi, err)
+			}
+		}
+		g.Close()
+	}
+	if _, err := NewGitStore(test.dir, "HEAD"); err != nil {
+		t.Errorf("Unexpected error reading a revision again %s", err.Error())
+	}
+	h := gitHistories.roots[test.dir]
+	if h == nil || len(h.commits) != 3 || len(h.tips) != 2 {
+		t.Errorf("Unexpected history %+v", h)
+	}
+}
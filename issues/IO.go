@@ -1,6 +1,7 @@
 package issues
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 )
@@ -14,60 +15,54 @@ func (i *Issue) Read(p []byte) (int, error) {
 	}
 	if i.descFile == nil {
 		dir := i.Direr()
-		fp, err := os.OpenFile(string(dir)+sops+i.DescriptionFileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
-		i.descFile = fp
-		if err != nil {
+		data, err := CurrentStore().ReadFile(string(dir) + sops + i.DescriptionFileName)
+		if err != nil && !os.IsNotExist(err) {
 			fmt.Fprintf(os.Stderr, "err: %s", err.Error())
 			return 0, ErrNoDescription
 		}
+		i.descFile = bytes.NewReader(data)
 	}
 
 	return i.descFile.Read(p)
 }
 
 func (i *Issue) Write(data []byte) (n int, err error) {
-	if i.DescriptionFileName == "" {
-		return 0, ErrNoDescription
-	}
-	if i.descFile == nil {
-		dir := i.Direr()
-		os.MkdirAll(string(dir), 0755)
-		fp, err := os.OpenFile(string(dir)+sops+i.DescriptionFileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing to issue: %s", err.Error())
-			return 0, err
-		}
-		i.descFile = fp
-	}
-	return i.descFile.Write(data)
+	return i.WriteAt(data, -1)
 }
 
 // WriteAt makes a directory, writes a byte string to the Description using an offset.
+// An offset below zero appends.
 // It returns the number of bytes written and an error.
 func (i *Issue) WriteAt(data []byte, off int64) (n int, err error) {
 	if i.DescriptionFileName == "" {
 		return 0, ErrNoDescription
 	}
-	if i.descFile == nil {
-		dir := i.Direr()
-		os.MkdirAll(string(dir), 0755)
-		fp, err := os.OpenFile(i.DescriptionFileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing to issue: %s", err.Error())
-			return 0, err
-		}
-		i.descFile = fp
+	dir := i.Direr()
+	s := CurrentStore()
+	s.MkdirAll(string(dir))
+	file := string(dir) + sops + i.DescriptionFileName
+	content, err := s.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error writing to issue: %s", err.Error())
+		return 0, err
+	}
+	if off < 0 {
+		off = int64(len(content))
 	}
-	return i.descFile.WriteAt(data, off)
+	if end := off + int64(len(data)); end > int64(len(content)) {
+		content = append(content, make([]byte, end-int64(len(content)))...)
+	}
+	copy(content[off:], data)
+	if err := s.WriteFile(file, content); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing to issue: %s", err.Error())
+		return 0, err
+	}
+	return len(data), nil
 }
 
-// Close returns an error if there is an error closing the descFile of an issue.
+// Close releases the Description read by Read.
 func (i Issue) Close() error {
-	if i.descFile != nil {
-		err := i.descFile.Close()
-		i.descFile = nil
-		return err
-	}
+	i.descFile = nil
 	return nil
 }
 
@@ -75,7 +70,7 @@ func (i Issue) Close() error {
 func (i *Issue) Remove() error {
 	dir := i.Direr()
 	if dir != "" {
-		return os.RemoveAll(string(dir))
+		return CurrentStore().Remove(string(dir))
 	}
 	return ErrNotFound
 }
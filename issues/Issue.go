@@ -1,17 +1,14 @@
 package issues
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
-	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 )
 
@@ -22,7 +19,7 @@ import (
 type Issue struct {
 	Dir                 Directory
 	modtime             int
-	descFile            *os.File
+	descFile            *bytes.Reader
 	DescriptionFileName string
 	TagArray            []TagKeyValue
 }
@@ -49,6 +46,8 @@ type Comment struct {
 	Body   string
 	Order  int
 	Xml    []byte
+	// File is the name of the comment file in the issue directory
+	File string
 }
 
 // ErrNoDescription defines a new error.
@@ -131,7 +130,7 @@ func (b *Issue) LoadIssue(dir Directory, config Config) {
 
 func getIdNext(config Config) int {
 	// config.FitDir/.fit_idnext_1001
-	files, err := filepath.Glob(config.FitDir + sops + ".fit_idnext_*")
+	files, err := storeGlob(config.FitDir, ".fit_idnext_*")
 	//fmt.Printf("debug Found %v files: %v\n", len(files), strings.Join(files, ", "))
 	if err == nil && len(files) > 1 {
 		fmt.Printf("Found %v files: %v\n", len(files), strings.Join(files, ", "))
@@ -163,7 +162,7 @@ func getIdNext(config Config) int {
 
 func writeIdNext(j int, config Config) {
 	content := ""
-	ioutil.WriteFile(config.FitDir+sops+".fit_idnext_"+strconv.Itoa(j), []byte(content+"\n"), 0644)
+	CurrentStore().WriteFile(config.FitDir+sops+".fit_idnext_"+strconv.Itoa(j), []byte(content+"\n"))
 	// TODO: scm.add
 }
 
@@ -173,7 +172,7 @@ func writeIdNext(j int, config Config) {
 //}
 
 func renameIdNext(i int, j int, config Config) {
-	os.Rename(config.FitDir+sops+".fit_idnext_"+strconv.Itoa(i),
+	CurrentStore().Rename(config.FitDir+sops+".fit_idnext_"+strconv.Itoa(i),
 		config.FitDir+sops+".fit_idnext_"+strconv.Itoa(j))
 	// TODO: scm.add
 }
@@ -220,20 +219,9 @@ func (b Issue) Description() string {
 	//does filepath.FromSlash() really work?
 	df := string(b.Dir) + sops + b.DescriptionFileName
 	value := ""
-	if _, staterr := os.Stat(df); staterr == nil {
-		v, readerr := ioutil.ReadFile(df)
+	if v, readerr := CurrentStore().ReadFile(df); readerr == nil {
 		//fmt.Printf("debug %v %v \n", b.DescriptionFileName, v)
-		if readerr == nil {
-			value = string(v)
-		} else if perr, ok := staterr.(*os.PathError); ok {
-			switch perr.Err.(syscall.Errno) {
-			// os.PathError Op, Path, Err
-			case syscall.ENOENT:
-				return string(value)
-			default:
-				panic("Unhandled error " + fmt.Sprint(reflect.TypeOf(readerr)) + " " + readerr.Error())
-			}
-		}
+		value = string(v)
 	}
 	//if string(value) == "" {
 	//	return "(No description provided.)\n"
@@ -248,28 +236,37 @@ func (b *Issue) SetDescription(val string, config Config) error {
 	b.DescriptionFileName = config.DescriptionFileName
 
 	//return ioutil.WriteFile(filepath.FromSlash(string(dir)+"/"+b.DescriptionFileName), []byte(val+"\n"), 0644)
-	return ioutil.WriteFile(string(dir)+sops+b.DescriptionFileName, []byte(val+"\n"), 0644)
+	if err := CurrentStore().WriteFile(string(dir)+sops+b.DescriptionFileName, []byte(val+"\n")); err != nil {
+		return err
+	}
+	InvalidateCache(dir, config)
+	return nil
 }
 
 // RemoveTag deletes a tag file of an issue.
-func (b *Issue) RemoveTag(tag TagBoolTrue, config Config) {
+func (b *Issue) RemoveTag(tag TagBoolTrue, config Config) error {
 	if dir := b.Direr(); dir != "" {
-		os.Remove(string(dir) + sops + "tags" + sops + string(tag))
-		files, err := filepath.Glob(string(dir) + sops + "tag_" + string(tag) + "*")
-		if err == nil {
+		err := CurrentStore().Remove(string(dir) + sops + "tags" + sops + string(tag))
+		files, globerr := storeGlob(string(dir), "tag_"+string(tag)+"*")
+		if globerr == nil {
 			for _, x := range files {
-				os.Remove(x)
+				if rerr := CurrentStore().Remove(x); err == nil {
+					err = rerr
+				}
 			}
 		}
+		InvalidateCache(dir, config)
+		return err
 	} else {
 		// no b.Dir - should not happen any more
 		// still good to check just in case
 		fmt.Printf("Error removing tag: %s", tag)
+		return fmt.Errorf("No directory removing tag %s", tag)
 	}
 }
 
 // TagIssue writes an empty *boolean* tag file: key, no value
-func (b *Issue) TagIssue(tag TagBoolTrue, config Config) {
+func (b *Issue) TagIssue(tag TagBoolTrue, config Config) error {
 	var key string
 	if dir := b.Direr(); dir != "" {
 		if config.NewFieldLowerCase {
@@ -277,40 +274,132 @@ func (b *Issue) TagIssue(tag TagBoolTrue, config Config) {
 		} else {
 			key = string(tag)
 		}
+		var err error
 		if config.TagKeyValue == true {
-			ioutil.WriteFile(string(dir)+sops+"tag_"+key, []byte(""), 0644)
-		} else {
-			os.Mkdir(string(dir)+sops+"tags"+sops, 0755)
-			ioutil.WriteFile(string(dir)+sops+"tags"+sops+key, []byte(""), 0644)
+			err = CurrentStore().WriteFile(string(dir)+sops+"tag_"+key, []byte(""))
+		} else if err = CurrentStore().MkdirAll(string(dir) + sops + "tags"); err == nil {
+			err = CurrentStore().WriteFile(string(dir)+sops+"tags"+sops+key, []byte(""))
 		}
+		InvalidateCache(dir, config)
+		return err
 	} else {
 		fmt.Printf("Error tagging issue: %s", key)
+		return fmt.Errorf("No directory tagging issue %s", tag)
 	}
 }
 
-// RemoveComment deletes a comment file of an issue.
+// RemoveComment deletes a comment file of an issue. Comments read by
+// Comments know their File, others are found by Body.
 func (b *Issue) RemoveComment(comment Comment) {
 	if dir := b.Direr(); dir != "" {
-		//os.Remove(filepath.FromSlash(string(dir) + "/comment-" + string(ShortTitleToDir(string(comment.Body)))))
-		os.Remove(string(dir) + sops + "comment-" + string(ShortTitleToDir(string(comment.Body))))
+		if comment.File == "" {
+			for _, c := range b.Comments() {
+				if c.Body == strings.TrimRight(comment.Body, "\n") && c.File != "comments" {
+					comment.File = c.File
+					break
+				}
+			}
+		}
+		if comment.File == "" {
+			// comments written before the comment-<order>- names
+			comment.File = "comment-" + string(ShortTitleToDir(string(comment.Body)))
+		}
+		CurrentStore().Remove(string(dir) + sops + comment.File)
 	} else {
 		fmt.Printf("Error removing comment: %s", comment.Body)
 	}
 }
 
-// CommentIssue writes a text file for an issue.
+// commentOrderRegex matches the order in the name of a comment file.
+var commentOrderRegex = regexp.MustCompile("^comment-([0-9]+)-")
+
+// commentOrder returns the order in the name of a comment file, or 0 for
+// comment files written before orders were stored.
+func commentOrder(name string) int {
+	if m := commentOrderRegex.FindStringSubmatch(name); m != nil {
+		order, _ := strconv.Atoi(m[1])
+		return order
+	}
+	return 0
+}
+
+// formatComment returns the contents of a comment file: Author and Date
+// header lines, an empty line and the body.
+func formatComment(comment Comment) []byte {
+	header := ""
+	if comment.Author != "" {
+		header += "Author: " + comment.Author + "\n"
+	}
+	header += "Date: " + comment.Time.UTC().Format(time.RFC3339) + "\n"
+	return []byte(header + "\n" + strings.TrimRight(comment.Body, "\n") + "\n")
+}
+
+// parseComment reads the contents of a comment file. Files without
+// header lines are only a body.
+func parseComment(data []byte) Comment {
+	comment := Comment{}
+	text := string(data)
+	header := Comment{}
+	for {
+		i := strings.Index(text, "\n")
+		if i == -1 {
+			break
+		}
+		line := text[:i]
+		if line == "" {
+			if !header.Time.IsZero() || header.Author != "" {
+				comment = header
+				data = []byte(text[i+1:])
+			}
+			break
+		}
+		if strings.HasPrefix(line, "Author: ") {
+			header.Author = strings.TrimPrefix(line, "Author: ")
+		} else if strings.HasPrefix(line, "Date: ") {
+			t, err := time.Parse(time.RFC3339, strings.TrimPrefix(line, "Date: "))
+			if err != nil {
+				break
+			}
+			header.Time = t
+		} else {
+			break
+		}
+		text = text[i+1:]
+	}
+	comment.Body = strings.TrimRight(string(data), "\n")
+	return comment
+}
+
+// CommentIssue writes a comment file for an issue named
+// comment-<order>-<short body> with the Author and Time of comment, the
+// current time when Time is not set. With ImportCommentsTogether the body
+// is appended to the comments file instead.
 func (b *Issue) CommentIssue(comment Comment, config Config) {
 	if dir := b.Direr(); dir != "" {
 		//os.Mkdir(filepath.FromSlash(string(dir)+"/"), 0755)
 		commenttext := []byte(comment.Body + "\n")
 		if config.ImportCommentsTogether { // not efficient but ok for now
-			data, err := ioutil.ReadFile(string(dir) + sops + "comments")
-			check(err)
-			commentappend := []byte(fmt.Sprintf("%s%s%s", data, "\n", commenttext))
-			werr := ioutil.WriteFile(string(dir)+sops+"comments", commentappend, 0644)
+			data, err := CurrentStore().ReadFile(string(dir) + sops + "comments")
+			commentappend := commenttext
+			if err == nil {
+				commentappend = []byte(fmt.Sprintf("%s%s%s", data, "\n", commenttext))
+			} else if !os.IsNotExist(err) {
+				check(err)
+			}
+			werr := CurrentStore().WriteFile(string(dir)+sops+"comments", commentappend)
 			check(werr)
 		} else {
-			werr := ioutil.WriteFile(string(dir)+sops+"comment-"+string(ShortTitleToDir(string(comment.Body))), commenttext, 0644)
+			order := 1
+			for _, c := range b.Comments() {
+				if o := commentOrder(c.File); o >= order {
+					order = o + 1
+				}
+			}
+			if comment.Time.IsZero() {
+				comment.Time = time.Now()
+			}
+			name := fmt.Sprintf("comment-%d-%s", order, ShortTitleToDir(string(comment.Body)))
+			werr := CurrentStore().WriteFile(string(dir)+sops+name, formatComment(comment))
 			check(werr)
 		}
 	} else {
@@ -318,6 +407,58 @@ func (b *Issue) CommentIssue(comment Comment, config Config) {
 	}
 }
 
+// Comments returns the comments of an issue in chronological order, by the
+// Date of their header or the modification time of older comment files.
+// The comments file written by ImportCommentsTogether is one comment.
+func (b Issue) Comments() []Comment {
+	dir := string(b.Direr())
+	comments := []Comment{}
+	files, _ := CurrentStore().ReadDir(dir)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasSuffix(name, ".xml") ||
+			!(name == "comments" || strings.HasPrefix(name, "comment-")) {
+			continue
+		}
+		data, err := CurrentStore().ReadFile(dir + sops + name)
+		if err != nil {
+			continue
+		}
+		comment := Comment{Body: strings.TrimRight(string(data), "\n")}
+		if name != "comments" {
+			comment = parseComment(data)
+		}
+		if comment.Time.IsZero() {
+			comment.Time = file.ModTime()
+		}
+		comment.Order = commentOrder(name)
+		comment.File = name
+		comments = append(comments, comment)
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].Time.Equal(comments[j].Time) {
+			return comments[i].Order < comments[j].Order
+		}
+		return comments[i].Time.Before(comments[j].Time)
+	})
+	for i := range comments {
+		comments[i].Order = i + 1
+	}
+	return comments
+}
+
+// HasComment returns true when a comment with body is already saved,
+// in a comment file or in the comments file of ImportCommentsTogether.
+func (b Issue) HasComment(body string) bool {
+	body = "\n" + strings.TrimRight(body, "\n") + "\n"
+	for _, c := range b.Comments() {
+		if strings.Contains("\n"+c.Body+"\n", body) {
+			return true
+		}
+	}
+	return false
+}
+
 // ViewIssue outputs an issue.
 func (b Issue) ViewIssue() {
 	// Fields and tags could be more general if architected differently.
@@ -340,6 +481,16 @@ func (b Issue) ViewIssue() {
 	if tags := b.StringTags(); len(tags) > 0 {
 		fmt.Printf("Tags: %s\n", strings.Join([]string(tags), ", "))
 	}
+	if comments := b.Comments(); len(comments) > 0 {
+		fmt.Printf("Comments:\n")
+		for _, c := range comments {
+			fmt.Printf("\n[%d] ", c.Order)
+			if c.Author != "" {
+				fmt.Printf("%s, ", c.Author)
+			}
+			fmt.Printf("%s\n%s\n", c.Time.Format(time.RFC3339), c.Body)
+		}
+	}
 
 }
 
@@ -386,7 +537,7 @@ func (b Issue) tager(abspath string) (string, string, bool, bool, error) {
 		return key, value, tag_name, tag_contents, errors.New("tag has no key or value")
 	} else if len(parts) == 2 {
 		key = parts[1]
-		field, err := ioutil.ReadFile(string(dir) + sops + "tag_")
+		field, err := CurrentStore().ReadFile(string(dir) + sops + "tag_")
 		if err == nil {
 			value = ([]string(strings.Split(string(field), "\n")))[0] // tag_Status file contents overrides "Status" file contents
 			// assumes value is ok, not false
@@ -421,9 +572,9 @@ func (b Issue) Tags() []TagBoolTrue {
 		}
 	}
 	// look in the <issue>/tags subdir
-	withtagsubdir, errsubdir := ioutil.ReadDir(string(dir) + sops + "tags" + sops) // returns []os.FileInfo
+	withtagsubdir, errsubdir := CurrentStore().ReadDir(string(dir) + sops + "tags") // returns []os.FileInfo
 	// look in the <issue> dir for tag_<key> and tag_<key>_<value>
-	withtagfile, errtagfile := filepath.Glob(string(dir) + sops + "tag_*") // returns []string
+	withtagfile, errtagfile := storeGlob(string(dir), "tag_*") // returns []string
 	if len(tags) == 0 && errsubdir != nil && errtagfile != nil {
 		return nil
 	}
@@ -513,7 +664,7 @@ func (b Issue) liners(fieldName string) []string {
 	dirr := b.Direr()
 	dir := string(dirr)
 	lines := []string{}
-	withtagfile, errtagfile := filepath.Glob(dir + sops + "tag_*") // returns []string
+	withtagfile, errtagfile := storeGlob(dir, "tag_*") // returns []string
 	if errtagfile == nil {
 		for _, withtagfilefile := range withtagfile {
 			//fmt.Printf("debug liners %v\n", withtagfilefile)
@@ -529,13 +680,13 @@ func (b Issue) liners(fieldName string) []string {
 		}
 	}
 	// try (F)ieldName
-	field, err := ioutil.ReadFile(dir + sops + fieldName)
+	field, err := CurrentStore().ReadFile(dir + sops + fieldName)
 	if err == nil {
 		lines = strings.Split(string(field), "\n")
 		return lines
 	}
 	// try lower (f)ieldname
-	field, err = ioutil.ReadFile(dir + sops + strings.ToLower(fieldName))
+	field, err = CurrentStore().ReadFile(dir + sops + strings.ToLower(fieldName))
 	if err == nil {
 		lines = strings.Split(string(field), "\n")
 		return lines
@@ -558,7 +709,7 @@ func (b Issue) SetField(fieldName string, value string, config Config) error { /
 		file_contents = true
 	}
 	// try tag_Status* files
-	withtagfile, errtagfile := filepath.Glob(string(dir) + sops + "tag_" + fieldName + "*") // returns []string
+	withtagfile, errtagfile := storeGlob(string(dir), "tag_"+fieldName+"*") // returns []string
 	errfind := errtagfile
 	// two cases, ie tag_Status_closed or tag_Status contains closed
 	if errtagfile == nil {
@@ -598,13 +749,14 @@ func (b Issue) SetField(fieldName string, value string, config Config) error { /
 	var err error
 	if config.NewFieldAsTag == true {
 		if config.NewFieldLowerCase == true {
-			err = ioutil.WriteFile(string(dir)+sops+"tag_"+strings.ToLower(fieldName)+"_"+strings.ToLower(TitleToDirString(newValue)), []byte(""), 0644)
+			err = CurrentStore().WriteFile(string(dir)+sops+"tag_"+strings.ToLower(fieldName)+"_"+strings.ToLower(TitleToDirString(newValue)), []byte(""))
 		} else {
-			err = ioutil.WriteFile(string(dir)+sops+"tag_"+fieldName+"_"+TitleToDirString(newValue), []byte(""), 0644)
+			err = CurrentStore().WriteFile(string(dir)+sops+"tag_"+fieldName+"_"+TitleToDirString(newValue), []byte(""))
 		}
 	} else {
-		err = ioutil.WriteFile(string(dir)+sops+fieldName, []byte(newValue), 0644)
+		err = CurrentStore().WriteFile(string(dir)+sops+fieldName, []byte(newValue))
 	}
+	InvalidateCache(dir, config)
 	if err != nil {
 		return err
 	} else {
@@ -667,8 +819,10 @@ func (b Issue) SetIdentifier(newValue string, config Config) error {
 // New prepares an issue directory.
 func New(title string, config Config) (*Issue, error) {
 	expectedDir := FitDirer(config) + Directory(os.PathSeparator) + TitleToDir(title)
-	err := os.Mkdir(string(expectedDir), 0755)
-	if err != nil {
+	if _, err := CurrentStore().Stat(string(expectedDir)); err == nil {
+		return nil, &os.PathError{Op: "mkdir", Path: string(expectedDir), Err: os.ErrExist}
+	}
+	if err := CurrentStore().MkdirAll(string(expectedDir)); err != nil {
 		return nil, err
 	}
 	return &Issue{Dir: expectedDir}, nil
@@ -172,6 +172,75 @@ func TestCommentStatusPriorityMilestone(t *testing.T) {
 	_ = b.Milestone()
 }
 
+func TestHasComment(t *testing.T) {
+	config := Config{}
+	test := tester{}
+	test.Setup()
+	defer test.Teardown()
+	b := test.issue
+
+	b.CommentIssue(Comment{Author: "Author", Body: "first comment"}, config)
+	config.ImportCommentsTogether = true
+	b.CommentIssue(Comment{Author: "Author", Body: "second comment"}, config)
+	b.CommentIssue(Comment{Author: "Author", Body: "third comment"}, config)
+	if len(b.Comments()) != 2 {
+		t.Errorf("Expected a comment file and a comments file, got %+v", b.Comments())
+	}
+	for _, body := range []string{"first comment", "second comment", "third comment\n"} {
+		if !b.HasComment(body) {
+			t.Errorf("Expected comment %q", body)
+		}
+	}
+	if b.HasComment("comment") {
+		t.Error("Unexpected partial comment match")
+	}
+}
+
+func TestCommentFormat(t *testing.T) {
+	config := Config{}
+	test := tester{}
+	test.Setup()
+	defer test.Teardown()
+	b := test.issue
+
+	// a comment file without headers or order, older than the others
+	old := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	ioutil.WriteFile(string(b.Dir)+sops+"comment-legacy", []byte("legacy comment\n"), 0644)
+	os.Chtimes(string(b.Dir)+sops+"comment-legacy", old, old)
+	when := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+	b.CommentIssue(Comment{Author: "Jane <jane@example.com>", Time: when, Body: "the same first words, one"}, config)
+	b.CommentIssue(Comment{Author: "Jane <jane@example.com>", Time: when, Body: "the same first words, two"}, config)
+
+	data, _ := ioutil.ReadFile(string(b.Dir) + sops + "comment-2-the-same-first-words_-two")
+	if string(data) != "Author: Jane <jane@example.com>\nDate: 2020-02-03T04:05:06Z\n\nthe same first words, two\n" {
+		t.Errorf("Unexpected comment file %q", data)
+	}
+	comments := b.Comments()
+	if len(comments) != 3 {
+		t.Fatalf("Expected 3 comments, got %+v", comments)
+	}
+	for i, expected := range []Comment{
+		{Time: old, Body: "legacy comment", Order: 1, File: "comment-legacy"},
+		{Author: "Jane <jane@example.com>", Time: when, Body: "the same first words, one", Order: 2, File: "comment-1-the-same-first-words_-one"},
+		{Author: "Jane <jane@example.com>", Time: when, Body: "the same first words, two", Order: 3, File: "comment-2-the-same-first-words_-two"},
+	} {
+		c := comments[i]
+		if c.Author != expected.Author || !c.Time.Equal(expected.Time) || c.Body != expected.Body ||
+			c.Order != expected.Order || c.File != expected.File {
+			t.Errorf("Unexpected comment %+v, expected %+v", c, expected)
+		}
+	}
+	if c := parseComment([]byte("Author: not a header\nbody\n")); c.Author != "" || c.Body != "Author: not a header\nbody" {
+		t.Errorf("Unexpected comment without an empty line after headers %+v", c)
+	}
+
+	b.RemoveComment(comments[1])
+	b.RemoveComment(Comment{Body: "legacy comment"})
+	if comments = b.Comments(); len(comments) != 1 || comments[0].Body != "the same first words, two" {
+		t.Errorf("Unexpected comments after remove %+v", comments)
+	}
+}
+
 func TestDescription(t *testing.T) {
 	config := Config{}
 	config.DescriptionFileName = "Description"
@@ -0,0 +1,327 @@
+package issues
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Relations of links between issues. A depends-on B is the same as
+// B blocks A.
+const (
+	LinkBlocks     = "blocks"
+	LinkDependsOn  = "depends-on"
+	LinkRelatesTo  = "relates-to"
+	LinkDuplicates = "duplicates"
+)
+
+// LinkRelations are the relations of links.
+var LinkRelations = []string{LinkBlocks, LinkDependsOn, LinkRelatesTo, LinkDuplicates}
+
+// Link is a link_<relation>_<target> file of an issue. Target is the
+// Identifier of the linked issue, or its directory name without one.
+type Link struct {
+	Relation string
+	Target   string
+}
+
+// File returns the name of the file of a link.
+func (l Link) File() string {
+	return "link_" + l.Relation + "_" + l.Target
+}
+
+// ParseLink returns the link of a file name.
+func ParseLink(name string) (Link, bool) {
+	for _, relation := range LinkRelations {
+		if prefix := "link_" + relation + "_"; strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return Link{relation, name[len(prefix):]}, true
+		}
+	}
+	return Link{}, false
+}
+
+// LinkTarget returns the Target of links to b, its Identifier unless
+// it can not be part of a file name.
+func LinkTarget(b Issue) string {
+	if id := b.Identifier(); id != "" && !strings.ContainsAny(id, "/\\ ") {
+		return id
+	}
+	return string(b.Dir.ShortNamer())
+}
+
+// Links returns the links of an issue sorted by file name.
+func (b Issue) Links() []Link {
+	files, _ := storeGlob(string(b.Direr()), "link_*")
+	var links []Link
+	for _, file := range files {
+		name := file[strings.LastIndex(file, sops)+1:]
+		if l, ok := ParseLink(name); ok {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// LinkIssue writes the file of a link of an issue.
+func (b *Issue) LinkIssue(l Link, config Config) error {
+	valid := false
+	for _, relation := range LinkRelations {
+		valid = valid || relation == l.Relation
+	}
+	if !valid {
+		return fmt.Errorf("Invalid relation %s, use one of %s", l.Relation, strings.Join(LinkRelations, ", "))
+	}
+	dir := b.Direr()
+	if err := CurrentStore().WriteFile(string(dir)+sops+l.File(), []byte("")); err != nil {
+		return err
+	}
+	InvalidateCache(dir, config)
+	return nil
+}
+
+// UnlinkIssue removes the file of a link of an issue.
+func (b *Issue) UnlinkIssue(l Link, config Config) error {
+	dir := b.Direr()
+	if _, err := CurrentStore().Stat(string(dir) + sops + l.File()); err != nil {
+		return fmt.Errorf("No link %s %s", l.Relation, l.Target)
+	}
+	if err := CurrentStore().Remove(string(dir) + sops + l.File()); err != nil {
+		return err
+	}
+	InvalidateCache(dir, config)
+	return nil
+}
+
+// LinkEdge is a link between two issues named by their directory names.
+// Links that depend on an issue are edges from the blocking issue, so
+// every blocking edge has the relation blocks. Links to issues that do
+// not exist are Missing, from the issue with the link to its Target.
+type LinkEdge struct {
+	From     string
+	To       string
+	Relation string
+	Missing  bool
+
+	dependsOn bool
+}
+
+// LinkGraph holds the links between the issues of a fit directory.
+type LinkGraph struct {
+	// Issues are in the order of issue numbers.
+	Issues []IssueRecord
+	Edges  []LinkEdge
+
+	index map[string]int
+}
+
+// NewLinkGraph reads the links of every issue.
+func NewLinkGraph(config Config) *LinkGraph {
+	fitdir := FitDirer(config)
+	g := &LinkGraph{Issues: CachedIssues(config), index: map[string]int{}}
+	for i, r := range g.Issues {
+		g.index[r.Name] = i
+	}
+	for _, r := range g.Issues {
+		for _, l := range r.issue(fitdir, config).Links() {
+			e := LinkEdge{From: r.Name, To: l.Target, Relation: l.Relation}
+			if target, ok := g.Resolve(l.Target); ok {
+				e.To = target.Name
+			} else {
+				e.Missing = true
+			}
+			if e.Relation == LinkDependsOn && !e.Missing {
+				e.Relation, e.dependsOn = LinkBlocks, true
+				e.From, e.To = e.To, e.From
+			}
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	return g
+}
+
+// Resolve returns the issue of the Target of a link, by Identifier
+// first and then by directory name.
+func (g *LinkGraph) Resolve(target string) (IssueRecord, bool) {
+	for _, r := range g.Issues {
+		if r.Identifier != "" && strings.EqualFold(r.Identifier, target) {
+			return r, true
+		}
+	}
+	if i, ok := g.index[target]; ok {
+		return g.Issues[i], true
+	}
+	return IssueRecord{}, false
+}
+
+// Number returns the issue number of an issue directory name, 0 when
+// there is no such issue.
+func (g *LinkGraph) Number(name string) int {
+	if i, ok := g.index[name]; ok {
+		return i + 1
+	}
+	return 0
+}
+
+// Blockers returns the issues blocking an issue, open and closed, in
+// the order of issue numbers.
+func (g *LinkGraph) Blockers(name string) []IssueRecord {
+	blocking := map[string]bool{}
+	for _, e := range g.Edges {
+		if e.Relation == LinkBlocks && !e.Missing && e.To == name {
+			blocking[e.From] = true
+		}
+	}
+	var blockers []IssueRecord
+	for _, r := range g.Issues {
+		if blocking[r.Name] {
+			blockers = append(blockers, r)
+		}
+	}
+	return blockers
+}
+
+// OpenBlockers returns the issues blocking an issue that are not closed.
+func (g *LinkGraph) OpenBlockers(name string) []IssueRecord {
+	var open []IssueRecord
+	for _, r := range g.Blockers(name) {
+		if !r.Closed() {
+			open = append(open, r)
+		}
+	}
+	return open
+}
+
+// Dangling returns the links to issues that do not exist, because their
+// directory was removed to close them or renamed, and the links to
+// closed issues other than dependencies, which closing resolves.
+func (g *LinkGraph) Dangling() (missing, closed []LinkEdge) {
+	for _, e := range g.Edges {
+		switch {
+		case e.Missing:
+			missing = append(missing, e)
+		case e.dependsOn:
+		case g.Issues[g.index[e.To]].Closed():
+			closed = append(closed, e)
+		}
+	}
+	return missing, closed
+}
+
+// Cycles returns the issues blocking each other, each cycle in the order
+// of issue numbers. Cycles are the strongly connected components of the
+// blocking edges found with Tarjan's algorithm.
+func (g *LinkGraph) Cycles() [][]string {
+	next := make([][]int, len(g.Issues))
+	self := make([]bool, len(g.Issues))
+	for _, e := range g.Edges {
+		if e.Relation == LinkBlocks && !e.Missing {
+			from, to := g.index[e.From], g.index[e.To]
+			next[from] = append(next[from], to)
+			self[from] = self[from] || from == to
+		}
+	}
+	index := make([]int, len(g.Issues))
+	low := make([]int, len(g.Issues))
+	onStack := make([]bool, len(g.Issues))
+	var stack []int
+	var components [][]int
+	counter := 0
+	var connect func(v int)
+	connect = func(v int) {
+		counter++
+		index[v], low[v] = counter, counter
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range next[v] {
+			if index[w] == 0 {
+				connect(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+		if low[v] == index[v] {
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			if len(component) > 1 || self[v] {
+				components = append(components, component)
+			}
+		}
+	}
+	for v := range g.Issues {
+		if index[v] == 0 {
+			connect(v)
+		}
+	}
+
+	var cycles [][]string
+	for _, component := range components {
+		in := map[int]bool{}
+		for _, v := range component {
+			in[v] = true
+		}
+		var cycle []string
+		for v, r := range g.Issues {
+			if in[v] {
+				cycle = append(cycle, r.Name)
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// TopologicalOrder returns names with issues after the issues among names
+// blocking them and otherwise in the order of names. Issues in a cycle
+// keep their order.
+func (g *LinkGraph) TopologicalOrder(names []string) []string {
+	position := map[string]int{}
+	for i, name := range names {
+		position[name] = i
+	}
+	blockers := make([]int, len(names))
+	blocks := make([][]int, len(names))
+	for _, e := range g.Edges {
+		from, fromOk := position[e.From]
+		to, toOk := position[e.To]
+		if e.Relation == LinkBlocks && !e.Missing && fromOk && toOk && from != to {
+			blockers[to]++
+			blocks[from] = append(blocks[from], to)
+		}
+	}
+	done := make([]bool, len(names))
+	var order []string
+	for len(order) < len(names) {
+		next := -1
+		for i := range names {
+			if !done[i] && blockers[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			// a cycle, take the first issue left
+			for i := range names {
+				if !done[i] {
+					next = i
+					break
+				}
+			}
+		}
+		done[next] = true
+		order = append(order, names[next])
+		for _, to := range blocks[next] {
+			blockers[to]--
+		}
+	}
+	return order
+}
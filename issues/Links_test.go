@@ -0,0 +1,100 @@
+package issues
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseLink(t *testing.T) {
+	for name, expected := range map[string]Link{
+		"link_blocks_Other-issue":      {LinkBlocks, "Other-issue"},
+		"link_depends-on_GH-12":        {LinkDependsOn, "GH-12"},
+		"link_relates-to_with_under":   {LinkRelatesTo, "with_under"},
+		"link_duplicates_Some-issue-2": {LinkDuplicates, "Some-issue-2"},
+	} {
+		if l, ok := ParseLink(name); !ok || l != expected || l.File() != name {
+			t.Errorf("Unexpected link of %s: %+v", name, l)
+		}
+	}
+	for _, name := range []string{"link_blocks_", "link_fixes_Other", "tag_blocks_Other", "Description"} {
+		if l, ok := ParseLink(name); ok {
+			t.Errorf("Unexpected link of %s: %+v", name, l)
+		}
+	}
+}
+
+func TestLinkGraph(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	issues := map[string]*Issue{}
+	for _, title := range []string{"Database", "Api", "Ui", "Docs", "Release"} {
+		issues[title], _ = New(title, config)
+	}
+	issues["Api"].SetIdentifier("API-1", config)
+	link := func(from, relation, to string) {
+		if err := issues[from].LinkIssue(Link{relation, LinkTarget(*issues[to])}, config); err != nil {
+			t.Fatalf("Could not link %s %s %s: %s", from, relation, to, err.Error())
+		}
+	}
+	link("Database", LinkBlocks, "Api")
+	link("Ui", LinkDependsOn, "Api")
+	link("Ui", LinkRelatesTo, "Docs")
+	link("Release", LinkDependsOn, "Docs")
+	issues["Release"].LinkIssue(Link{LinkBlocks, "Removed-issue"}, config)
+	if err := issues["Ui"].LinkIssue(Link{"fixes", "Api"}, config); err == nil {
+		t.Errorf("Expected an error for an invalid relation")
+	}
+	if links := issues["Ui"].Links(); !reflect.DeepEqual(links, []Link{{LinkDependsOn, "API-1"}, {LinkRelatesTo, "Docs"}}) {
+		t.Errorf("Unexpected links %+v", links)
+	}
+
+	g := NewLinkGraph(config)
+	var blockers []string
+	for _, r := range g.Blockers("Ui") {
+		blockers = append(blockers, r.Name)
+	}
+	if !reflect.DeepEqual(blockers, []string{"Api"}) {
+		t.Errorf("Unexpected blockers of Ui %v", blockers)
+	}
+	if cycles := g.Cycles(); len(cycles) != 0 {
+		t.Errorf("Unexpected cycles %v", cycles)
+	}
+	if order := g.TopologicalOrder([]string{"Release", "Ui", "Docs", "Api", "Database"}); !reflect.DeepEqual(order,
+		[]string{"Docs", "Release", "Database", "Api", "Ui"}) {
+		t.Errorf("Unexpected order %v", order)
+	}
+
+	issues["Docs"].SetStatus("closed", config)
+	link("Api", LinkBlocks, "Database")
+	g = NewLinkGraph(config)
+	if blockers := g.OpenBlockers("Release"); len(blockers) != 0 {
+		t.Errorf("Unexpected open blockers of Release %+v", blockers)
+	}
+	cycles := g.Cycles()
+	if len(cycles) == 1 {
+		sort.Strings(cycles[0])
+	}
+	if !reflect.DeepEqual(cycles, [][]string{{"Api", "Database"}}) {
+		t.Errorf("Unexpected cycles %v", cycles)
+	}
+	missing, closed := g.Dangling()
+	if len(missing) != 1 || missing[0].From != "Release" || missing[0].To != "Removed-issue" {
+		t.Errorf("Unexpected missing links %+v", missing)
+	}
+	// depending on a closed issue is resolved, relating to it is not
+	if len(closed) != 1 || closed[0].From != "Ui" || closed[0].To != "Docs" || closed[0].Relation != LinkRelatesTo {
+		t.Errorf("Unexpected links to closed issues %+v", closed)
+	}
+
+	if err := issues["Release"].UnlinkIssue(Link{LinkBlocks, "Removed-issue"}, config); err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if err := issues["Release"].UnlinkIssue(Link{LinkBlocks, "Removed-issue"}, config); err == nil {
+		t.Errorf("Expected an error removing a missing link")
+	}
+}
@@ -0,0 +1,125 @@
+package issues
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLoadWorkers bounds the issues LoadIssueRecords reads at once.
+// Reading issues waits on the file system more than on the CPU.
+const defaultLoadWorkers = 16
+
+// IssueRecord holds the fields, tags and times of an issue, read at once
+// so that commands and the cache do not read the issue files again.
+// DirTime is the modification time of the issue directory, which changes
+// when files are added, removed or renamed, and ModTime the newest of
+// its files, see Directory.ModTime.
+type IssueRecord struct {
+	Name            string
+	DirTime         time.Time
+	ModTime         time.Time
+	Title           string
+	Identifier      string
+	Status          string
+	Priority        string
+	Milestone       string
+	Tags            []string
+	DescriptionSize int64
+}
+
+// byRecordDir sorts records like byDir sorts the issue directories, so
+// issue numbers are the same with and without records.
+type byRecordDir []IssueRecord
+
+func (t byRecordDir) Len() int      { return len(t) }
+func (t byRecordDir) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t byRecordDir) Less(i, j int) bool {
+	return t[i].DirTime.Unix() < t[j].DirTime.Unix()
+}
+
+// Issue returns the issue of a record.
+func (r IssueRecord) Issue(config Config) Issue {
+	return r.issue(FitDirer(config), config)
+}
+
+// issue returns the issue of a record of the fit directory fitdir.
+func (r IssueRecord) issue(fitdir Directory, config Config) Issue {
+	return Issue{
+		Dir:                 fitdir + dops + Directory(r.Name),
+		modtime:             int(r.ModTime.Unix()),
+		DescriptionFileName: config.DescriptionFileName,
+	}
+}
+
+// load returns the issue of a record of fitdir, loaded with LoadIssue
+// when the config has IdAutomatic.
+func (r IssueRecord) load(fitdir Directory, config Config) Issue {
+	b := r.issue(fitdir, config)
+	if config.IdAutomatic {
+		b.LoadIssue(b.Dir, config)
+	}
+	return b
+}
+
+// HasTag returns if the record has tag, like Issue.HasTag.
+func (r IssueRecord) HasTag(tag TagBoolTrue) bool {
+	return findArrayString(r.Tags, string(tag))
+}
+
+// Closed returns true when the Status of the record is closed, from a
+// Status file or a tag_status_closed file.
+func (r IssueRecord) Closed() bool {
+	return strings.EqualFold(strings.TrimSpace(r.Status), "closed") || r.HasTag("status:closed")
+}
+
+// loadIssueRecord reads the record of the issue directory dir.
+func loadIssueRecord(dir Directory, config Config) IssueRecord {
+	r := IssueRecord{Name: string(dir.ShortNamer())}
+	// before the files, so a change while reading them is seen next time
+	if fi, err := CurrentStore().Stat(string(dir)); err == nil {
+		r.DirTime = fi.ModTime()
+	}
+	b := Issue{Dir: dir, DescriptionFileName: config.DescriptionFileName}
+	r.ModTime = dir.ModTime()
+	r.Title = b.Title("")
+	r.Identifier = b.Identifier()
+	r.Status = b.Status()
+	r.Priority = b.Priority()
+	r.Milestone = b.Milestone()
+	r.Tags = b.StringTags()
+	if fi, err := CurrentStore().Stat(string(dir) + sops + config.DescriptionFileName); err == nil && !fi.IsDir() {
+		r.DescriptionSize = fi.Size()
+	}
+	return r
+}
+
+// LoadIssueRecords reads the records of the issue directories dirs with
+// at most workers goroutines, or a default number when workers is 0.
+// Records are returned in the order of dirs.
+func LoadIssueRecords(dirs []Directory, workers int, config Config) []IssueRecord {
+	records := make([]IssueRecord, len(dirs))
+	if workers <= 0 {
+		workers = defaultLoadWorkers
+	}
+	if workers > len(dirs) {
+		workers = len(dirs)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				records[i] = loadIssueRecord(dirs[i], config)
+			}
+		}()
+	}
+	for i := range dirs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return records
+}
@@ -0,0 +1,66 @@
+package issues
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLoadIssueRecords(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	var dirs []Directory
+	for i := 0; i < 50; i++ {
+		b, _ := New(fmt.Sprintf("Issue %d", i), config)
+		b.SetDescription(fmt.Sprintf("Description %d", i), config)
+		b.SetStatus(fmt.Sprintf("status%d", i%3), config)
+		if i%2 == 0 {
+			b.TagIssue(TagBoolTrue(fmt.Sprintf("tag%d", i)), config)
+		}
+		dirs = append(dirs, b.Dir)
+	}
+	// the same records in the same order with any number of workers
+	serial := LoadIssueRecords(dirs, 1, config)
+	for _, workers := range []int{0, 3, 100} {
+		if records := LoadIssueRecords(dirs, workers, config); !reflect.DeepEqual(records, serial) {
+			t.Errorf("Records with %d workers differ from the records of 1 worker", workers)
+		}
+	}
+	for i, r := range serial {
+		b := Issue{Dir: dirs[i], DescriptionFileName: "Description"}
+		if r.Name != string(dirs[i].ShortNamer()) || r.Title != b.Title("") || r.Status != b.Status() ||
+			!reflect.DeepEqual(r.Tags, b.StringTags()) || r.DescriptionSize != int64(len(b.Description())) ||
+			!r.ModTime.Equal(dirs[i].ModTime()) || r.DirTime.IsZero() {
+			t.Errorf("Unexpected record %d %+v", i, r)
+		}
+	}
+	if records := LoadIssueRecords(nil, 0, config); len(records) != 0 {
+		t.Errorf("Unexpected records without directories %+v", records)
+	}
+}
+
+// benchmarkLoadIssueRecords reads the records of 2000 issues.
+func benchmarkLoadIssueRecords(b *testing.B, workers int) {
+	config, done := benchmarkIssues(b, 2000)
+	defer done()
+	var dirs []Directory
+	for _, fi := range readIssues(string(FitDirer(config))) {
+		dirs = append(dirs, FitDirer(config)+dops+Directory(fi.Name()))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LoadIssueRecords(dirs, workers, config)
+	}
+}
+
+func BenchmarkLoadIssueRecordsSerial(b *testing.B) {
+	benchmarkLoadIssueRecords(b, 1)
+}
+
+func BenchmarkLoadIssueRecords(b *testing.B) {
+	benchmarkLoadIssueRecords(b, 0)
+}
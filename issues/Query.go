@@ -0,0 +1,358 @@
+package issues
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query selects issues. Queries are parsed from expressions like
+//
+//	status:open AND (priority:high OR tag:security) AND NOT milestone:v2
+//
+// A predicate field:value compares a field with a value, ignoring case.
+// For title and description the value can be any part of the text.
+// A predicate field~regex matches a regular expression, ignoring case.
+// The date fields reported, due, started and closed are also compared
+// with field<date and field>date, where a date is like 2026-01-01 or a
+// duration relative to now like 7d or -2w, see ParseDate, so due<7d
+// matches issues due within a week. overdue matches open issues with a
+// due date in the past.
+// Values with spaces or parentheses are quoted, "like \"this\"".
+// NOT binds tightest, then AND, then OR. Predicates next to each other
+// without an operator are joined by AND.
+type Query interface {
+	Match(b Issue) bool
+	String() string
+}
+
+// QueryFields are the fields of predicates, with their aliases.
+var QueryFields = []string{"status", "priority", "milestone", "identifier", "id",
+	"tag", "tags", "title", "description", "reported", "due", "started", "closed"}
+
+// QueryError is a syntax error of a query. Pos is the byte offset of the
+// error in Query.
+type QueryError struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+// Error returns a string of the error.
+func (e QueryError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Pos+1)
+}
+
+// queryPredicate is field:value, field~regex, field<date or field>date.
+type queryPredicate struct {
+	field string
+	op    byte
+	value string
+	re    *regexp.Regexp
+}
+
+// values returns the values of the field of b, "" when the field is unset.
+func (q queryPredicate) values(b Issue) []string {
+	var values []string
+	switch q.field {
+	case "status":
+		values = []string{b.Status()}
+	case "priority":
+		values = []string{b.Priority()}
+	case "milestone":
+		values = []string{b.Milestone()}
+	case "identifier", "id":
+		values = []string{b.Identifier()}
+	case "tag", "tags":
+		values = b.StringTags()
+	case "title":
+		values = []string{b.Title("")}
+	case "description":
+		values = []string{b.Description()}
+	case "reported", "due", "started", "closed":
+		values = []string{b.fielder(dateField(q.field))}
+	}
+	if len(values) == 0 {
+		values = []string{""}
+	}
+	return values
+}
+
+// Match returns true when a value of the field matches.
+func (q queryPredicate) Match(b Issue) bool {
+	if q.op == '<' || q.op == '>' || q.op == ':' && q.value != "" && dateField(q.field) != "" {
+		return q.matchDate(b)
+	}
+	for _, value := range q.values(b) {
+		switch {
+		case q.re != nil:
+			if q.re.MatchString(value) {
+				return true
+			}
+		case q.field == "title" || q.field == "description":
+			if strings.Contains(strings.ToLower(value), strings.ToLower(q.value)) {
+				return true
+			}
+		case strings.EqualFold(strings.TrimSpace(value), q.value):
+			return true
+		}
+	}
+	return false
+}
+
+// matchDate compares the date of a date field with the value, false
+// when the field is not set.
+func (q queryPredicate) matchDate(b Issue) bool {
+	d, ok := b.Date(dateField(q.field))
+	if !ok {
+		return false
+	}
+	value, err := ParseDate(q.value, time.Now())
+	if err != nil {
+		return false
+	}
+	switch c := d.Compare(value); q.op {
+	case '<':
+		return c < 0
+	case '>':
+		return c > 0
+	default:
+		return c == 0
+	}
+}
+
+// dateField returns the date field of a query field, "" when it is not
+// a date field.
+func dateField(field string) string {
+	for _, f := range DateFields {
+		if strings.EqualFold(f, field) {
+			return f
+		}
+	}
+	return ""
+}
+
+// String returns the predicate, quoting the value when needed.
+func (q queryPredicate) String() string {
+	value := q.value
+	if value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+	}) >= 0 {
+		value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+	}
+	return q.field + string(q.op) + value
+}
+
+// queryAnd matches when both queries match.
+type queryAnd struct{ left, right Query }
+
+func (q queryAnd) Match(b Issue) bool { return q.left.Match(b) && q.right.Match(b) }
+func (q queryAnd) String() string     { return "(" + q.left.String() + " AND " + q.right.String() + ")" }
+
+// queryOr matches when either query matches.
+type queryOr struct{ left, right Query }
+
+func (q queryOr) Match(b Issue) bool { return q.left.Match(b) || q.right.Match(b) }
+func (q queryOr) String() string     { return "(" + q.left.String() + " OR " + q.right.String() + ")" }
+
+// queryNot matches when the query does not match.
+type queryNot struct{ query Query }
+
+func (q queryNot) Match(b Issue) bool { return !q.query.Match(b) }
+func (q queryNot) String() string     { return "NOT " + q.query.String() }
+
+// queryOverdue matches open issues with a due date in the past.
+type queryOverdue struct{}
+
+func (q queryOverdue) Match(b Issue) bool { return b.Overdue(time.Now()) }
+func (q queryOverdue) String() string     { return "overdue" }
+
+// queryToken is a token of a query: one of ( ) AND OR NOT, a predicate
+// or "" at the end.
+type queryToken struct {
+	text      string
+	pos       int
+	predicate Query
+}
+
+// queryParser parses a query by recursive descent.
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	next   int
+}
+
+// lex splits the query into tokens.
+func (p *queryParser) lex() error {
+	s := p.query
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			p.tokens = append(p.tokens, queryToken{text: string(c), pos: i})
+			i++
+		default:
+			start := i
+			for i < len(s) && strings.IndexByte(" \t\n():~<>", s[i]) == -1 {
+				i++
+			}
+			word := s[start:i]
+			if i == len(s) || strings.IndexByte(":~<>", s[i]) == -1 {
+				switch strings.ToUpper(word) {
+				case "AND", "OR", "NOT":
+					p.tokens = append(p.tokens, queryToken{text: strings.ToUpper(word), pos: start})
+					continue
+				case "OVERDUE":
+					p.tokens = append(p.tokens, queryToken{text: word, pos: start, predicate: queryOverdue{}})
+					continue
+				}
+				return QueryError{s, start, fmt.Sprintf("expected field:value or field~regex, got %q", word)}
+			}
+			field := strings.ToLower(word)
+			if !findArrayString(QueryFields, field) {
+				return QueryError{s, start, fmt.Sprintf("unknown field %q, fields are %s", word,
+					strings.Join(QueryFields, ", "))}
+			}
+			q := queryPredicate{field: field, op: s[i]}
+			i++
+			valuePos := i
+			if i < len(s) && s[i] == '"' {
+				var value strings.Builder
+				for i++; i < len(s) && s[i] != '"'; i++ {
+					if s[i] == '\\' && i+1 < len(s) {
+						i++
+					}
+					value.WriteByte(s[i])
+				}
+				if i == len(s) {
+					return QueryError{s, valuePos, "unterminated quoted value"}
+				}
+				i++
+				q.value = value.String()
+			} else {
+				for i < len(s) && strings.IndexByte(" \t\n()", s[i]) == -1 {
+					i++
+				}
+				q.value = s[valuePos:i]
+			}
+			switch {
+			case q.op == '~':
+				re, err := regexp.Compile("(?i)" + q.value)
+				if err != nil {
+					return QueryError{s, valuePos, "invalid regex: " + err.Error()}
+				}
+				q.re = re
+			case (q.op == '<' || q.op == '>') && dateField(field) == "":
+				return QueryError{s, start, fmt.Sprintf("%c compares dates, %q is not a date field", q.op, word)}
+			case q.op == '<' || q.op == '>' || q.op == ':' && q.value != "" && dateField(field) != "":
+				if _, err := ParseDate(q.value, time.Now()); err != nil {
+					return QueryError{s, valuePos, err.Error()}
+				}
+			}
+			p.tokens = append(p.tokens, queryToken{text: s[start:i], pos: start, predicate: q})
+		}
+	}
+	p.tokens = append(p.tokens, queryToken{pos: len(s)})
+	return nil
+}
+
+// peek returns the next token without consuming it.
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+// errorAt returns a syntax error at token t.
+func (p *queryParser) errorAt(t queryToken, expected string) error {
+	got := "end of query"
+	if t.text != "" {
+		got = fmt.Sprintf("%q", t.text)
+	}
+	return QueryError{p.query, t.pos, fmt.Sprintf("expected %s, got %s", expected, got)}
+}
+
+// or parses and ('OR' and)*.
+func (p *queryParser) or() (Query, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "OR" {
+		p.next++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+// and parses unary (['AND'] unary)*.
+func (p *queryParser) and() (Query, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch t := p.peek(); {
+		case t.text == "AND":
+			p.next++
+		case t.text == "(" || t.text == "NOT" || t.predicate != nil:
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+}
+
+// unary parses 'NOT' unary, '(' or ')' or a predicate.
+func (p *queryParser) unary() (Query, error) {
+	t := p.peek()
+	switch {
+	case t.text == "NOT":
+		p.next++
+		q, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{q}, nil
+	case t.text == "(":
+		p.next++
+		q, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().text != ")" {
+			return nil, p.errorAt(p.peek(), `")"`)
+		}
+		p.next++
+		return q, nil
+	case t.predicate != nil:
+		p.next++
+		return t.predicate, nil
+	}
+	return nil, p.errorAt(t, "field:value, NOT or \"(\"")
+}
+
+// ParseQuery parses a query expression. Errors are a QueryError.
+func ParseQuery(query string) (Query, error) {
+	p := &queryParser{query: query}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	q, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.text != "" {
+		return nil, p.errorAt(t, "AND, OR or end of query")
+	}
+	return q, nil
+}
@@ -0,0 +1,106 @@
+package issues
+
+import (
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, test := range []struct {
+		query, expected string
+	}{
+		{"status:open", "status:open"},
+		{"status:open AND priority:high OR tag:security", "((status:open AND priority:high) OR tag:security)"},
+		{"status:open AND (priority:high OR tag:security) AND NOT milestone:v2",
+			"((status:open AND (priority:high OR tag:security)) AND NOT milestone:v2)"},
+		{"status:open priority:high", "(status:open AND priority:high)"},
+		{"not not Tag:cli or title~^fix", "(NOT NOT tag:cli OR title~^fix)"},
+		{`description~"panic: nil" AND milestone:""`, `(description~"panic: nil" AND milestone:"")`},
+		{`title:"say \"hi\""`, `title:"say \"hi\""`},
+		{"due<7d OR overdue", "(due<7d OR overdue)"},
+		{"Reported>2026-01-01 status:open", "(reported>2026-01-01 AND status:open)"},
+	} {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %s: %s", test.query, err.Error())
+		} else if q.String() != test.expected {
+			t.Errorf("Parsing %s: expected %s, got %s", test.query, test.expected, q.String())
+		}
+	}
+
+	for _, test := range []struct {
+		query, expected string
+	}{
+		{"", `expected field:value, NOT or "(", got end of query at column 1`},
+		{"status:open AND", `expected field:value, NOT or "(", got end of query at column 16`},
+		{"(status:open", `expected ")", got end of query at column 13`},
+		{"status:open)", `expected AND, OR or end of query, got ")" at column 12`},
+		{"open", `expected field:value or field~regex, got "open" at column 1`},
+		{"color:red", `unknown field "color", fields are status, priority, milestone, identifier, id, tag, tags, title, description, reported, due, started, closed at column 1`},
+		{"priority<high", `< compares dates, "priority" is not a date field at column 1`},
+		{"due>soon", "Invalid date soon, use YYYY-MM-DD[ HH:MM[:SS]][ zone] or a duration like 7d at column 5"},
+		{`title:"open`, "unterminated quoted value at column 7"},
+		{`title~"("`, "invalid regex: error parsing regexp: missing closing ): `(?i)(` at column 7"},
+	} {
+		_, err := ParseQuery(test.query)
+		if err == nil {
+			t.Errorf("Expected an error parsing %q", test.query)
+			continue
+		}
+		if _, ok := err.(QueryError); !ok || err.Error() != test.expected {
+			t.Errorf("Parsing %q: expected error %s, got %s", test.query, test.expected, err.Error())
+		}
+	}
+}
+
+func TestFindIssuesByQuery(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	a, _ := New("Crash on start", config)
+	a.SetStatus("open", config)
+	a.SetPriority("high", config)
+	a.SetMilestone("v1", config)
+	a.SetDescription("panic: nil map", config)
+	b, _ := New("Leaks password", config)
+	b.SetStatus("Open", config)
+	b.TagIssue("security", config)
+	b.SetMilestone("v2", config)
+	c, _ := New("Slow start", config)
+	c.SetStatus("closed", config)
+	c.SetPriority("high", config)
+
+	for _, test := range []struct {
+		query    string
+		expected []string
+	}{
+		{"status:open", []string{"Crash on start", "Leaks password"}},
+		{"status:open AND (priority:high OR tag:security) AND NOT milestone:v2", []string{"Crash on start"}},
+		{"priority:high AND NOT status:open", []string{"Slow start"}},
+		{"milestone: AND NOT title:test", []string{"Slow start"}},
+		{"title~start$", []string{"Crash on start", "Slow start"}},
+		{"title:PASS", []string{"Leaks password"}},
+		{`description~"panic"`, []string{"Crash on start"}},
+		{"tag:cli", nil},
+	} {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %s: %s", test.query, err.Error())
+		}
+		var titles []string
+		for _, b := range FindIssuesByQuery(q, config) {
+			titles = append(titles, b.Title(""))
+		}
+		if len(titles) != len(test.expected) {
+			t.Errorf("Query %s: expected %v, got %v", test.query, test.expected, titles)
+			continue
+		}
+		for i := range titles {
+			if titles[i] != test.expected[i] {
+				t.Errorf("Query %s: expected %v, got %v", test.query, test.expected, titles)
+			}
+		}
+	}
+}
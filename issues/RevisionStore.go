@@ -0,0 +1,166 @@
+package issues
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RevisionFile is a file of a revision of a repository. Path is relative
+// to the root of the repository with / separators.
+type RevisionFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// RevisionStore is a read only Store of the files of a revision of a
+// repository, listed once and read with a function, for example from the
+// objects of a git repository. Directories are the parents of files,
+// modified at the time of the oldest file below them so that issues keep
+// their numbers as they are edited. Paths outside the repository are read
+// from the file system.
+type RevisionStore struct {
+	// Root is the top level directory of the working tree.
+	Root string
+
+	files    map[string]RevisionFile
+	children map[string][]string
+	read     func(path string) ([]byte, error)
+}
+
+// NewRevisionStore returns the Store of files, read by read with the Path
+// of a file.
+func NewRevisionStore(root string, files []RevisionFile, read func(path string) ([]byte, error)) *RevisionStore {
+	s := &RevisionStore{
+		Root:     filepath.Clean(root),
+		files:    map[string]RevisionFile{},
+		children: map[string][]string{},
+		read:     read,
+	}
+	dirs := map[string]time.Time{"": {}}
+	for _, f := range files {
+		s.files[f.Path] = f
+		s.children[revisionParent(f.Path)] = append(s.children[revisionParent(f.Path)], f.Path)
+		for dir := revisionParent(f.Path); ; dir = revisionParent(dir) {
+			if t, seen := dirs[dir]; !seen {
+				dirs[dir] = f.ModTime
+				s.children[revisionParent(dir)] = append(s.children[revisionParent(dir)], dir)
+			} else if f.ModTime.Before(t) || t.IsZero() {
+				dirs[dir] = f.ModTime
+			}
+			if dir == "" {
+				break
+			}
+		}
+	}
+	for dir, t := range dirs {
+		s.files[dir+"/"] = RevisionFile{Path: dir, ModTime: t}
+	}
+	for _, names := range s.children {
+		sort.Strings(names)
+	}
+	return s
+}
+
+// revisionParent returns the parent of a path of a revision, "" for the
+// root.
+func revisionParent(path string) string {
+	if i := strings.LastIndex(path, "/"); i != -1 {
+		return path[:i]
+	}
+	return ""
+}
+
+// relative returns the path in the revision of a file system path and
+// false for paths outside the repository.
+func (s *RevisionStore) relative(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(s.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+sops) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// info describes a file, or a directory when there is none.
+func (s *RevisionStore) info(rel string) (os.FileInfo, bool) {
+	name := rel[strings.LastIndex(rel, "/")+1:]
+	if f, ok := s.files[rel]; ok && rel != "" {
+		return storeFileInfo{name, f.Size, false, f.ModTime}, true
+	}
+	if rel == "" {
+		name = filepath.Base(s.Root)
+	}
+	if d, ok := s.files[rel+"/"]; ok {
+		return storeFileInfo{name, 0, true, d.ModTime}, true
+	}
+	return nil, false
+}
+
+// ReadFile returns the contents of a file of the revision.
+func (s *RevisionStore) ReadFile(path string) ([]byte, error) {
+	rel, ok := s.relative(path)
+	if !ok {
+		return OSStore{}.ReadFile(path)
+	}
+	if _, ok := s.files[rel]; !ok || rel == "" {
+		return nil, missing("open", path)
+	}
+	return s.read(rel)
+}
+
+// ReadDir lists a directory of the revision sorted by name.
+func (s *RevisionStore) ReadDir(path string) ([]os.FileInfo, error) {
+	rel, ok := s.relative(path)
+	if !ok {
+		return OSStore{}.ReadDir(path)
+	}
+	if _, ok := s.files[rel+"/"]; !ok {
+		return nil, missing("open", path)
+	}
+	fis := []os.FileInfo{}
+	for _, name := range s.children[rel] {
+		fi, _ := s.info(name)
+		fis = append(fis, fi)
+	}
+	return fis, nil
+}
+
+// Stat describes a file or directory of the revision.
+func (s *RevisionStore) Stat(path string) (os.FileInfo, error) {
+	rel, ok := s.relative(path)
+	if !ok {
+		return OSStore{}.Stat(path)
+	}
+	fi, ok := s.info(rel)
+	if !ok {
+		return nil, missing("stat", path)
+	}
+	return fi, nil
+}
+
+// readOnly returns the error of writing to path.
+func readOnly(op, path string) error {
+	return &os.PathError{Op: op, Path: path, Err: ErrReadOnly}
+}
+
+// WriteFile returns ErrReadOnly.
+func (s *RevisionStore) WriteFile(path string, data []byte) error { return readOnly("write", path) }
+
+// MkdirAll returns ErrReadOnly.
+func (s *RevisionStore) MkdirAll(path string) error { return readOnly("mkdir", path) }
+
+// Remove returns ErrReadOnly.
+func (s *RevisionStore) Remove(path string) error { return readOnly("remove", path) }
+
+// Rename returns ErrReadOnly.
+func (s *RevisionStore) Rename(oldpath, newpath string) error { return readOnly("rename", oldpath) }
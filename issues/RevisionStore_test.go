@@ -0,0 +1,51 @@
+package issues
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRevisionStore(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+	read := func(path string) ([]byte, error) { return []byte("contents of " + path), nil }
+	s := NewRevisionStore("/repo", []RevisionFile{
+		{Path: "fit/Issue/Description", Size: 5, ModTime: day(3)},
+		{Path: "fit/Issue/Status", Size: 4, ModTime: day(5)},
+		{Path: "fit/Another/Description", Size: 1, ModTime: day(2)},
+		{Path: "README", Size: 9, ModTime: day(1)},
+	}, read)
+
+	// directories are as old as their oldest file
+	for path, expected := range map[string]time.Time{
+		"/repo/fit/Issue": day(3), "/repo/fit": day(2), "/repo": day(1),
+		"/repo/fit/Issue/Status": day(5),
+	} {
+		if fi, err := s.Stat(path); err != nil || !fi.ModTime().Equal(expected) {
+			t.Errorf("Unexpected time of %s %+v %v", path, fi, err)
+		}
+	}
+	fis, err := s.ReadDir("/repo/fit")
+	if err != nil || len(fis) != 2 || fis[0].Name() != "Another" || !fis[0].IsDir() || fis[1].Name() != "Issue" {
+		t.Errorf("Unexpected directory %+v %v", fis, err)
+	}
+	if fis, _ := s.ReadDir("/repo"); len(fis) != 2 || fis[0].Name() != "README" || fis[0].Size() != 9 {
+		t.Errorf("Unexpected root %+v", fis)
+	}
+	if data, err := s.ReadFile("/repo/fit/Issue/Status"); err != nil || string(data) != "contents of fit/Issue/Status" {
+		t.Errorf("Unexpected Status %q %v", data, err)
+	}
+	if _, err := s.ReadFile("/repo/fit/Issue"); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file reading a directory, got %v", err)
+	}
+	if _, err := s.Stat("/repo/fit/Missing"); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file, got %v", err)
+	}
+	if err := s.WriteFile("/repo/fit/Issue/Status", nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected a read only error, got %v", err)
+	}
+	if err := s.Remove("/repo/fit/Issue"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected a read only error, got %v", err)
+	}
+}
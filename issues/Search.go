@@ -0,0 +1,219 @@
+package issues
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters, the usual defaults.
+const (
+	searchK1 = 1.2
+	searchB  = 0.75
+)
+
+// searchWeights weigh terms by the text they were found in.
+var searchWeights = map[string]float64{
+	"title":       3,
+	"fields":      2,
+	"description": 1,
+	"comments":    1,
+}
+
+// searchSnippetFields are searched in order for the text of a snippet.
+var searchSnippetFields = []string{"description", "comments", "title", "fields"}
+
+// SearchDoc is an indexed issue. Text holds the indexed text by field:
+// title, description, comments and fields, the values of the Status,
+// Priority, Milestone and Identifier files and the tags.
+type SearchDoc struct {
+	Dir    string
+	Title  string
+	Length float64
+	Text   map[string]string
+}
+
+// SearchPosting is the weighted frequency of a term in a document.
+type SearchPosting struct {
+	Doc  int
+	Freq float64
+}
+
+// SearchIndex is an inverted index of issues for full text search, with
+// results ranked by BM25.
+type SearchIndex struct {
+	Docs  []SearchDoc
+	Terms map[string][]SearchPosting
+}
+
+// SearchResult is an issue matching a search. The Snippet is the text
+// around the first matching term with the terms highlighted **like this**.
+type SearchResult struct {
+	Doc     SearchDoc
+	Score   float64
+	Snippet string
+}
+
+// searchToken is a term of a text with its byte offsets.
+type searchToken struct {
+	term       string
+	start, end int
+}
+
+// searchTokens splits text into lower case terms of letters and digits.
+func searchTokens(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text + " " {
+		isTerm := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isTerm && start == -1 {
+			start = i
+		} else if !isTerm && start != -1 {
+			tokens = append(tokens, searchToken{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// NewSearchIndex returns an empty index.
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{Terms: map[string][]SearchPosting{}}
+}
+
+// Add indexes the title, Description, comments and field values of b.
+func (idx *SearchIndex) Add(b Issue) {
+	var comments []string
+	for _, c := range b.Comments() {
+		comments = append(comments, c.Body)
+	}
+	fields := []string{b.Status(), b.Priority(), b.Milestone(), b.Identifier()}
+	fields = append(fields, b.StringTags()...)
+	doc := SearchDoc{
+		Dir:   string(b.Dir),
+		Title: b.Title(""),
+		Text: map[string]string{
+			"title":       b.Title(""),
+			"description": b.Description(),
+			"comments":    strings.Join(comments, "\n\n"),
+			"fields":      strings.Join(fields, " "),
+		},
+	}
+	freqs := map[string]float64{}
+	for field, text := range doc.Text {
+		for _, t := range searchTokens(text) {
+			freqs[t.term] += searchWeights[field]
+			doc.Length += searchWeights[field]
+		}
+	}
+	n := len(idx.Docs)
+	idx.Docs = append(idx.Docs, doc)
+	for term, freq := range freqs {
+		idx.Terms[term] = append(idx.Terms[term], SearchPosting{n, freq})
+	}
+}
+
+// Search returns the documents containing any of the terms of query,
+// best first.
+func (idx *SearchIndex) Search(query string) []SearchResult {
+	if len(idx.Docs) == 0 {
+		return nil
+	}
+	average := 0.0
+	for _, doc := range idx.Docs {
+		average += doc.Length
+	}
+	average /= float64(len(idx.Docs))
+	if average == 0 {
+		average = 1
+	}
+
+	terms := map[string]bool{}
+	scores := map[int]float64{}
+	n := float64(len(idx.Docs))
+	for _, t := range searchTokens(query) {
+		if terms[t.term] {
+			continue
+		}
+		terms[t.term] = true
+		postings := idx.Terms[t.term]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			length := idx.Docs[p.Doc].Length
+			scores[p.Doc] += idf * p.Freq * (searchK1 + 1) /
+				(p.Freq + searchK1*(1-searchB+searchB*length/average))
+		}
+	}
+
+	results := []SearchResult{}
+	for doc, score := range scores {
+		results = append(results, SearchResult{
+			Doc:     idx.Docs[doc],
+			Score:   score,
+			Snippet: searchSnippet(idx.Docs[doc], terms),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Doc.Dir < results[j].Doc.Dir
+	})
+	return results
+}
+
+// searchSnippet returns about 80 characters of the text of doc around the
+// first of terms, on one line, with every term highlighted.
+func searchSnippet(doc SearchDoc, terms map[string]bool) string {
+	const width = 80
+	for _, field := range searchSnippetFields {
+		text := doc.Text[field]
+		tokens := searchTokens(text)
+		first := -1
+		for i, t := range tokens {
+			if terms[t.term] {
+				first = i
+				break
+			}
+		}
+		if first == -1 {
+			continue
+		}
+		// start a few words before the first match
+		start := 0
+		for i := first; i >= 0; i-- {
+			if tokens[first].start-tokens[i].start > width/4 {
+				break
+			}
+			start = tokens[i].start
+		}
+		end := len(text)
+		for _, t := range tokens[first:] {
+			if t.end-start > width {
+				end = t.start
+				break
+			}
+		}
+		snippet := ""
+		pos := start
+		for _, t := range tokens {
+			if t.start < start || t.end > end || !terms[t.term] {
+				continue
+			}
+			snippet += text[pos:t.start] + "**" + text[t.start:t.end] + "**"
+			pos = t.end
+		}
+		snippet += text[pos:end]
+		snippet = strings.Join(strings.Fields(snippet), " ")
+		if start > 0 {
+			snippet = "..." + snippet
+		}
+		if end < len(strings.TrimRightFunc(text, unicode.IsSpace)) {
+			snippet += "..."
+		}
+		return snippet
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package issues
+
+import (
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	a, _ := New("Crash on start", config)
+	a.SetDescription("The program will panic with a nil map when the config is missing.", config)
+	b, _ := New("Slow start", config)
+	b.SetDescription("Starting takes ten seconds.\nIt should not crash.", config)
+	b.CommentIssue(Comment{Body: "Another panic seen here"}, config)
+	c, _ := New("Leaks password", config)
+	c.SetStatus("open", config)
+	c.TagIssue("security", config)
+
+	index := NewSearchIndex()
+	for _, issue := range GetAllIssues(config) {
+		index.Add(issue)
+	}
+	results := index.Search("crash")
+	if len(results) != 2 || results[0].Doc.Title != "Crash on start" || results[1].Doc.Title != "Slow start" {
+		t.Fatalf("Expected title match ranked first, got %+v", results)
+	}
+	if results[0].Snippet != "**Crash** on start" {
+		t.Errorf("Unexpected title snippet %q", results[0].Snippet)
+	}
+	if results[1].Snippet != "...It should not **crash**." {
+		t.Errorf("Unexpected description snippet %q", results[1].Snippet)
+	}
+
+	results = index.Search("PANIC map")
+	if len(results) != 2 || results[0].Doc.Title != "Crash on start" {
+		t.Fatalf("Expected the issue with both terms first, got %+v", results)
+	}
+	if results[1].Snippet != "Another **panic** seen here" {
+		t.Errorf("Unexpected comment snippet %q", results[1].Snippet)
+	}
+	if results = index.Search("security"); len(results) != 1 || results[0].Snippet != "open **security** status:open" {
+		t.Errorf("Unexpected tag search %+v", results)
+	}
+	if results = index.Search("nothing"); len(results) != 0 {
+		t.Errorf("Unexpected results %+v", results)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	doc := SearchDoc{Text: map[string]string{"description": "one two three four five six seven eight nine ten " +
+		"eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one " +
+		"twenty-two twenty-three twenty-four"}}
+	expected := "...ten eleven twelve **thirteen** fourteen fifteen sixteen seventeen eighteen nineteen..."
+	if snippet := searchSnippet(doc, map[string]bool{"thirteen": true}); snippet != expected {
+		t.Errorf("Unexpected snippet %q, expected %q", snippet, expected)
+	}
+}
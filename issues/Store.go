@@ -0,0 +1,316 @@
+package issues
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store reads and writes the files of issues. Paths are file system paths
+// like the Dir of an issue joined with file names, so every Store sees the
+// same paths.
+type Store interface {
+	// ReadFile returns the contents of a file.
+	ReadFile(path string) ([]byte, error)
+	// WriteFile creates or replaces a file. The directory must exist.
+	WriteFile(path string, data []byte) error
+	// ReadDir lists a directory sorted by name.
+	ReadDir(path string) ([]os.FileInfo, error)
+	// Stat describes a file or directory.
+	Stat(path string) (os.FileInfo, error)
+	// MkdirAll creates a directory and any missing parents.
+	MkdirAll(path string) error
+	// Remove deletes a file or a directory with its contents. Missing
+	// paths are not an error.
+	Remove(path string) error
+	// Rename moves a file or directory.
+	Rename(oldpath, newpath string) error
+}
+
+// ErrReadOnly is returned when writing to a Store of a revision.
+var ErrReadOnly = errors.New("Issues are read only")
+
+// store is the Store of every issue, the file system unless SetStore
+// was called.
+var store Store = OSStore{}
+
+// storeMutex guards replacing store.
+var storeMutex sync.RWMutex
+
+// CurrentStore returns the Store of issues.
+func CurrentStore() Store {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	return store
+}
+
+// SetStore replaces the Store of issues and returns the previous one.
+func SetStore(s Store) Store {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	old := store
+	store = s
+	return old
+}
+
+// isOSStore returns true when issues are read from the file system, the
+// only Store with a cache.
+func isOSStore() bool {
+	_, ok := CurrentStore().(OSStore)
+	return ok
+}
+
+// storeGlob returns the paths of the entries of dir whose names match
+// pattern, like filepath.Glob does for one directory.
+func storeGlob(dir, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	fis, err := CurrentStore().ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var matches []string
+	for _, fi := range fis {
+		if ok, _ := filepath.Match(pattern, fi.Name()); ok {
+			matches = append(matches, dir+sops+fi.Name())
+		}
+	}
+	return matches, nil
+}
+
+// OSStore is the Store of the file system.
+type OSStore struct{}
+
+// ReadFile returns the contents of a file.
+func (OSStore) ReadFile(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+// WriteFile creates or replaces a file.
+func (OSStore) WriteFile(path string, data []byte) error {
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// ReadDir lists a directory sorted by name.
+func (OSStore) ReadDir(path string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(path)
+}
+
+// Stat describes a file or directory.
+func (OSStore) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// MkdirAll creates a directory and any missing parents.
+func (OSStore) MkdirAll(path string) error {
+	return os.MkdirAll(path, 0755)
+}
+
+// Remove deletes a file or a directory with its contents.
+func (OSStore) Remove(path string) error {
+	return os.RemoveAll(path)
+}
+
+// Rename moves a file or directory.
+func (OSStore) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// storeFileInfo describes a file of a Store other than the file system.
+type storeFileInfo struct {
+	name    string
+	size    int64
+	dir     bool
+	modTime time.Time
+}
+
+func (fi storeFileInfo) Name() string       { return fi.name }
+func (fi storeFileInfo) Size() int64        { return fi.size }
+func (fi storeFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi storeFileInfo) IsDir() bool        { return fi.dir }
+func (fi storeFileInfo) Sys() interface{}   { return nil }
+func (fi storeFileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// memFile is a file or directory of a MemStore.
+type memFile struct {
+	data    []byte
+	dir     bool
+	modTime time.Time
+}
+
+// MemStore is a Store in memory, for tests. Modification times of files
+// and directories change like they do on a file system.
+type MemStore struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+}
+
+// NewMemStore returns an empty MemStore with only the root directory.
+func NewMemStore() *MemStore {
+	root := filepath.VolumeName(sops) + sops
+	return &MemStore{files: map[string]*memFile{root: {dir: true, modTime: time.Now()}}}
+}
+
+// touch sets the modification time of the directory of path.
+func (m *MemStore) touch(path string, now time.Time) {
+	if parent := m.files[filepath.Dir(path)]; parent != nil {
+		parent.modTime = now
+	}
+}
+
+// missing returns the error of a path that does not exist.
+func missing(op, path string) error {
+	return &os.PathError{Op: op, Path: path, Err: os.ErrNotExist}
+}
+
+// ReadFile returns the contents of a file.
+func (m *MemStore) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f := m.files[filepath.Clean(path)]
+	if f == nil {
+		return nil, missing("open", path)
+	}
+	if f.dir {
+		return nil, &os.PathError{Op: "read", Path: path, Err: errors.New("is a directory")}
+	}
+	return append([]byte(nil), f.data...), nil
+}
+
+// WriteFile creates or replaces a file. The directory must exist.
+func (m *MemStore) WriteFile(path string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if parent := m.files[filepath.Dir(path)]; parent == nil || !parent.dir {
+		return missing("open", path)
+	}
+	if f := m.files[path]; f != nil && f.dir {
+		return &os.PathError{Op: "open", Path: path, Err: errors.New("is a directory")}
+	}
+	now := time.Now()
+	if m.files[path] == nil {
+		m.touch(path, now)
+	}
+	m.files[path] = &memFile{data: append([]byte(nil), data...), modTime: now}
+	return nil
+}
+
+// ReadDir lists a directory sorted by name.
+func (m *MemStore) ReadDir(path string) ([]os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if f := m.files[path]; f == nil || !f.dir {
+		return nil, missing("open", path)
+	}
+	fis := []os.FileInfo{}
+	for name, f := range m.files {
+		if filepath.Dir(name) == path && name != path {
+			fis = append(fis, storeFileInfo{filepath.Base(name), int64(len(f.data)), f.dir, f.modTime})
+		}
+	}
+	sort.Slice(fis, func(i, j int) bool { return fis[i].Name() < fis[j].Name() })
+	return fis, nil
+}
+
+// Stat describes a file or directory.
+func (m *MemStore) Stat(path string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	f := m.files[path]
+	if f == nil {
+		return nil, missing("stat", path)
+	}
+	return storeFileInfo{filepath.Base(path), int64(len(f.data)), f.dir, f.modTime}, nil
+}
+
+// MkdirAll creates a directory and any missing parents.
+func (m *MemStore) MkdirAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(filepath.Clean(path), time.Now())
+}
+
+func (m *MemStore) mkdirAll(path string, now time.Time) error {
+	if f := m.files[path]; f != nil {
+		if !f.dir {
+			return &os.PathError{Op: "mkdir", Path: path, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if parent := filepath.Dir(path); parent != path {
+		if err := m.mkdirAll(parent, now); err != nil {
+			return err
+		}
+	}
+	m.touch(path, now)
+	m.files[path] = &memFile{dir: true, modTime: now}
+	return nil
+}
+
+// below returns the paths of path and everything inside it.
+func (m *MemStore) below(path string) []string {
+	paths := []string{}
+	for name := range m.files {
+		if name == path || strings.HasPrefix(name, path+sops) {
+			paths = append(paths, name)
+		}
+	}
+	return paths
+}
+
+// Remove deletes a file or a directory with its contents.
+func (m *MemStore) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if m.files[path] == nil {
+		return nil
+	}
+	for _, name := range m.below(path) {
+		delete(m.files, name)
+	}
+	m.touch(path, time.Now())
+	return nil
+}
+
+// Rename moves a file or directory. Existing files are replaced, existing
+// directories are not.
+func (m *MemStore) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	if m.files[oldpath] == nil {
+		return missing("rename", oldpath)
+	}
+	if parent := m.files[filepath.Dir(newpath)]; parent == nil || !parent.dir {
+		return missing("rename", newpath)
+	}
+	if f := m.files[newpath]; f != nil && f.dir {
+		return &os.PathError{Op: "rename", Path: newpath, Err: os.ErrExist}
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	for _, name := range m.below(oldpath) {
+		m.files[newpath+strings.TrimPrefix(name, oldpath)] = m.files[name]
+		delete(m.files, name)
+	}
+	now := time.Now()
+	m.touch(oldpath, now)
+	m.touch(newpath, now)
+	return nil
+}
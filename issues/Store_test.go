@@ -0,0 +1,102 @@
+package issues
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMemStore(t *testing.T) {
+	s := NewMemStore()
+	if err := s.WriteFile("/repo/file", []byte("x")); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing directory error, got %v", err)
+	}
+	if err := s.MkdirAll("/repo/fit/Issue"); err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+	s.WriteFile("/repo/fit/Issue/Status", []byte("open"))
+	s.WriteFile("/repo/fit/Issue/Description", []byte("text\n"))
+	if data, err := s.ReadFile("/repo/fit/Issue/Status"); err != nil || string(data) != "open" {
+		t.Errorf("Unexpected Status %q %v", data, err)
+	}
+	if _, err := s.ReadFile("/repo/fit/Issue/Milestone"); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+	fis, _ := s.ReadDir("/repo/fit/Issue")
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"Description", "Status"}) {
+		t.Errorf("Unexpected files %v", names)
+	}
+	if fi, err := s.Stat("/repo/fit/Issue/Description"); err != nil || fi.IsDir() || fi.Size() != 5 {
+		t.Errorf("Unexpected Description %+v %v", fi, err)
+	}
+
+	if err := s.Rename("/repo/fit/Issue", "/repo/fit/Renamed"); err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if _, err := s.Stat("/repo/fit/Issue"); !os.IsNotExist(err) {
+		t.Errorf("Issue still exists after rename")
+	}
+	if data, _ := s.ReadFile("/repo/fit/Renamed/Status"); string(data) != "open" {
+		t.Errorf("Unexpected Status after rename %q", data)
+	}
+	if err := s.Remove("/repo/fit/Renamed"); err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if fis, _ := s.ReadDir("/repo/fit"); len(fis) != 0 {
+		t.Errorf("Unexpected files after remove %v", fis)
+	}
+	if err := s.Remove("/repo/fit/Missing"); err != nil {
+		t.Errorf("Unexpected error removing a missing file %s", err.Error())
+	}
+}
+
+func TestIssuesInMemStore(t *testing.T) {
+	old := SetStore(NewMemStore())
+	defer SetStore(old)
+	os.Setenv("FIT", "/repo")
+	defer os.Unsetenv("FIT")
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	CurrentStore().MkdirAll("/repo/fit")
+
+	b, err := New("Memory issue", config)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+	if b.Dir != "/repo/fit/Memory-issue" {
+		t.Errorf("Unexpected directory %s", b.Dir)
+	}
+	b.SetDescription("In memory", config)
+	b.SetStatus("open", config)
+	b.TagIssue("cli", config)
+	b.CommentIssue(Comment{Body: "A comment"}, config)
+
+	issues := GetAllIssues(config)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %+v", issues)
+	}
+	if got := issues[0].Description(); got != "In memory\n" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if got := issues[0].StringTags(); !reflect.DeepEqual(got, []string{"cli", "status:open"}) {
+		t.Errorf("Unexpected tags %v", got)
+	}
+	if got := FindIssuesByTag([]string{"cli"}, config); len(got) != 1 {
+		t.Errorf("Expected 1 issue tagged cli, got %+v", got)
+	}
+	if c := issues[0].Comments(); len(c) != 1 || c[0].Body != "A comment" {
+		t.Errorf("Unexpected comments %+v", c)
+	}
+	if _, err := os.Stat("/repo/fit"); err == nil {
+		t.Errorf("Issue written to the file system")
+	}
+	b.Remove()
+	if got := GetAllIssues(config); len(got) != 0 {
+		t.Errorf("Expected no issues after remove, got %+v", got)
+	}
+}
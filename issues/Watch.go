@@ -0,0 +1,149 @@
+package issues
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WatchersFile is the file of an issue listing its watchers, one
+// channel:address recipient per line.
+const WatchersFile = "Watchers"
+
+// QueryWatchesFile is the file of the fit directory listing the watches
+// of queries, a recipient and a query per line.
+const QueryWatchesFile = ".fit_watchers"
+
+// QueryWatch is a recipient watching the issues matching a query.
+type QueryWatch struct {
+	Recipient string
+	Query     string
+}
+
+// readLines returns the lines of a file of the Store that are not empty.
+func readLines(path string) []string {
+	data, err := CurrentStore().ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// writeLines replaces a file of the Store with lines, or removes it
+// when there are none.
+func writeLines(path string, lines []string) error {
+	if len(lines) == 0 {
+		return CurrentStore().Remove(path)
+	}
+	return CurrentStore().WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// Watchers returns the recipients watching an issue sorted by name.
+func (b Issue) Watchers() []string {
+	watchers := readLines(string(b.Direr()) + sops + WatchersFile)
+	sort.Strings(watchers)
+	return watchers
+}
+
+// Watch adds a recipient to the watchers of an issue.
+func (b *Issue) Watch(recipient string, config Config) error {
+	watchers := b.Watchers()
+	if findArrayString(watchers, recipient) {
+		return fmt.Errorf("%s already watches %s", recipient, b.Title(""))
+	}
+	dir := b.Direr()
+	if err := writeLines(string(dir)+sops+WatchersFile, append(watchers, recipient)); err != nil {
+		return err
+	}
+	InvalidateCache(dir, config)
+	return nil
+}
+
+// Unwatch removes a recipient from the watchers of an issue.
+func (b *Issue) Unwatch(recipient string, config Config) error {
+	var watchers []string
+	for _, w := range b.Watchers() {
+		if w != recipient {
+			watchers = append(watchers, w)
+		}
+	}
+	if len(watchers) == len(b.Watchers()) {
+		return fmt.Errorf("%s does not watch %s", recipient, b.Title(""))
+	}
+	dir := b.Direr()
+	if err := writeLines(string(dir)+sops+WatchersFile, watchers); err != nil {
+		return err
+	}
+	InvalidateCache(dir, config)
+	return nil
+}
+
+// QueryWatches returns the watches of queries of the fit directory in
+// the order they were added.
+func QueryWatches(config Config) []QueryWatch {
+	var watches []QueryWatch
+	for _, line := range readLines(string(FitDirer(config)) + sops + QueryWatchesFile) {
+		if i := strings.Index(line, " "); i > 0 {
+			watches = append(watches, QueryWatch{line[:i], strings.TrimSpace(line[i+1:])})
+		}
+	}
+	return watches
+}
+
+// writeQueryWatches replaces the watches of queries of the fit directory.
+func writeQueryWatches(watches []QueryWatch, config Config) error {
+	var lines []string
+	for _, w := range watches {
+		lines = append(lines, w.Recipient+" "+w.Query)
+	}
+	return writeLines(string(FitDirer(config))+sops+QueryWatchesFile, lines)
+}
+
+// AddQueryWatch adds a recipient watching the issues matching a query.
+func AddQueryWatch(w QueryWatch, config Config) error {
+	if _, err := ParseQuery(w.Query); err != nil {
+		return err
+	}
+	watches := QueryWatches(config)
+	for _, old := range watches {
+		if old == w {
+			return fmt.Errorf("%s already watches %s", w.Recipient, w.Query)
+		}
+	}
+	return writeQueryWatches(append(watches, w), config)
+}
+
+// RemoveQueryWatch removes a recipient watching the issues matching a
+// query.
+func RemoveQueryWatch(w QueryWatch, config Config) error {
+	var watches []QueryWatch
+	for _, old := range QueryWatches(config) {
+		if old != w {
+			watches = append(watches, old)
+		}
+	}
+	if len(watches) == len(QueryWatches(config)) {
+		return fmt.Errorf("%s does not watch %s", w.Recipient, w.Query)
+	}
+	return writeQueryWatches(watches, config)
+}
+
+// issueWatchers returns the watchers of an issue and the recipients of
+// the watches of queries matching it, sorted by name.
+func issueWatchers(b Issue, watches []QueryWatch) []string {
+	watchers := b.Watchers()
+	for _, w := range watches {
+		q, err := ParseQuery(w.Query)
+		if err == nil && q.Match(b) && !findArrayString(watchers, w.Recipient) {
+			watchers = append(watchers, w.Recipient)
+		}
+	}
+	sort.Strings(watchers)
+	return watchers
+}
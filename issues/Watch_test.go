@@ -0,0 +1,87 @@
+package issues
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIssueWatchers(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	b, _ := New("Watched issue", config)
+	if err := b.Watch("sms:+14155551212", config); err != nil {
+		t.Fatalf("Could not watch: %s", err.Error())
+	}
+	b.Watch("email:alice@example.com", config)
+	if err := b.Watch("email:alice@example.com", config); err == nil {
+		t.Errorf("Expected an error watching twice")
+	}
+	if w := b.Watchers(); !reflect.DeepEqual(w, []string{"email:alice@example.com", "sms:+14155551212"}) {
+		t.Errorf("Unexpected watchers %q", w)
+	}
+	if tags := b.StringTags(); len(tags) != 0 {
+		t.Errorf("Unexpected tags of watchers %q", tags)
+	}
+	if err := b.Unwatch("sms:+14155551212", config); err != nil {
+		t.Errorf("Could not unwatch: %s", err.Error())
+	}
+	if err := b.Unwatch("sms:+14155551212", config); err == nil {
+		t.Errorf("Expected an error unwatching twice")
+	}
+	if w := b.Watchers(); !reflect.DeepEqual(w, []string{"email:alice@example.com"}) {
+		t.Errorf("Unexpected watchers %q", w)
+	}
+}
+
+func TestQueryWatches(t *testing.T) {
+	config := Config{}
+	config.DescriptionFileName = "Description"
+	test := tester{} // from Issue_test.go
+	test.Setup()
+	defer test.Teardown()
+
+	secure, _ := New("Secure issue", config)
+	secure.TagIssue("security", config)
+	secure.Watch("email:alice@example.com", config)
+	New("Other issue", config)
+
+	if err := AddQueryWatch(QueryWatch{"sms:+14155551212", "tag:security"}, config); err != nil {
+		t.Fatalf("Could not watch a query: %s", err.Error())
+	}
+	AddQueryWatch(QueryWatch{"email:alice@example.com", "tag:security"}, config)
+	if err := AddQueryWatch(QueryWatch{"sms:+14155551212", "tag:security"}, config); err == nil {
+		t.Errorf("Expected an error watching a query twice")
+	}
+	if err := AddQueryWatch(QueryWatch{"sms:+14155551212", "(tag:security"}, config); err == nil {
+		t.Errorf("Expected an error watching an invalid query")
+	}
+	expected := []QueryWatch{{"sms:+14155551212", "tag:security"}, {"email:alice@example.com", "tag:security"}}
+	if w := QueryWatches(config); !reflect.DeepEqual(w, expected) {
+		t.Errorf("Unexpected watches %+v", w)
+	}
+
+	watchers := map[string][]string{}
+	for _, s := range SnapshotIssues(config) {
+		watchers[s.Name] = s.Watchers
+	}
+	if w := watchers["Secure-issue"]; !reflect.DeepEqual(w, []string{"email:alice@example.com", "sms:+14155551212"}) {
+		t.Errorf("Unexpected watchers of a matching issue %q", w)
+	}
+	if w := watchers["Other-issue"]; len(w) != 0 {
+		t.Errorf("Unexpected watchers of another issue %q", w)
+	}
+
+	if err := RemoveQueryWatch(QueryWatch{"sms:+14155551212", "tag:security"}, config); err != nil {
+		t.Errorf("Could not remove a watch: %s", err.Error())
+	}
+	if err := RemoveQueryWatch(QueryWatch{"sms:+14155551212", "tag:security"}, config); err == nil {
+		t.Errorf("Expected an error removing a watch twice")
+	}
+	if w := QueryWatches(config); !reflect.DeepEqual(w, expected[1:]) {
+		t.Errorf("Unexpected watches after removing %+v", w)
+	}
+}
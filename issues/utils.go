@@ -2,7 +2,6 @@ package issues
 
 import (
 	_ "fmt"
-	"io/ioutil"
 	"os"
 )
 
@@ -37,7 +36,7 @@ func removeFi(slice []os.FileInfo, i int) []os.FileInfo {
 // also in fitapp/utils.go
 func readIssues(dirname string) []os.FileInfo {
 	//var issueList []os.FileInfo
-	fis, _ := ioutil.ReadDir(string(dirname))
+	fis, _ := CurrentStore().ReadDir(string(dirname))
 	issueList := fis
 	for idx, fi := range issueList {
 		//Debug("debug fi " + string(fi.Name()) + "idx " + string(idx) + "\n")
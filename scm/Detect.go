@@ -63,7 +63,14 @@ func DetectSCM(options map[string]bool, config bugs.Config) (SCMHandler, bugs.Di
 		return gm, bugs.Directory(dirFound), nil
 	}
 	if dirFound != "" && scmtype == ".hg" {
-		return HgManager{}, bugs.Directory(dirFound), nil
+		var hm HgManager
+		if val, ok := options["autoclose"]; ok {
+			hm.Autoclose = val
+		}
+		if val, ok := options["use_bug_prefix"]; ok {
+			hm.UseBugPrefix = val
+		}
+		return hm, bugs.Directory(dirFound), nil
 	}
 
 	return nil, "", errors.New("No SCM found")
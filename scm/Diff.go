@@ -0,0 +1,156 @@
+package scm
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Snapshot returns the issues of rev of the repository of handler, or of
+// the working tree when rev is empty.
+func Snapshot(handler SCMHandler, rev string, config bugs.Config) ([]bugs.IssueSnapshot, error) {
+	if rev == "" {
+		return bugs.SnapshotIssues(config), nil
+	}
+	store, err := handler.RevisionStore(rev, config)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+	old := bugs.SetStore(store)
+	defer bugs.SetStore(old)
+	return bugs.SnapshotIssues(config), nil
+}
+
+// DiffIssues returns the issues created, closed, renamed or changed from
+// the revision from to the revision to, the working tree when empty.
+// Unlike the status of staged files used for commit messages, issues are
+// compared by their fields, tags and comments.
+func DiffIssues(handler SCMHandler, from, to string, config bugs.Config) ([]bugs.IssueChange, error) {
+	old, err := Snapshot(handler, from, config)
+	if err != nil {
+		return nil, err
+	}
+	new, err := Snapshot(handler, to, config)
+	if err != nil {
+		return nil, err
+	}
+	return bugs.DiffIssues(old, new, config), nil
+}
+
+// HeadRevision returns the full revision of the commit checked out.
+func HeadRevision(handler SCMHandler, config bugs.Config) (string, error) {
+	var cmd *exec.Cmd
+	if handler.SCMTyper() == "hg" {
+		cmd = exec.Command("hg", "log", "-r", ".", "--template", "{node}")
+	} else {
+		cmd = exec.Command("git", "rev-parse", "HEAD")
+	}
+	cmd.Dir = string(bugs.FitDirer(config))
+	out, err := cmd.Output()
+	rev := strings.TrimSpace(string(out))
+	if err != nil || rev == "" || strings.Trim(rev, "0") == "" {
+		return "", UnsupportedType("No commit checked out")
+	}
+	return rev, nil
+}
+
+// CommitIssues returns the full revision of the commit rev and the
+// issues it changed compared to its first parent. Every issue of a
+// commit without a parent is created.
+func CommitIssues(handler SCMHandler, rev string, config bugs.Config) (string, []bugs.IssueChange, error) {
+	var cmd *exec.Cmd
+	if handler.SCMTyper() == "hg" {
+		cmd = exec.Command("hg", "log", "-r", rev, "--template", "{node} {p1node}")
+	} else {
+		cmd = exec.Command("git", "rev-list", "-1", "--parents", rev)
+	}
+	cmd.Dir = string(bugs.FitDirer(config))
+	out, err := cmd.Output()
+	revs := strings.Fields(string(out))
+	if err != nil || len(revs) == 0 {
+		return "", nil, UnsupportedType("Unknown revision " + rev)
+	}
+	var old []bugs.IssueSnapshot
+	if len(revs) > 1 && strings.Trim(revs[1], "0") != "" {
+		if old, err = Snapshot(handler, revs[1], config); err != nil {
+			return "", nil, err
+		}
+	}
+	new, err := Snapshot(handler, revs[0], config)
+	if err != nil {
+		return "", nil, err
+	}
+	return revs[0], bugs.DiffIssues(old, new, config), nil
+}
+
+// ClosedIssues returns the issues closed by the commits after the
+// revision from up to the revision to, the working tree when empty, or
+// by every commit when from is empty. Every commit that changed the fit
+// directory is compared to the one before it, so issues created and
+// closed between the two revisions are found too. Issues closed and
+// reopened again are not returned. Changes are in the order of names.
+func ClosedIssues(handler SCMHandler, from, to string, config bugs.Config) ([]bugs.IssueChange, error) {
+	head := to
+	if head == "" {
+		head = "HEAD"
+		if handler.SCMTyper() == "hg" {
+			head = "."
+		}
+	}
+	revs, err := handler.Revisions(from, head, config)
+	if err != nil {
+		return nil, err
+	}
+	if to == "" {
+		revs = append(revs, "")
+	}
+	var prev []bugs.IssueSnapshot
+	if from != "" {
+		if prev, err = Snapshot(handler, from, config); err != nil {
+			return nil, err
+		}
+	}
+	closed := map[string]bugs.IssueChange{}
+	for _, rev := range revs {
+		next, err := Snapshot(handler, rev, config)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range bugs.DiffIssues(prev, next, config) {
+			switch {
+			case c.Kind == bugs.IssueClosed:
+				delete(closed, c.OldName)
+				closed[c.Name] = c
+			case c.New.Closed():
+				// created closed, or changed or renamed after closing
+				old, ok := closed[c.OldName]
+				if !ok {
+					old, ok = closed[c.Name]
+				}
+				if ok {
+					delete(closed, c.OldName)
+					c.Old = old.Old
+				}
+				if ok || c.Kind == bugs.IssueCreated {
+					c.Kind = bugs.IssueClosed
+					closed[c.Name] = c
+				}
+			default:
+				delete(closed, c.Name)
+				delete(closed, c.OldName)
+			}
+		}
+		prev = next
+	}
+	var changes []bugs.IssueChange
+	for _, c := range closed {
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, nil
+}
@@ -1,15 +1,16 @@
 package scm
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
@@ -120,51 +121,10 @@ func (mgr GitManager) currentStatus(dir bugs.Directory, config bugs.Config) (clo
 	return ghClosed, issues
 }
 
-// Create commit message by iterating over issues in order:
-// closed issues are most important (something is DONE, ok? ;), those issues will also become hidden)
-// new issues are next, with just updates at the end
-// TODO: do something if this message will be too long
+// commitMsg returns the commit message of the staged changes of the
+// issues, see issuesCommitMsg.
 func (mgr GitManager) commitMsg(dir bugs.Directory, config bugs.Config) []byte {
-	ghClosed, issues := mgr.currentStatus(dir, config)
-
-	done, add, update, together := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
-	var cntd, cnta, cntu int
-
-	for issue, state := range issues {
-		if state.d {
-			fmt.Fprintf(done, ", %q", issue)
-			cntd++
-		} else if state.a {
-			fmt.Fprintf(add, ", %q", issue)
-			cnta++
-		} else if state.m {
-			fmt.Fprintf(update, ", %q", issue)
-			cntu++
-		}
-	}
-
-	outf := func(buf *bytes.Buffer, what string, many bool) {
-		if buf.Len() == 0 {
-			return
-		}
-		var plural string
-		if many {
-			plural = "s:"
-		}
-		item := buf.Bytes()[2:]
-		fmt.Fprintf(together, "%s issue%s %s; ", what, plural, item)
-	}
-	outf(done, "Close", cntd > 1)
-	outf(add, "Create", cnta > 1)
-	outf(update, "Update", cntu > 1)
-	if l := together.Len(); l > 0 {
-		together.Truncate(l - 2) // "; " from last applied outf()
-	}
-
-	if len(ghClosed) > 0 {
-		fmt.Fprintf(together, "\n\nCloses %s\n", strings.Join(ghClosed, ", closes "))
-	}
-	return together.Bytes()
+	return issuesCommitMsg(mgr.currentStatus(dir, config))
 }
 
 // Commit saves files to the SCM. It runs git add -A.
@@ -251,3 +211,108 @@ func (mgr GitManager) SCMIssuesCacher(config bugs.Config) ([]byte, error) { // c
 //then check if updates should be sent for these issues
 //    then send the updates
 //}
+
+// Log returns the commits that changed files of the issue directory,
+// newest first, following renames of the issue. It runs git log -M.
+func (mgr GitManager) Log(issue bugs.Directory, config bugs.Config) ([]LogEntry, error) {
+	git := func(dir string, args ...string) (string, error) {
+		// unquoted paths to match non-ASCII issue directories
+		cmd := exec.Command("git", append([]string{"-c", "core.quotepath=off"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		return string(out), err
+	}
+	issue = bugs.Directory(filepath.Clean(string(issue)))
+	fitdir := filepath.Dir(string(issue))
+	top, err := git(fitdir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.New("Not a git repository: " + fitdir)
+	}
+	top = strings.TrimSpace(top)
+	prefix, err := git(fitdir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	out, err := git(top, "log", "-M", "--name-status", "--format=%x1e%H%x1f%an <%ae>%x1f%aI%x1f%s", "--", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var records []logRecord
+	for _, commit := range strings.Split(out, "\x1e")[1:] {
+		lines := strings.Split(commit, "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		record := logRecord{entry: LogEntry{Commit: fields[0], Author: fields[1], Date: date, Message: fields[3]}}
+		for _, line := range lines[1:] {
+			parts := strings.Split(line, "\t")
+			switch {
+			case len(parts) == 3 && strings.HasPrefix(parts[0], "R"):
+				record.changes = append(record.changes, logChange{status: "R", oldPath: parts[1], path: parts[2]})
+			case len(parts) == 2:
+				record.changes = append(record.changes, logChange{status: parts[0][:1], path: parts[1]})
+			}
+		}
+		records = append(records, record)
+	}
+
+	show := func(rev, file string, parent bool) string {
+		if parent {
+			rev += "^"
+		}
+		out, _ := git(top, "show", rev+":"+file)
+		return out
+	}
+	diff := func(rev, oldFile, file string) string {
+		out, _ := git(top, "show", "--format=", "-M", rev, "--", oldFile, file)
+		return out
+	}
+	return issueLog(records, prefix+string(issue.ShortNamer()), show, diff), nil
+}
+
+// RevisionStore returns the issues at rev, read from the objects of the
+// repository without changing the working tree.
+func (mgr GitManager) RevisionStore(rev string, config bugs.Config) (bugs.Store, error) {
+	fitdir := string(bugs.FitDirer(config))
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = fitdir
+	top, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("Not a git repository: " + fitdir)
+	}
+	return bugs.NewGitStore(revisionRoot(strings.TrimSpace(string(top)), config), rev)
+}
+
+// RevisionAt returns the last commit of HEAD made before date.
+func (mgr GitManager) RevisionAt(date time.Time, config bugs.Config) (string, error) {
+	cmd := exec.Command("git", "rev-list", "-1", "--before="+date.Format(time.RFC3339), "HEAD")
+	cmd.Dir = string(bugs.FitDirer(config))
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	if rev := strings.TrimSpace(string(out)); rev != "" {
+		return rev, nil
+	}
+	return "", errors.New("No commit before " + date.Format(time.RFC3339))
+}
+
+// Revisions returns the commits of to after from that changed the fit
+// directory, oldest first, or every such commit of to when from is empty.
+func (mgr GitManager) Revisions(from, to string, config bugs.Config) ([]string, error) {
+	revs := to
+	if from != "" {
+		revs = from + ".." + to
+	}
+	cmd := exec.Command("git", "rev-list", "--reverse", revs, "--", ".")
+	cmd.Dir = string(bugs.FitDirer(config))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("Unknown revisions " + revs)
+	}
+	return strings.Fields(string(out)), nil
+}
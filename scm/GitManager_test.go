@@ -274,3 +274,101 @@ func TestGitManagerAutoclosingGitHub(t *testing.T) {
 		}
 	}
 }
+
+func TestGitLog(t *testing.T) {
+	var config bugs.Config
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	if git == false {
+		t.Skip("WARN git executable not found")
+	}
+	tester := GitTester{fitdirname: config.FitDirName}
+	if err := tester.Setup(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	defer tester.TearDown()
+	commit := func(msg string) {
+		runCmd("git", "add", "-A")
+		if out, err := runCmd("git", "-c", "user.name=Tester", "-c", "user.email=tester@example.com", "commit", "-q", "-m", msg); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	}
+	issue := config.FitDirName + sops + "Test-bug"
+	os.MkdirAll(issue+sops+"tags", 0755)
+	ioutil.WriteFile(issue+sops+"Description", []byte(""), 0644)
+	ioutil.WriteFile(tester.WorkDir()+sops+"README", []byte("not an issue\n"), 0644)
+	commit("Create")
+	ioutil.WriteFile(issue+sops+"Description", []byte("hello\n"), 0644)
+	ioutil.WriteFile(issue+sops+"Status", []byte("open\n"), 0644)
+	ioutil.WriteFile(issue+sops+"tags"+sops+"cli", []byte(""), 0644)
+	ioutil.WriteFile(tester.WorkDir()+sops+"README", []byte("still not an issue\n"), 0644)
+	commit("Update")
+	// retitle with a changed status and a removed tag
+	renamed := config.FitDirName + sops + "Renamed-bug"
+	os.Rename(issue, renamed)
+	ioutil.WriteFile(renamed+sops+"Status", []byte("closed\n"), 0644)
+	os.Remove(renamed + sops + "tags" + sops + "cli")
+	commit("Rename")
+
+	entries, err := GitManager{}.Log(bugs.Directory(tester.WorkDir()+sops+renamed), config)
+	if err != nil {
+		t.Fatal("Unexpected log error: " + err.Error())
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 log entries, got %+v", entries)
+	}
+	for i, expected := range []struct {
+		message, dir, renamedFrom, files string
+	}{
+		{"Rename", "Renamed-bug", "Test-bug", "R Description, R Status, D tags/cli"},
+		{"Update", "Test-bug", "", "M Description, A Status, A tags/cli"},
+		{"Create", "Test-bug", "", "A Description"},
+	} {
+		e := entries[i]
+		var files []string
+		for _, f := range e.Files {
+			files = append(files, f.Status+" "+f.Name)
+		}
+		if e.Message != expected.message || e.Dir != expected.dir || e.RenamedFrom != expected.renamedFrom ||
+			strings.Join(files, ", ") != expected.files || e.Author != "Tester <tester@example.com>" || e.Date.IsZero() {
+			t.Errorf("Unexpected log entry %d: %+v %s", i, e, files)
+		}
+	}
+	status := entries[0].Files[1]
+	if status.Before != "open\n" || status.After != "closed\n" {
+		t.Errorf("Unexpected Status change %+v", status)
+	}
+	if diff := entries[1].Files[0].Diff; !strings.Contains(diff, "\n+hello\n") {
+		t.Errorf("Unexpected Description diff %s", diff)
+	}
+
+	// git quotes non-ASCII paths unless core.quotepath is off
+	accented := config.FitDirName + sops + "Café-bug"
+	os.MkdirAll(accented, 0755)
+	ioutil.WriteFile(accented+sops+"Description", []byte("crème\n"), 0644)
+	commit("Accented")
+	entries, err = GitManager{}.Log(bugs.Directory(tester.WorkDir()+sops+accented), config)
+	if err != nil {
+		t.Fatal("Unexpected log error: " + err.Error())
+	}
+	if len(entries) != 1 || entries[0].Dir != "Café-bug" || len(entries[0].Files) != 1 || entries[0].Files[0].After != "crème\n" {
+		t.Errorf("Unexpected log of a non-ASCII issue %+v", entries)
+	}
+}
+
+func TestGitRevisionStore(t *testing.T) {
+	if git == false {
+		t.Skip("WARN git executable not found")
+	}
+	tester := GitTester{fitdirname: "fit", handler: GitManager{}}
+	if err := tester.Setup(); err != nil {
+		t.Fatal("Could not initialize git: " + err.Error())
+	}
+	defer tester.TearDown()
+	runtestRevisionStore(&tester, t, "HEAD^", func(msg string) {
+		runCmd("git", "add", "-A")
+		if out, err := runCmd("git", "-c", "user.name=Tester", "-c", "user.email=tester@example.com", "commit", "-q", "-m", msg); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	})
+}
@@ -1,28 +1,191 @@
 package scm
 
 import (
+	"errors"
 	"fmt"
 	bugs "github.com/driusan/bug/bugs"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 )
 
-// HgManager is a struct for a mercurial (hg) software configuration manager.
-type HgManager struct{}
+// HgManager is a struct for a mercurial (hg) software configuration
+// manager, with the fields of GitManager.
+type HgManager struct {
+	Autoclose    bool
+	UseBugPrefix bool
+}
+
+// hgRoot returns the root of the hg repository of dir and the path of
+// dir below the root with / separators and a trailing /.
+func hgRoot(dir string) (string, string, error) {
+	cmd := exec.Command("hg", "root")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", errors.New("Not a hg repository: " + dir)
+	}
+	top := strings.TrimSpace(string(out))
+	realTop, err := filepath.EvalSymlinks(top)
+	if err != nil {
+		realTop = top
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		realDir = dir
+	}
+	rel, err := filepath.Rel(realTop, realDir)
+	if err != nil {
+		return "", "", err
+	}
+	return top, filepath.ToSlash(rel) + "/", nil
+}
+
+// hgStatus returns the lines of hg status with flags of the files below
+// dir, like "M fit/Issue/Description" with paths from the root of the
+// repository.
+func hgStatus(dir string, flags ...string) ([]string, error) {
+	top, prefix, err := hgRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("hg", append([]string{"status", "--print0"}, flags...)...)
+	cmd.Dir = top
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, entry := range strings.Split(string(out), "\000") {
+		if len(entry) < 3 {
+			continue
+		}
+		if path := filepath.ToSlash(entry[2:]); strings.HasPrefix(path, prefix) {
+			lines = append(lines, entry[:2]+path)
+		}
+	}
+	return lines, nil
+}
+
+// hgIssuesStatus returns the issues changed by hg status lines of the
+// files below prefix, the fit directory, with the rules of
+// GitManager.currentStatus. hg has no staging area, so added and unknown
+// (?) files are added, removed and missing (!) files are deleted.
+func hgIssuesStatus(lines []string, prefix string, config bugs.Config) issuesStatus {
+	issues := issuesStatus{}
+	for _, line := range lines {
+		path := strings.TrimPrefix(line[2:], prefix)
+		i := strings.Index(path, "/")
+		if i <= 0 {
+			// files of the fit directory like .fit_idnext_<n>
+			continue
+		}
+		name, op := path[:i], line[0]
+		desc := path[i+1:] == config.DescriptionFileName
+		issue := issues[name]
+		switch {
+		case desc && (op == 'R' || op == '!'):
+			issue.d = true
+		case desc && (op == 'A' || op == '?'):
+			issue.a = true
+		default:
+			issue.m = true
+		}
+		issues[name] = issue
+	}
+	return issues
+}
 
-// Purge would give the hg command to purge files but this is not supported.
+// currentStatus returns the issues closed on GitHub and the created,
+// updated and closed issues of the changes of the working directory
+// below dir, like GitManager.currentStatus.
+func (mgr HgManager) currentStatus(dir bugs.Directory, config bugs.Config) (closedOnGitHub []string, _ issuesStatus) {
+	top, prefix, err := hgRoot(filepath.Clean(string(dir)))
+	if err != nil {
+		return nil, issuesStatus{}
+	}
+	lines, _ := hgStatus(filepath.Clean(string(dir)))
+	ghRegex := regexp.MustCompile("(?im)^-Github:(.*)$")
+	var ghClosed []string
+	for _, line := range lines {
+		if !mgr.Autoclose || (line[0] != 'R' && line[0] != '!') || !strings.HasSuffix(line, "/Identifier") {
+			continue
+		}
+		cmd := exec.Command("hg", "diff", filepath.FromSlash(line[2:]))
+		cmd.Dir = top
+		diffout, _ := cmd.Output()
+		if matches := ghRegex.FindStringSubmatch(string(diffout)); len(matches) > 1 {
+			ghClosed = append(ghClosed, strings.TrimSpace(matches[1]))
+		}
+	}
+	issues := hgIssuesStatus(lines, prefix, config)
+	return ghClosed, issues
+}
+
+// commitMsg returns the commit message of the changes of the issues,
+// see issuesCommitMsg.
+func (mgr HgManager) commitMsg(dir bugs.Directory, config bugs.Config) []byte {
+	return issuesCommitMsg(mgr.currentStatus(dir, config))
+}
+
+// Purge removes the files below dir that hg does not track and the
+// directories left empty, like hg purge without needing the purge
+// extension. Ignored files are kept.
 func (mgr HgManager) Purge(dir bugs.Directory) error {
-	return UnsupportedType("Purge is not supported under Hg. Sorry!")
+	root := filepath.Clean(string(dir))
+	top, prefix, err := hgRoot(root)
+	if err != nil {
+		return err
+	}
+	lines, err := hgStatus(root, "--unknown")
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Printf("Removing %s\n", line[2:])
+		if err := os.Remove(filepath.Join(top, filepath.FromSlash(line[2:]))); err != nil {
+			return err
+		}
+	}
+	var dirs []string
+	filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && fi.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	// deepest first, hg does not track directories
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if fis, err := ioutil.ReadDir(dirs[i]); err == nil && len(fis) == 0 && os.Remove(dirs[i]) == nil {
+			rel, _ := filepath.Rel(root, dirs[i])
+			fmt.Printf("Removing %s%s/\n", prefix, filepath.ToSlash(rel))
+		}
+	}
+	return nil
 }
 
-// Commit gives the hg command to commit files.
-func (mgr HgManager) Commit(dir bugs.Directory, commitMsg string, config bugs.Config) error {
-	cmd := exec.Command("hg", "addremove", string(dir))
+// Commit gives the hg command to commit files. The message lists the
+// created, updated and closed issues like GitManager.Commit, or is
+// backupCommitMsg without any.
+func (mgr HgManager) Commit(dir bugs.Directory, backupCommitMsg string, config bugs.Config) error {
+	// --similarity 100 records the renames of retitle for Log
+	cmd := exec.Command("hg", "addremove", "--similarity", "100", string(dir))
 	if err := cmd.Run(); err != nil {
 		fmt.Printf("Could not add issues to be committed: %s?\n", err.Error())
 		return err
 	}
 
-	cmd = exec.Command("hg", "commit", string(dir), "-m", commitMsg)
+	msg := string(mgr.commitMsg(dir, config))
+	if msg == "" {
+		msg = backupCommitMsg
+	} else if mgr.UseBugPrefix {
+		msg = "issue: " + msg
+	}
+	cmd = exec.Command("hg", "commit", string(dir), "-m", msg)
 	// stdout and stderr not captured in HgManager_test.go runtestCommitDirtyTree()
 	if err := cmd.Run(); err != nil {
 		//fmt.Printf("post 2 runtestCommitDirtyTree error %v\n", err) // 255 when $?=1 and stdout text "nothing changed" present
@@ -38,12 +201,152 @@ func (mgr HgManager) SCMTyper() string {
 	return "hg"
 }
 
-// SCMIssuesUpdaters returns in error
+// SCMIssuesUpdaters returns []byte of the changed, unknown and missing
+// files of the fit directory, which Commit would add or remove.
 func (mgr HgManager) SCMIssuesUpdaters(config bugs.Config) ([]byte, error) {
-	return []byte(""), nil
+	lines, err := hgStatus(string(bugs.FitDirer(config)))
+	if err != nil || len(lines) == 0 {
+		return []byte(""), nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), errors.New("Files In " + config.FitDirName + "/ Need Committing")
 }
 
-// SCMIssuesCacher returns in error
+// SCMIssuesCacher returns []byte of the added, removed and modified files
+// of the fit directory, what hg commit would commit without addremove.
+// hg has no staging area, these are its closest equivalent.
 func (mgr HgManager) SCMIssuesCacher(config bugs.Config) ([]byte, error) {
-	return []byte(""), nil
+	lines, err := hgStatus(string(bugs.FitDirer(config)), "--added", "--removed", "--modified")
+	if err != nil || len(lines) == 0 {
+		return []byte(""), nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), errors.New("Files In " + config.FitDirName + "/ Staged and Need Committing")
+}
+
+// hgCopyRegex matches a file_copies entry of hg log, "new (old)".
+var hgCopyRegex = regexp.MustCompile(`^(.*) \((.*)\)$`)
+
+// hgLogTemplate prints 8 lines for each commit: the node, author, date,
+// first line of the message and the tab separated added, removed,
+// modified and copied files.
+const hgLogTemplate = `{node}\n{author}\n{date|rfc3339date}\n{desc|firstline}\n` +
+	`{join(file_adds, "\t")}\n{join(file_dels, "\t")}\n{join(file_mods, "\t")}\n{join(file_copies, "\t")}\n`
+
+// hgLogRecords reads the commits of hg log output of hgLogTemplate. An
+// added file copied from another is a rename.
+func hgLogRecords(out string) []logRecord {
+	lines := strings.Split(out, "\n")
+	var records []logRecord
+	for i := 0; i+8 <= len(lines); i += 8 {
+		date, _ := time.Parse(time.RFC3339, lines[i+2])
+		record := logRecord{entry: LogEntry{Commit: lines[i], Author: lines[i+1], Date: date, Message: lines[i+3]}}
+		copies := map[string]string{}
+		for _, c := range strings.Split(lines[i+7], "\t") {
+			if m := hgCopyRegex.FindStringSubmatch(c); m != nil {
+				copies[m[1]] = m[2]
+			}
+		}
+		for j, status := range []string{"A", "D", "M"} {
+			for _, file := range strings.Split(lines[i+4+j], "\t") {
+				if file == "" {
+					continue
+				}
+				if old, ok := copies[file]; ok && status == "A" {
+					record.changes = append(record.changes, logChange{status: "R", path: file, oldPath: old})
+				} else {
+					record.changes = append(record.changes, logChange{status: status, path: file})
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Log returns the commits that changed files of the issue directory,
+// newest first, following renames of the issue. Renames are recorded by
+// hg addremove --similarity 100 in Commit.
+func (mgr HgManager) Log(issue bugs.Directory, config bugs.Config) ([]LogEntry, error) {
+	hg := func(dir string, args ...string) (string, error) {
+		cmd := exec.Command("hg", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		return string(out), err
+	}
+	issue = bugs.Directory(filepath.Clean(string(issue)))
+	fitdir := filepath.Dir(string(issue))
+	top, err := hg(fitdir, "root")
+	if err != nil {
+		return nil, errors.New("Not a hg repository: " + fitdir)
+	}
+	top = strings.TrimSpace(top)
+	rel, err := filepath.Rel(top, fitdir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.ToSlash(rel) + "/"
+
+	out, err := hg(top, "log", "--template", hgLogTemplate, filepath.FromSlash(prefix))
+	if err != nil {
+		return nil, err
+	}
+	records := hgLogRecords(out)
+
+	show := func(rev, file string, parent bool) string {
+		if parent {
+			rev = "p1(" + rev + ")"
+		}
+		out, _ := hg(top, "cat", "-r", rev, filepath.FromSlash(file))
+		return out
+	}
+	diff := func(rev, oldFile, file string) string {
+		out, _ := hg(top, "diff", "--git", "-c", rev, filepath.FromSlash(oldFile), filepath.FromSlash(file))
+		return out
+	}
+	return issueLog(records, prefix+string(issue.ShortNamer()), show, diff), nil
+}
+
+// RevisionStore returns the issues at rev, read with hg cat without
+// changing the working tree.
+func (mgr HgManager) RevisionStore(rev string, config bugs.Config) (bugs.Store, error) {
+	fitdir := string(bugs.FitDirer(config))
+	cmd := exec.Command("hg", "root")
+	cmd.Dir = fitdir
+	top, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("Not a hg repository: " + fitdir)
+	}
+	return hgRevisionStore(revisionRoot(strings.TrimSpace(string(top)), config), rev)
+}
+
+// RevisionAt returns the last ancestor of the working directory committed
+// before date.
+func (mgr HgManager) RevisionAt(date time.Time, config bugs.Config) (string, error) {
+	cmd := exec.Command("hg", "log", "-r", "last(::. and date('<"+date.Format("2006-01-02 15:04:05 -0700")+"'))",
+		"--template", "{node}")
+	cmd.Dir = string(bugs.FitDirer(config))
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	if rev := strings.TrimSpace(string(out)); rev != "" {
+		return rev, nil
+	}
+	return "", errors.New("No commit before " + date.Format(time.RFC3339))
+}
+
+// Revisions returns the changesets of to after from that changed the fit
+// directory, oldest first, or every such changeset of to when from is
+// empty.
+func (mgr HgManager) Revisions(from, to string, config bugs.Config) ([]string, error) {
+	revs := "::" + to
+	if from != "" {
+		revs = "only(" + to + ", " + from + ")"
+	}
+	cmd := exec.Command("hg", "log", "-r", "sort("+revs+", rev)", "--template", "{node}\n", ".")
+	cmd.Dir = string(bugs.FitDirer(config))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("Unknown revisions " + revs)
+	}
+	return strings.Fields(string(out)), nil
 }
@@ -3,11 +3,14 @@ package scm
 import (
 	"flag"
 	"fmt"
+	bugs "github.com/driusan/bug/bugs"
 	"io/ioutil"
 	"log"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 type HgCommit struct {
@@ -169,22 +172,198 @@ func TestHgTyper(t *testing.T) {
 	}
 }
 
+func TestHgIssuesStatus(t *testing.T) {
+	var config bugs.Config
+	config.DescriptionFileName = "Description"
+	for _, test := range []struct {
+		name     string
+		lines    []string
+		prefix   string
+		expected issuesStatus
+	}{
+		{"no changes", nil, "fit/", issuesStatus{}},
+		{"created", []string{
+			"A fit/New-issue/Description",
+			"? fit/Unknown-issue/Description",
+			"A fit/New-issue/Status",
+		}, "fit/", issuesStatus{"New-issue": {a: true, m: true}, "Unknown-issue": {a: true}}},
+		{"closed", []string{
+			"R fit/Old-issue/Description",
+			"R fit/Old-issue/Status",
+			"! fit/Missing-issue/Description",
+		}, "fit/", issuesStatus{"Old-issue": {d: true, m: true}, "Missing-issue": {d: true}}},
+		{"updated", []string{
+			"M fit/Changed-issue/Status",
+			"? fit/Changed-issue/tags/security",
+			"M fit/Edited-issue/Description",
+			"R fit/Edited-issue/comment-1",
+		}, "fit/", issuesStatus{"Changed-issue": {m: true}, "Edited-issue": {m: true}}},
+		{"renamed", []string{
+			"A fit/New-title/Description",
+			"R fit/Old-title/Description",
+		}, "fit/", issuesStatus{"New-title": {a: true}, "Old-title": {d: true}}},
+		{"fit directory files", []string{
+			"? fit/.fit_idnext_1002",
+			"M fit/.fit_watchers",
+		}, "fit/", issuesStatus{}},
+		{"nested fit directory", []string{
+			"A project/fit/New-issue/Description",
+			"M project/fit/Changed-issue/Priority",
+		}, "project/fit/", issuesStatus{"New-issue": {a: true}, "Changed-issue": {m: true}}},
+	} {
+		if issues := hgIssuesStatus(test.lines, test.prefix, config); !reflect.DeepEqual(issues, test.expected) {
+			t.Errorf("Unexpected status of %s: %+v", test.name, issues)
+		}
+	}
+}
+
+func TestHgLogRecords(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		out      string
+		expected []logRecord
+	}{
+		{"empty", "", nil},
+		{"created", "abc123\nTester <tester@example.com>\n2019-01-02T03:04:05+00:00\nCreate\n" +
+			"fit/Test-bug/Description\tfit/Test-bug/Status\n\n\n\n",
+			[]logRecord{{
+				entry: LogEntry{Commit: "abc123", Author: "Tester <tester@example.com>",
+					Date: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC), Message: "Create"},
+				changes: []logChange{{status: "A", path: "fit/Test-bug/Description"}, {status: "A", path: "fit/Test-bug/Status"}},
+			}}},
+		{"renamed", "def456\nTester\n2019-01-03T00:00:00+00:00\nRename\n" +
+			"fit/New-bug/Description\tfit/New-bug/Status\n" +
+			"fit/Old-bug/Description\tfit/Old-bug/Status\tfit/Old-bug/tags/cli\n" +
+			"fit/Other-bug/Priority\n" +
+			"fit/New-bug/Description (fit/Old-bug/Description)\tfit/New-bug/Status (fit/Old-bug/Status)\n",
+			[]logRecord{{
+				entry: LogEntry{Commit: "def456", Author: "Tester",
+					Date: time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC), Message: "Rename"},
+				changes: []logChange{
+					{status: "R", path: "fit/New-bug/Description", oldPath: "fit/Old-bug/Description"},
+					{status: "R", path: "fit/New-bug/Status", oldPath: "fit/Old-bug/Status"},
+					{status: "D", path: "fit/Old-bug/Description"},
+					{status: "D", path: "fit/Old-bug/Status"},
+					{status: "D", path: "fit/Old-bug/tags/cli"},
+					{status: "M", path: "fit/Other-bug/Priority"},
+				},
+			}}},
+		{"truncated", "abc123\nTester\n2019-01-02T03:04:05+00:00\n", nil},
+	} {
+		records := hgLogRecords(test.out)
+		for i := range records {
+			// compare the instants, not the locations
+			if i < len(test.expected) && records[i].entry.Date.Equal(test.expected[i].entry.Date) {
+				records[i].entry.Date = test.expected[i].entry.Date
+			}
+		}
+		if !reflect.DeepEqual(records, test.expected) {
+			t.Errorf("Unexpected records of %s: %+v", test.name, records)
+		}
+	}
+}
+
+// setupHgIssues commits an issue to a new hg repository and returns the
+// config and the tester to tear down.
+func setupHgIssues(t *testing.T) (bugs.Config, HgTester) {
+	var config bugs.Config
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	os.Unsetenv("FIT")
+	os.Setenv("HGUSER", "Tester <tester@example.com>")
+	h := HgTester{}
+	if err := h.Setup(); err != nil {
+		t.Fatal("Could not initialize hg: " + err.Error())
+	}
+	os.MkdirAll(config.FitDirName+sops+"Test-bug", 0755)
+	ioutil.WriteFile(config.FitDirName+sops+"Test-bug"+sops+"Description", []byte("test\n"), 0644)
+	if err := h.handler.Commit(bugs.Directory(h.WorkDir()+sops+config.FitDirName), "Initial commit", config); err != nil {
+		h.TearDown()
+		t.Fatal("Could not commit: " + err.Error())
+	}
+	return config, h
+}
+
 func TestHgPurge(t *testing.T) {
-	// This should eventually be replaced by something more
-	// like:
-	//      h := HgTester{}
-	//      h.handler = HgManager{}
-	//      runtestPurgeFiles(&h, t)
-	// but since the current behaviour is to return a not
-	// supported error, that would evidently fail..
-	m := HgManager{}
-	err := m.Purge(dops + "tmp" + dops + "imaginaryHgRepo")
-
-	switch err.(type) {
-	case UnsupportedType:
-		// This is valid, do nothing.
-	default:
-		t.Error("Unexpected return value for Hg purge function.")
+	if hg == false {
+		t.Skip("WARN hg executable not found")
+	}
+	config, h := setupHgIssues(t)
+	defer h.TearDown()
+
+	os.MkdirAll(config.FitDirName+sops+"Test-Purge-bug"+sops+"tags", 0755)
+	ioutil.WriteFile(config.FitDirName+sops+"Test-Purge-bug"+sops+"Description", []byte(""), 0644)
+	ioutil.WriteFile(config.FitDirName+sops+"Test-bug"+sops+"Status", []byte("open\n"), 0644)
+	stdout, _ := captureOutput(func() {
+		if err := h.handler.Purge(bugs.Directory(h.WorkDir() + sops + config.FitDirName)); err != nil {
+			t.Error("Error purging directory: " + err.Error())
+		}
+	}, t)
+	if !strings.Contains(stdout, "Removing fit/Test-Purge-bug/Description\n") ||
+		!strings.Contains(stdout, "Removing fit/Test-Purge-bug/\n") {
+		t.Errorf("Unexpected output of purge: %s", stdout)
+	}
+	fis, _ := ioutil.ReadDir(config.FitDirName)
+	if len(fis) != 1 || fis[0].Name() != "Test-bug" {
+		t.Errorf("Expected only Test-bug to remain, got %v", fis)
 	}
+	files, _ := ioutil.ReadDir(config.FitDirName + sops + "Test-bug")
+	if len(files) != 1 || files[0].Name() != "Description" {
+		t.Errorf("Expected only the committed files of Test-bug to remain, got %v", files)
+	}
+}
+
+func TestHgCommitStatus(t *testing.T) {
+	if hg == false {
+		t.Skip("WARN hg executable not found")
+	}
+	config, h := setupHgIssues(t)
+	defer h.TearDown()
+	h.handler = HgManager{UseBugPrefix: true}
+
+	if out, err := h.handler.SCMIssuesUpdaters(config); err != nil || len(out) != 0 {
+		t.Errorf("Unexpected files to commit %q %v", out, err)
+	}
+	os.MkdirAll(config.FitDirName+sops+"Fresh-bug", 0755)
+	ioutil.WriteFile(config.FitDirName+sops+"Fresh-bug"+sops+"Description", []byte("fresh\n"), 0644)
+	ioutil.WriteFile(config.FitDirName+sops+"Test-bug"+sops+"Description", []byte("changed\n"), 0644)
+	out, err := h.handler.SCMIssuesUpdaters(config)
+	if err == nil || string(out) != "M fit/Test-bug/Description\n? fit/Fresh-bug/Description\n" {
+		t.Errorf("Unexpected files to commit %q %v", out, err)
+	}
+	out, err = h.handler.SCMIssuesCacher(config)
+	if err == nil || string(out) != "M fit/Test-bug/Description\n" {
+		t.Errorf("Unexpected tracked files to commit %q %v", out, err)
+	}
+	if err := h.handler.Commit(bugs.Directory(h.WorkDir()+sops+config.FitDirName), "Backup message", config); err != nil {
+		t.Fatal("Could not commit: " + err.Error())
+	}
+	msg, _ := runCmd("hg", "log", "-l", "1", "--template", "{desc}")
+	if msg != `issue: Create issue "Fresh-bug"; Update issue "Test-bug"` {
+		t.Errorf("Unexpected commit message %q", msg)
+	}
+
+	os.RemoveAll(config.FitDirName + sops + "Fresh-bug")
+	h.handler.Commit(bugs.Directory(h.WorkDir()+sops+config.FitDirName), "Backup message", config)
+	msg, _ = runCmd("hg", "log", "-l", "1", "--template", "{desc}")
+	if msg != `issue: Close issue "Fresh-bug"` {
+		t.Errorf("Unexpected commit message %q", msg)
+	}
+	h.AssertCleanTree(t)
+}
 
+func TestHgRevisionStore(t *testing.T) {
+	if hg == false {
+		t.Skip("WARN hg executable not found")
+	}
+	tester := HgTester{}
+	if err := tester.Setup(); err != nil {
+		t.Fatal("Could not initialize hg: " + err.Error())
+	}
+	defer tester.TearDown()
+	runtestRevisionStore(&tester, t, ".^", func(msg string) {
+		if out, err := runCmd("hg", "commit", "-A", "-u", "Tester <tester@example.com>", "-m", msg); err != nil {
+			t.Fatalf("Could not commit %s: %s", msg, out)
+		}
+	})
 }
@@ -1,6 +1,9 @@
 package scm
 
-import bugs "github.com/driusan/bug/bugs"
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"time"
+)
 
 // SCMHandler interface defines how to call Commit, Purge and SCMTyper.
 type SCMHandler interface {
@@ -9,6 +12,33 @@ type SCMHandler interface {
 	SCMTyper() string
 	SCMIssuesUpdaters(config bugs.Config) ([]byte, error)
 	SCMIssuesCacher(config bugs.Config) ([]byte, error)
+	Log(issue bugs.Directory, config bugs.Config) ([]LogEntry, error)
+	RevisionStore(rev string, config bugs.Config) (bugs.Store, error)
+	RevisionAt(date time.Time, config bugs.Config) (string, error)
+	Revisions(from, to string, config bugs.Config) ([]string, error)
+}
+
+// LogEntry is a commit that changed files of an issue.
+type LogEntry struct {
+	Commit  string
+	Author  string
+	Date    time.Time
+	Message string
+	// Dir is the issue directory name after the commit and RenamedFrom
+	// the name before when the commit renamed the issue.
+	Dir         string
+	RenamedFrom string
+	Files       []LogFile
+}
+
+// LogFile is a file of an issue changed by a commit, with the contents
+// before and after the commit and the diff.
+type LogFile struct {
+	Name   string
+	Status string // A, M, D or R
+	Before string
+	After  string
+	Diff   string
 }
 
 // FileStatus type holds information about a file.
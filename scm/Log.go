@@ -0,0 +1,155 @@
+package scm
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// logChange is a changed file of a commit, paths are relative to the
+// root of the repository with / separators.
+type logChange struct {
+	status  string // A, M, D or R
+	path    string
+	oldPath string
+}
+
+// logRecord is a commit of the issues directory as listed by git or hg.
+type logRecord struct {
+	entry   LogEntry
+	changes []logChange
+}
+
+// logContents returns the contents of file at rev, or at the parent of rev
+// when parent is true.
+type logContents func(rev, file string, parent bool) string
+
+// logDiff returns the diff of rev for the file oldFile renamed to file.
+type logDiff func(rev, oldFile, file string) string
+
+// issueLog selects the records, newest first, that changed files of the
+// issue directory dir. Older records are searched for the name dir had
+// before each rename, renames detected by the SCM or a commit deleting
+// a directory with the same files dir gained.
+func issueLog(records []logRecord, dir string, show logContents, diff logDiff) []LogEntry {
+	entries := []LogEntry{}
+	for _, record := range records {
+		prefix := dir + "/"
+		var changes []logChange
+		renamedFrom := ""
+		for _, c := range record.changes {
+			if !strings.HasPrefix(c.path, prefix) {
+				continue
+			}
+			if rel := "/" + strings.TrimPrefix(c.path, prefix); c.status == "R" &&
+				!strings.HasPrefix(c.oldPath, prefix) && strings.HasSuffix(c.oldPath, rel) {
+				renamedFrom = strings.TrimSuffix(c.oldPath, rel)
+			}
+			changes = append(changes, c)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if renamedFrom == "" {
+			renamedFrom = logRenamedFrom(record.changes, changes, prefix)
+		}
+		if renamedFrom != "" {
+			// files added to dir and deleted from the old name are renamed,
+			// other files deleted from the old name are removed
+			deleted := map[string]bool{}
+			for _, c := range record.changes {
+				if c.status == "D" && strings.HasPrefix(c.path, renamedFrom+"/") {
+					deleted[c.path] = true
+				}
+			}
+			for i, c := range changes {
+				old := renamedFrom + "/" + strings.TrimPrefix(c.path, prefix)
+				if c.status == "R" {
+					delete(deleted, c.oldPath)
+				} else if c.status == "A" && deleted[old] {
+					changes[i] = logChange{status: "R", path: c.path, oldPath: old}
+					delete(deleted, old)
+				}
+			}
+			for _, c := range record.changes {
+				if deleted[c.path] {
+					changes = append(changes, logChange{status: "D", path: prefix + strings.TrimPrefix(c.path, renamedFrom+"/"), oldPath: c.path})
+				}
+			}
+		}
+
+		entry := record.entry
+		entry.Dir = path.Base(dir)
+		if renamedFrom != "" {
+			entry.RenamedFrom = path.Base(renamedFrom)
+		}
+		for _, c := range changes {
+			f := LogFile{Name: strings.TrimPrefix(c.path, prefix), Status: c.status}
+			old := c.path
+			if c.oldPath != "" {
+				old = c.oldPath
+			}
+			if c.status != "A" {
+				f.Before = show(entry.Commit, old, true)
+			}
+			if c.status != "D" {
+				f.After = show(entry.Commit, c.path, false)
+			}
+			if c.status != "R" || f.Before != f.After {
+				f.Diff = diff(entry.Commit, old, c.path)
+			}
+			entry.Files = append(entry.Files, f)
+		}
+		sort.SliceStable(entry.Files, func(i, j int) bool {
+			return entry.Files[i].Name < entry.Files[j].Name
+		})
+		entries = append(entries, entry)
+		if renamedFrom != "" {
+			dir = renamedFrom
+		}
+	}
+	return entries
+}
+
+// logRenamedFrom returns the issue directory deleted by a commit with the
+// files it added to the issue directory prefix, for SCMs that did not
+// detect the rename, like git for empty files.
+func logRenamedFrom(all, changes []logChange, prefix string) string {
+	added := map[string]bool{}
+	for _, c := range changes {
+		if c.status != "A" {
+			return ""
+		}
+		added[strings.TrimPrefix(c.path, prefix)] = true
+	}
+	// issue directories are in the same parent directory
+	parent := path.Dir(strings.TrimSuffix(prefix, "/")) + "/"
+	deleted := map[string]map[string]bool{}
+	for _, c := range all {
+		if c.status != "D" || strings.HasPrefix(c.path, prefix) || !strings.HasPrefix(c.path, parent) {
+			continue
+		}
+		rel := strings.TrimPrefix(c.path, parent)
+		i := strings.Index(rel, "/")
+		if i == -1 {
+			continue
+		}
+		if deleted[rel[:i]] == nil {
+			deleted[rel[:i]] = map[string]bool{}
+		}
+		deleted[rel[:i]][rel[i+1:]] = true
+	}
+	for dir, names := range deleted {
+		if len(names) != len(added) {
+			continue
+		}
+		same := true
+		for name := range added {
+			same = same && names[name]
+		}
+		if same {
+			return parent + dir
+		}
+	}
+	return ""
+}
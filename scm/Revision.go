@@ -0,0 +1,79 @@
+package scm
+
+import (
+	bugs "github.com/driusan/bug/bugs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// revisionRoot returns the working tree root top of the repository as a
+// parent of the fit directory, so that the paths of issues and of the
+// Store of a revision agree when top is reached through a symlink.
+func revisionRoot(top string, config bugs.Config) string {
+	fitdir := string(bugs.FitDirer(config))
+	realTop, err := filepath.EvalSymlinks(top)
+	if err != nil {
+		return top
+	}
+	realFitdir, err := filepath.EvalSymlinks(fitdir)
+	if err != nil {
+		return top
+	}
+	rel, err := filepath.Rel(realTop, realFitdir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return top
+	}
+	root := fitdir
+	for ; rel != "."; rel = filepath.Dir(rel) {
+		root = filepath.Dir(root)
+	}
+	return root
+}
+
+// hgRevisionStore returns the Store of rev of the hg repository with the
+// working tree root. Files are listed with hg files and read with hg cat.
+func hgRevisionStore(root, rev string) (bugs.Store, error) {
+	hg := func(args ...string) (string, error) {
+		cmd := exec.Command("hg", args...)
+		cmd.Dir = root
+		out, err := cmd.Output()
+		return string(out), err
+	}
+	// modification times, newest first
+	out, err := hg("log", "-r", "reverse(::("+rev+"))", "--template", `@{date|hgdate}\n{files % "{file}\n"}`)
+	if err != nil {
+		return nil, UnsupportedType("Unknown revision " + rev)
+	}
+	times := map[string]time.Time{}
+	var t time.Time
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "@") {
+			// seconds and the offset of the time zone
+			seconds, _ := strconv.ParseInt(strings.Fields(line[1:] + " 0")[0], 10, 64)
+			t = time.Unix(seconds, 0)
+		} else if _, ok := times[line]; line != "" && !ok {
+			times[line] = t
+		}
+	}
+	out, err = hg("files", "-v", "-r", rev, "--template", `{size}\t{path}\n`)
+	if err != nil {
+		return nil, err
+	}
+	var files []bugs.RevisionFile
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		size, _ := strconv.ParseInt(parts[0], 10, 64)
+		path := filepath.ToSlash(parts[1])
+		files = append(files, bugs.RevisionFile{Path: path, Size: size, ModTime: times[path]})
+	}
+	return bugs.NewRevisionStore(root, files, func(path string) ([]byte, error) {
+		out, err := hg("cat", "-r", rev, filepath.FromSlash(path))
+		return []byte(out), err
+	}), nil
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 //var dops = bugs.Directory(os.PathSeparator)
@@ -348,3 +349,82 @@ func scmRetitle(Args argumentList, config bugs.Config) {
 		fmt.Printf("Error moving directory\n")
 	}
 }
+
+// runtestRevisionStore commits an issue twice with commit and reads the
+// issues of the first commit, rev, while the working tree has changed.
+func runtestRevisionStore(tester ManagerTester, t *testing.T, rev string, commit func(msg string)) {
+	var config bugs.Config
+	config.DescriptionFileName = "Description"
+	config.FitDirName = "fit"
+	os.Unsetenv("FIT")
+	issue := tester.WorkDir() + sops + "fit" + sops + "Old-issue"
+	os.MkdirAll(issue, 0755)
+	ioutil.WriteFile(issue+sops+"Description", []byte("first\n"), 0644)
+	ioutil.WriteFile(issue+sops+"Status", []byte("open\n"), 0644)
+	commit("First")
+	ioutil.WriteFile(issue+sops+"Status", []byte("closed\n"), 0644)
+	os.MkdirAll(tester.WorkDir()+sops+"fit"+sops+"New-issue", 0755)
+	ioutil.WriteFile(tester.WorkDir()+sops+"fit"+sops+"New-issue"+sops+"Description", []byte("new\n"), 0644)
+	commit("Second")
+	ioutil.WriteFile(issue+sops+"Description", []byte("uncommitted\n"), 0644)
+
+	handler := tester.Manager()
+	store, err := handler.RevisionStore(rev, config)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err.Error())
+	}
+	old := bugs.SetStore(store)
+	defer bugs.SetStore(old)
+	issues := bugs.GetAllIssues(config)
+	if len(issues) != 1 || issues[0].Dir.ShortNamer() != "Old-issue" {
+		t.Fatalf("Expected the issue of the first commit, got %+v", issues)
+	}
+	if status, description := issues[0].Status(), issues[0].Description(); status != "open" || description != "first\n" {
+		t.Errorf("Unexpected issue at %s: %s %q", rev, status, description)
+	}
+	if data, _ := ioutil.ReadFile(issue + sops + "Description"); string(data) != "uncommitted\n" {
+		t.Errorf("Working tree changed: %q", data)
+	}
+	if _, err := handler.RevisionStore("missing-revision", config); err == nil {
+		t.Errorf("Expected an error for an unknown revision")
+	}
+
+	if _, err := handler.RevisionAt(time.Now().Add(time.Minute), config); err != nil {
+		t.Errorf("Unexpected error %s", err.Error())
+	}
+	if _, err := handler.RevisionAt(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), config); err == nil {
+		t.Errorf("Expected no commit before 2000")
+	}
+
+	bugs.SetStore(old)
+	head := "HEAD"
+	if handler.SCMTyper() == "hg" {
+		head = "."
+	}
+	if revs, err := handler.Revisions("", head, config); err != nil || len(revs) != 2 {
+		t.Errorf("Expected the two commits, got %v %v", revs, err)
+	}
+	if revs, err := handler.Revisions(rev, head, config); err != nil || len(revs) != 1 {
+		t.Errorf("Expected the second commit, got %v %v", revs, err)
+	}
+	for _, from := range []string{"", rev} {
+		closed, err := ClosedIssues(handler, from, "", config)
+		if err != nil || len(closed) != 1 || closed[0].Name != "Old-issue" || closed[0].Old.Status != "open" {
+			t.Errorf("Expected Old-issue closed since %q, got %+v %v", from, closed, err)
+		}
+	}
+	changes, err := DiffIssues(handler, rev, "", config)
+	if err != nil || len(changes) != 2 || changes[0].Kind != bugs.IssueClosed || changes[1].Kind != bugs.IssueCreated {
+		t.Errorf("Unexpected changes since %s: %+v %v", rev, changes, err)
+	}
+	last, changes, err := CommitIssues(handler, head, config)
+	if err != nil || len(last) != 40 || len(changes) != 2 || changes[0].Name != "Old-issue" || changes[1].Name != "New-issue" {
+		t.Errorf("Unexpected changes of the last commit %s: %+v %v", last, changes, err)
+	}
+	if current, err := HeadRevision(handler, config); err != nil || current != last {
+		t.Errorf("Unexpected revision checked out %s, expected %s: %v", current, last, err)
+	}
+	if _, changes, err = CommitIssues(handler, rev, config); err != nil || len(changes) != 1 || changes[0].Kind != bugs.IssueCreated {
+		t.Errorf("Unexpected changes of the first commit: %+v %v", changes, err)
+	}
+}
@@ -1,9 +1,64 @@
 package scm
 
 import (
+	"bytes"
+	"fmt"
 	bugs "github.com/driusan/bug/bugs"
 	"os"
+	"sort"
+	"strings"
 )
 
 var dops = bugs.Directory(os.PathSeparator)
 var sops = string(os.PathSeparator)
+
+// Create commit message by iterating over issues in order:
+// closed issues are most important (something is DONE, ok? ;), those issues will also become hidden)
+// new issues are next, with just updates at the end, each in the order of names
+// TODO: do something if this message will be too long
+func issuesCommitMsg(ghClosed []string, issues issuesStatus) []byte {
+	done, add, update, together := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	var cntd, cnta, cntu int
+
+	var names []string
+	for issue := range issues {
+		names = append(names, issue)
+	}
+	sort.Strings(names)
+	for _, issue := range names {
+		state := issues[issue]
+		if state.d {
+			fmt.Fprintf(done, ", %q", issue)
+			cntd++
+		} else if state.a {
+			fmt.Fprintf(add, ", %q", issue)
+			cnta++
+		} else if state.m {
+			fmt.Fprintf(update, ", %q", issue)
+			cntu++
+		}
+	}
+
+	outf := func(buf *bytes.Buffer, what string, many bool) {
+		if buf.Len() == 0 {
+			return
+		}
+		var plural string
+		if many {
+			plural = "s:"
+		}
+		item := buf.Bytes()[2:]
+		fmt.Fprintf(together, "%s issue%s %s; ", what, plural, item)
+	}
+	outf(done, "Close", cntd > 1)
+	outf(add, "Create", cnta > 1)
+	outf(update, "Update", cntu > 1)
+	if l := together.Len(); l > 0 {
+		together.Truncate(l - 2) // "; " from last applied outf()
+	}
+
+	if len(ghClosed) > 0 {
+		fmt.Fprintf(together, "\n\nCloses %s\n", strings.Join(ghClosed, ", closes "))
+	}
+	return together.Bytes()
+}
@@ -1 +1,25 @@
 package scm
+
+import (
+	"testing"
+)
+
+func TestIssuesCommitMsg(t *testing.T) {
+	issues := issuesStatus{
+		"Second-new": {a: true},
+		"First-new":  {a: true, m: true},
+		"Done":       {d: true},
+		"Changed":    {m: true},
+	}
+	expected := `Close issue "Done"; Create issues: "First-new", "Second-new"; Update issue "Changed"`
+	if msg := string(issuesCommitMsg(nil, issues)); msg != expected {
+		t.Errorf("Unexpected commit message %q", msg)
+	}
+	expected = "Close issue \"Done\"\n\nCloses #1, closes #2\n"
+	if msg := string(issuesCommitMsg([]string{"#1", "#2"}, issuesStatus{"Done": {d: true}})); msg != expected {
+		t.Errorf("Unexpected commit message %q", msg)
+	}
+	if msg := issuesCommitMsg(nil, issuesStatus{}); len(msg) != 0 {
+		t.Errorf("Unexpected commit message without changes %q", msg)
+	}
+}